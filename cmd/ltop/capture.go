@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/admiller/ltop/internal/fixture"
+)
+
+func runCapture(args []string) int {
+	var roots rootOptions
+	var out string
+	var ticks int
+	var interval time.Duration
+
+	fs := flag.NewFlagSet(AppName+" capture", flag.ContinueOnError)
+	fs.StringVar(&out, "out", "", "fixture directory, or .tar.gz/.tgz file to write")
+	fs.IntVar(&ticks, "ticks", 3, "number of collection passes to capture")
+	fs.DurationVar(&interval, "interval", time.Second, "delay between collection passes")
+	roots.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if out == "" || ticks < 1 {
+		fmt.Fprintln(os.Stderr, "capture requires --out and a positive --ticks")
+		fs.Usage()
+		return 2
+	}
+
+	dir := out
+	if fixture.IsArchivePath(out) {
+		tempDir, err := os.MkdirTemp("", "ltop-capture-")
+		if err != nil {
+			log.Printf("Failed to create capture directory: %v", err)
+			return 1
+		}
+		defer func() { _ = os.RemoveAll(tempDir) }()
+		dir = tempDir
+	}
+
+	writer, err := fixture.NewWriter(dir)
+	if err != nil {
+		log.Printf("Failed to create fixture: %v", err)
+		return 1
+	}
+
+	ltopApp := newApp(&roots)
+	if err := ltopApp.Capture(writer, ticks, interval); err != nil {
+		log.Printf("Capture failed: %v", err)
+		return 1
+	}
+
+	if dir != out {
+		if err := fixture.Archive(dir, out); err != nil {
+			log.Printf("Failed to write %s: %v", out, err)
+			return 1
+		}
+	}
+
+	fmt.Printf("Captured %d frames to %s\n", writer.Frames(), out)
+	return 0
+}
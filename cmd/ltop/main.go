@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/fixture"
 	"github.com/admiller/ltop/internal/models"
 	"github.com/admiller/ltop/internal/ui/views"
 	"github.com/admiller/ltop/pkg/utils"
@@ -20,40 +22,138 @@ const (
 	AppDesc    = "Linux system monitoring tool with modern TUI"
 )
 
+type options struct {
+	version  bool
+	help     bool
+	demo     bool
+	tui      bool
+	readOnly bool
+	fixture  string
+	roots    rootOptions
+}
+
+// rootOptions are shared by every command that collects metrics.
+type rootOptions struct {
+	procRoot string
+	sysRoot  string
+	hostRoot string
+}
+
+func (r *rootOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&r.procRoot, "proc-root", "", "")
+	fs.StringVar(&r.sysRoot, "sys-root", "", "")
+	fs.StringVar(&r.hostRoot, "host-root", "", "")
+}
+
+// apply overrides the loaded config with command line options. The
+// overrides are not written back to the config file.
+func (r *rootOptions) apply(ltopApp *app.App) {
+	config := ltopApp.GetConfig()
+	if r.procRoot != "" {
+		config.ProcRoot = r.procRoot
+	}
+	if r.sysRoot != "" {
+		config.SysRoot = r.sysRoot
+	}
+	if r.hostRoot != "" {
+		config.HostRoot = r.hostRoot
+	}
+	ltopApp.SetConfig(config)
+}
+
+func parseOptions(args []string) (*options, error) {
+	opts := &options{}
+
+	fs := flag.NewFlagSet(AppName, flag.ContinueOnError)
+	fs.Usage = printHelp
+	fs.BoolVar(&opts.version, "v", false, "")
+	fs.BoolVar(&opts.version, "version", false, "")
+	fs.BoolVar(&opts.help, "h", false, "")
+	fs.BoolVar(&opts.help, "help", false, "")
+	fs.BoolVar(&opts.demo, "demo", false, "")
+	fs.BoolVar(&opts.tui, "tui", false, "")
+	fs.StringVar(&opts.fixture, "fixture", "", "")
+	fs.BoolVar(&opts.readOnly, "read-only", false, "")
+	opts.roots.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// newApp creates the app with the user's config and the given overrides.
+func newApp(roots *rootOptions) *app.App {
+	ltopApp := app.New()
+
+	if err := ltopApp.LoadConfig(); err != nil {
+		log.Printf("Failed to load config, using defaults: %v", err)
+	}
+	roots.apply(ltopApp)
+
+	return ltopApp
+}
+
 func main() {
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
-		case "-v", "--version":
-			fmt.Printf("%s version %s\n", AppName, AppVersion)
-			fmt.Printf("%s\n", AppDesc)
-			return
-		case "-h", "--help":
-			printHelp()
+		case "capture":
+			os.Exit(runCapture(os.Args[2:]))
+		case "record":
+			os.Exit(runRecord(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "batch":
+			os.Exit(runBatch(os.Args[2:]))
+		}
+	}
+
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
 			return
 		}
+		os.Exit(2)
 	}
 
-	ltopApp := app.New()
+	if opts.version {
+		fmt.Printf("%s version %s\n", AppName, AppVersion)
+		fmt.Printf("%s\n", AppDesc)
+		return
+	}
+	if opts.help {
+		printHelp()
+		return
+	}
 
-	if err := ltopApp.LoadConfig(); err != nil {
-		log.Printf("Failed to load config, using defaults: %v", err)
+	ltopApp := newApp(&opts.roots)
+
+	if opts.fixture != "" {
+		replay, err := fixture.Open(opts.fixture)
+		if err != nil {
+			log.Fatalf("Failed to open fixture: %v", err)
+		}
+		defer func() { _ = replay.Close() }()
+		ltopApp.UseFixture(replay)
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "--demo" {
+	if opts.demo {
 		runDemo(ltopApp)
 		return
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "--tui" {
+	if opts.tui {
 		log.Printf("Starting %s %s in TUI mode", AppName, AppVersion)
-		if err := runTUI(ltopApp); err != nil {
+		if err := runTUI(ltopApp, opts.readOnly); err != nil {
 			log.Fatalf("TUI application failed: %v", err)
 		}
 		return
 	}
 
 	log.Printf("Starting %s %s in TUI mode", AppName, AppVersion)
-	if err := runTUI(ltopApp); err != nil {
+	if err := runTUI(ltopApp, opts.readOnly); err != nil {
 		log.Fatalf("Application failed: %v", err)
 	}
 }
@@ -61,21 +161,37 @@ func main() {
 func printHelp() {
 	fmt.Printf("%s - %s\n\n", AppName, AppDesc)
 	fmt.Println("Usage:")
-	fmt.Printf("  %s [options]\n\n", AppName)
+	fmt.Printf("  %s [options]\n", AppName)
+	fmt.Printf("  %s capture --out DIR|FILE.tar.gz [--ticks N] [--interval D]\n", AppName)
+	fmt.Printf("  %s record --out FILE [--interval D] [--duration D]\n", AppName)
+	fmt.Printf("  %s replay FILE\n", AppName)
+	fmt.Printf("  %s serve [--listen ADDR] [--interval D] [--top-processes N]\n", AppName)
+	fmt.Printf("  %s batch [-n N] [-d D] [-o json|ndjson|csv|table] [--only SECTION,...]\n\n", AppName)
 	fmt.Println("Options:")
 	fmt.Println("  -h, --help     Show this help message")
 	fmt.Println("  -v, --version  Show version information")
 	fmt.Println("  --demo         Run demo mode (command line output)")
 	fmt.Println("  --tui          Run TUI mode (default)")
+	fmt.Println("  --proc-root    Read process and kernel stats from this procfs (default /proc)")
+	fmt.Println("  --sys-root     Read device stats from this sysfs (default /sys)")
+	fmt.Println("  --host-root    Root filesystem of the monitored host (default /)")
+	fmt.Println("  --fixture      Replay a captured fixture instead of the live system")
+	fmt.Println("  --read-only    Disable every process action (kill, renice, ...)")
 	fmt.Println("")
 	fmt.Println("Interactive Commands:")
 	fmt.Println("  q, Ctrl+C      Quit")
 	fmt.Println("  p              Pause/Resume updates")
-	fmt.Println("  1-7            Switch between views")
+	fmt.Println("  1-8            Switch between views")
 	fmt.Println("  ↑↓, k/j        Navigate lists")
 	fmt.Println("  a              Toggle auto-scroll (logs)")
 	fmt.Println("  e/w/i          Filter logs by level")
 	fmt.Println("")
+	fmt.Println("Replay Commands:")
+	fmt.Println("  p              Pause/Resume playback")
+	fmt.Println("  [ / ]          Step back/forward one snapshot")
+	fmt.Println("  { / }          Slower/faster playback")
+	fmt.Println("  g              Go to a time")
+	fmt.Println("")
 	fmt.Println("Views:")
 	fmt.Println("  1 - System Overview")
 	fmt.Println("  2 - CPU Monitoring")
@@ -92,16 +208,11 @@ func runDemo(ltopApp *app.App) {
 	fmt.Printf("=== %s Demo Mode ===\n\n", AppName)
 
 	go func() {
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
-
-		for i := 0; i < 10; i++ {
-			if err := ltopApp.CollectMetrics(); err != nil {
-				log.Printf("Metrics collection failed: %v", err)
-			}
-			<-ticker.C
+		if err := ltopApp.Run(); err != nil {
+			log.Printf("Metrics collection failed: %v", err)
 		}
 	}()
+	defer ltopApp.Shutdown()
 
 	time.Sleep(2 * time.Second)
 
@@ -199,8 +310,22 @@ func printSystemInfo(snapshot *models.MetricsSnapshot) {
 	}
 }
 
-func runTUI(ltopApp *app.App) error {
+// runTUI runs the interface until it quits. readOnly disables process
+// actions for this run only, on top of the read_only setting.
+func runTUI(ltopApp *app.App, readOnly bool) error {
+	// The model subscribes before collection starts so that it receives the
+	// first snapshot.
 	model := views.NewModel(ltopApp)
+	if readOnly {
+		model.SetReadOnly(true)
+	}
+
+	go func() {
+		if err := ltopApp.Run(); err != nil {
+			log.Printf("Metrics collection failed: %v", err)
+		}
+	}()
+	defer ltopApp.Shutdown()
 
 	p := tea.NewProgram(
 		model,
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/recording"
+)
+
+func runRecord(args []string) int {
+	var roots rootOptions
+	var out string
+	var interval, duration time.Duration
+
+	fs := flag.NewFlagSet(AppName+" record", flag.ContinueOnError)
+	fs.StringVar(&out, "out", "", "recording file to append to")
+	fs.DurationVar(&interval, "interval", 0, "delay between snapshots (default: refresh interval from the config)")
+	fs.DurationVar(&duration, "duration", 0, "stop after this long (default: until interrupted)")
+	roots.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if out == "" {
+		fmt.Fprintln(os.Stderr, "record requires --out")
+		fs.Usage()
+		return 2
+	}
+
+	writer, err := recording.Create(out)
+	if err != nil {
+		log.Printf("Failed to create recording: %v", err)
+		return 1
+	}
+
+	ltopApp := newApp(&roots)
+	if interval > 0 {
+		ltopApp.Bus().SetInterval(interval)
+	}
+	if err := record(ltopApp, writer, duration); err != nil {
+		log.Printf("Recording failed: %v", err)
+		_ = writer.Close()
+		return 1
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("Failed to close %s: %v", out, err)
+		return 1
+	}
+
+	fmt.Printf("Recorded %d snapshots to %s\n", writer.Frames(), out)
+	return 0
+}
+
+// record writes every published snapshot until Run stops, on SIGINT or
+// SIGTERM, or duration has passed.
+func record(ltopApp *app.App, writer *recording.Writer, duration time.Duration) error {
+	snapshots, unsubscribe := ltopApp.Bus().Subscribe()
+	defer unsubscribe()
+
+	go func() {
+		if err := ltopApp.Run(); err != nil {
+			log.Printf("Metrics collection failed: %v", err)
+		}
+	}()
+	defer ltopApp.Shutdown()
+
+	if duration > 0 {
+		timer := time.AfterFunc(duration, ltopApp.Shutdown)
+		defer timer.Stop()
+	}
+
+	for snapshot := range snapshots {
+		if err := writer.Write(snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/recording"
+)
+
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet(AppName+" replay", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "replay requires a recording file")
+		return 2
+	}
+
+	rec, err := recording.Open(fs.Arg(0))
+	if err != nil {
+		log.Printf("Failed to open recording: %v", err)
+		return 1
+	}
+	if len(rec.Snapshots) == 0 {
+		log.Printf("%s has no snapshots", fs.Arg(0))
+		return 1
+	}
+	if rec.Truncated {
+		log.Printf("%s ends in an incomplete snapshot, replaying up to it", fs.Arg(0))
+	}
+
+	ltopApp := newApp(&rootOptions{})
+	ltopApp.UsePlayer(app.NewPlayer(rec.Snapshots))
+
+	if err := runTUI(ltopApp, false); err != nil {
+		log.Printf("Replay failed: %v", err)
+		return 1
+	}
+	return 0
+}
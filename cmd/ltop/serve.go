@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/admiller/ltop/internal/exporter"
+)
+
+func runServe(args []string) int {
+	var roots rootOptions
+	var listen string
+	var interval time.Duration
+	var top int
+
+	fs := flag.NewFlagSet(AppName+" serve", flag.ContinueOnError)
+	fs.StringVar(&listen, "listen", ":9292", "address to serve /metrics on")
+	fs.DurationVar(&interval, "interval", 0, "delay between collections (default: refresh interval from the config)")
+	fs.IntVar(&top, "top-processes", 0, "export per-process series for the N processes using the most CPU")
+	roots.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		log.Printf("Failed to listen on %s: %v", listen, err)
+		return 1
+	}
+
+	ltopApp := newApp(&roots)
+	if interval > 0 {
+		ltopApp.Bus().SetInterval(interval)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter.Handler(ltopApp.GetLastSnapshot, exporter.Options{TopProcesses: top}))
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server failed: %v", err)
+			ltopApp.Shutdown()
+		}
+	}()
+	log.Printf("Serving metrics on http://%s/metrics", listener.Addr())
+
+	// Run returns on SIGINT or SIGTERM.
+	if err := ltopApp.Run(); err != nil {
+		log.Printf("Metrics collection failed: %v", err)
+	}
+	ltopApp.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Failed to stop HTTP server: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -2,61 +2,286 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/fixture"
 	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
 )
 
+// App owns the collectors and the loop that runs them. Its methods are safe
+// to call from the TUI while Run publishes snapshots on another goroutine.
 type App struct {
-	config           models.SystemConfig
-	state            models.AppState
-	cpuCollector     *collectors.CPUCollector
-	memoryCollector  *collectors.MemoryCollector
-	processCollector *collectors.ProcessCollector
-	storageCollector *collectors.StorageCollector
-	networkCollector *collectors.NetworkCollector
-	logCollector     *collectors.LogCollector
-	ctx              context.Context
-	cancel           context.CancelFunc
-	lastSnapshot     *models.MetricsSnapshot
+	mu           sync.RWMutex
+	config       models.SystemConfig
+	state        models.AppState
+	source       *system.Source
+	fixture      *fixture.Replay
+	player       *Player
+	registry     *collectors.Registry
+	processes    *collectors.ProcessCollector
+	extensions   []collectors.Collector
+	scheduler    *Scheduler
+	bus          *Bus
+	history      *History
+	ctx          context.Context
+	cancel       context.CancelFunc
+	lastSnapshot atomic.Pointer[models.MetricsSnapshot]
+	// smaps is set while a view needs smaps_rollup collected, whatever
+	// the config says.
+	smaps bool
 }
 
 func New() *App {
 	ctx, cancel := context.WithCancel(context.Background())
 	config := models.DefaultSystemConfig()
 
-	return &App{
-		config:           config,
-		state:            models.AppState{},
-		cpuCollector:     collectors.NewCPUCollector(),
-		memoryCollector:  collectors.NewMemoryCollector(),
-		processCollector: collectors.NewProcessCollector(),
-		storageCollector: collectors.NewStorageCollector(),
-		networkCollector: collectors.NewNetworkCollector(),
-		logCollector:     collectors.NewLogCollector(config.LogSources, config.MaxLogEntries),
-		ctx:              ctx,
-		cancel:           cancel,
+	a := &App{
+		config:  config,
+		state:   models.AppState{},
+		bus:     NewBus(config.RefreshInterval),
+		history: NewHistory(config.HistoryDuration, config.HistoryResolution),
+		ctx:     ctx,
+		cancel:  cancel,
 	}
+	a.initCollectors()
+
+	return a
+}
+
+// initCollectors (re)creates every built-in collector against the roots in
+// the current config. Collector state such as previous counters is
+// discarded; collectors added with RegisterCollector are kept. a.mu must be
+// held for writing, or a not yet shared.
+func (a *App) initCollectors() {
+	if a.fixture != nil {
+		a.source = a.fixture.Source()
+	} else {
+		a.source = system.NewSource(configPaths(a.config))
+	}
+
+	a.processes = collectors.NewProcessCollectorWithSource(a.source)
+	a.processes.SetSmaps(a.config.ProcessSmaps || a.smaps)
+
+	registry := collectors.NewRegistry()
+	builtin := []collectors.Collector{
+		collectors.NewTyped(collectors.NameCPU, collectors.NewCPUCollectorWithSource(a.source).Collect),
+		collectors.NewTyped(collectors.NameMemory, collectors.NewMemoryCollectorWithSource(a.source).Collect),
+		collectors.NewTyped(collectors.NameProcesses, a.processes.Collect),
+		collectors.NewTyped(collectors.NameStorage, collectors.NewStorageCollectorWithSource(a.source).Collect),
+		collectors.NewTyped(collectors.NameNetwork, collectors.NewNetworkCollectorWithSource(a.source).Collect),
+	}
+	// Logs come from journalctl and plain files, neither of which is part
+	// of a fixture.
+	if a.fixture == nil {
+		logCollector := collectors.NewLogCollectorWithSource(a.config.LogSources, a.config.MaxLogEntries, a.source)
+		builtin = append(builtin, collectors.NewTypedContext(collectors.NameLogs, logCollector.CollectContext))
+	}
+
+	for _, c := range append(builtin, a.extensions...) {
+		if err := registry.Register(c); err != nil {
+			log.Printf("Skipping collector: %v", err)
+		}
+	}
+	registry.Configure(a.config.Collectors)
+
+	running := a.scheduler != nil && a.scheduler.Running()
+	if running {
+		a.scheduler.Stop()
+	}
+
+	a.registry = registry
+	a.scheduler = NewScheduler(registry, a.source.Now)
+	a.scheduler.SetDefaults(a.config.RefreshInterval, a.config.CollectorTimeout)
+
+	if running {
+		a.scheduler.Start(a.ctx)
+	}
+}
+
+// StartCollectors runs the collectors in the background, each on its own
+// interval. CollectMetrics then only assembles their latest results instead
+// of waiting for a collection pass. Fixtures are always collected
+// synchronously, one frame per CollectMetrics.
+func (a *App) StartCollectors() {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.fixture != nil {
+		return
+	}
+	a.scheduler.Start(a.ctx)
+}
+
+// RegisterCollector adds a collector to every following collection pass.
+// Its results are applied to the snapshot after the built-in collectors.
+func (a *App) RegisterCollector(c collectors.Collector) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.registry.Register(c); err != nil {
+		return err
+	}
+	if cfg, ok := a.config.Collectors[c.Name()]; ok {
+		if configurable, ok := c.(collectors.Configurable); ok {
+			configurable.SetEnabled(!cfg.Disabled)
+			configurable.SetInterval(cfg.Interval)
+			configurable.SetTimeout(cfg.Timeout)
+		}
+	}
+	a.extensions = append(a.extensions, c)
+	a.scheduler.Add(c)
+	return nil
+}
+
+func (a *App) Registry() *collectors.Registry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.registry
+}
+
+// SetCollectorEnabled turns a registered collector on or off and records
+// the choice in the config.
+func (a *App) SetCollectorEnabled(name string, enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c, ok := a.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown collector %q", name)
+	}
+	configurable, ok := c.(collectors.Configurable)
+	if !ok {
+		return fmt.Errorf("collector %q cannot be toggled", name)
+	}
+	configurable.SetEnabled(enabled)
+
+	settings := make(map[string]models.CollectorConfig, len(a.config.Collectors)+1)
+	for k, v := range a.config.Collectors {
+		settings[k] = v
+	}
+	cfg := settings[name]
+	cfg.Disabled = !enabled
+	cfg.Interval = c.Interval()
+	cfg.Timeout = c.Timeout()
+	settings[name] = cfg
+	a.config.Collectors = settings
+
+	if !enabled {
+		a.scheduler.Forget(name)
+	}
+	return nil
+}
+
+// UseFixture replaces the live system with a captured fixture. Every call to
+// CollectMetrics then advances to the next captured frame.
+func (a *App) UseFixture(replay *fixture.Replay) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.fixture = replay
+	a.initCollectors()
+}
+
+// Fixture returns the fixture set with UseFixture, or nil for the live
+// system.
+func (a *App) Fixture() *fixture.Replay {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.fixture
+}
+
+// UsePlayer replaces collection with playback of recorded snapshots. Run
+// then publishes the player's snapshots instead of collecting.
+func (a *App) UsePlayer(player *Player) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.player = player
+}
+
+// Player returns the player set with UsePlayer, or nil for live collection.
+func (a *App) Player() *Player {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.player
+}
+
+// Capture records ticks collection passes, interval apart, into w.
+func (a *App) Capture(w *fixture.Writer, ticks int, interval time.Duration) error {
+	source := a.Source()
+	source.SetRecorder(w)
+	defer source.SetRecorder(nil)
+
+	for i := 0; i < ticks; i++ {
+		if i > 0 {
+			select {
+			case <-a.ctx.Done():
+				return a.ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		w.BeginFrame(source.Now())
+		if err := a.CollectMetrics(); err != nil {
+			_ = w.EndFrame()
+			return err
+		}
+		if err := w.EndFrame(); err != nil {
+			return fmt.Errorf("failed to write frame %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func configPaths(config models.SystemConfig) system.Paths {
+	return system.Paths{
+		ProcRoot: config.ProcRoot,
+		SysRoot:  config.SysRoot,
+		HostRoot: config.HostRoot,
+	}.WithDefaults()
 }
 
+// Run collects on the bus interval and publishes every snapshot on the bus
+// until Shutdown is called or the process is signalled. Collection stops
+// while the bus is paused. With a player, Run plays it back instead.
 func (a *App) Run() error {
 	defer a.cancel()
+	defer a.bus.Close()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 
-	ticker := time.NewTicker(a.config.RefreshInterval)
+	if player := a.Player(); player != nil {
+		return a.play(player, sigChan)
+	}
+
+	interval := a.bus.Interval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// The first pass runs synchronously so the first snapshot is complete.
 	if err := a.CollectMetrics(); err != nil {
 		log.Printf("Initial metrics collection failed: %v", err)
 	}
 
+	a.StartCollectors()
+	defer func() {
+		a.mu.RLock()
+		scheduler := a.scheduler
+		a.mu.RUnlock()
+		scheduler.Stop()
+	}()
+
 	for {
 		select {
 		case <-a.ctx.Done():
@@ -64,8 +289,15 @@ func (a *App) Run() error {
 		case <-sigChan:
 			log.Println("Received shutdown signal")
 			return nil
+		case <-a.bus.IntervalChanged():
+			interval = a.bus.Interval()
+			ticker.Reset(interval)
+			a.mu.Lock()
+			a.config.RefreshInterval = interval
+			a.scheduler.SetDefaults(interval, 0)
+			a.mu.Unlock()
 		case <-ticker.C:
-			if !a.state.Paused {
+			if !a.bus.Paused() {
 				if err := a.CollectMetrics(); err != nil {
 					log.Printf("Metrics collection failed: %v", err)
 				}
@@ -74,102 +306,211 @@ func (a *App) Run() error {
 	}
 }
 
-func (a *App) CollectMetrics() error {
-	start := time.Now()
-
-	snapshot := &models.MetricsSnapshot{
-		Timestamp: start,
+// play publishes the player's current snapshot and then every following one,
+// spaced out like they were recorded, scaled by the playback speed.
+func (a *App) play(player *Player, sigChan <-chan os.Signal) error {
+	// Changes made before playback started are part of the first publish.
+	select {
+	case <-player.changed:
+	default:
 	}
+	shown := a.publishRecorded(player, -1)
 
-	if err := a.collectSystemOverview(snapshot); err != nil {
-		return err
+	timer := time.NewTimer(player.delay())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return nil
+		case <-sigChan:
+			log.Println("Received shutdown signal")
+			return nil
+		case <-player.changed:
+			shown = a.publishRecorded(player, shown)
+		case <-timer.C:
+			if !a.bus.Paused() && player.advance() {
+				shown = a.publishRecorded(player, shown)
+			}
+		}
+		timer.Reset(player.delay())
 	}
+}
 
-	if cpuMetrics, err := a.cpuCollector.Collect(); err == nil {
-		snapshot.CPU = *cpuMetrics
-	} else {
-		log.Printf("CPU collection failed: %v", err)
+// publishRecorded publishes the player's current snapshot. shown is the
+// position published last; after a jump the history is rebuilt from the
+// recording so that graphs show the time leading up to the new position.
+func (a *App) publishRecorded(player *Player, shown int) int {
+	position, snapshot := player.frame()
+	if snapshot == nil {
+		return shown
 	}
 
-	if memoryMetrics, err := a.memoryCollector.Collect(); err == nil {
-		snapshot.Memory = *memoryMetrics
-	} else {
-		log.Printf("Memory collection failed: %v", err)
+	switch {
+	case shown >= 0 && position == shown+1:
+		a.history.Record(snapshot)
+	case position != shown:
+		a.history.Reset()
+		for _, s := range player.recent(a.history.Duration()) {
+			a.history.Record(s)
+		}
 	}
 
-	if processMetrics, err := a.processCollector.Collect(); err == nil {
-		snapshot.Processes = *processMetrics
-	} else {
-		log.Printf("Process collection failed: %v", err)
+	a.lastSnapshot.Store(snapshot)
+	a.mu.Lock()
+	a.state.LastUpdate = time.Now()
+	a.mu.Unlock()
+
+	a.bus.Publish(snapshot)
+	return position
+}
+
+// CollectMetrics assembles a snapshot, stores it as the latest one and
+// publishes it on the bus.
+func (a *App) CollectMetrics() error {
+	a.mu.RLock()
+	source, scheduler, replay := a.source, a.scheduler, a.fixture
+	a.mu.RUnlock()
+
+	if replay != nil {
+		replay.Next()
 	}
 
-	if storageMetrics, err := a.storageCollector.Collect(); err == nil {
-		snapshot.Storage = *storageMetrics
-	} else {
-		log.Printf("Storage collection failed: %v", err)
+	start := source.Now()
+
+	snapshot := &models.MetricsSnapshot{
+		Timestamp: start,
 	}
 
-	if networkMetrics, err := a.networkCollector.Collect(); err == nil {
-		snapshot.Network = *networkMetrics
-	} else {
-		log.Printf("Network collection failed: %v", err)
+	if err := collectSystemOverview(source, snapshot); err != nil {
+		return err
 	}
 
-	if logMetrics, err := a.logCollector.Collect(); err == nil {
-		snapshot.Logs = *logMetrics
-	} else {
-		log.Printf("Log collection failed: %v", err)
+	if !scheduler.Running() {
+		scheduler.CollectOnce(a.ctx)
 	}
+	scheduler.Apply(snapshot)
 
-	a.lastSnapshot = snapshot
+	a.history.Record(snapshot)
+	a.lastSnapshot.Store(snapshot)
+	a.mu.Lock()
 	a.state.LastUpdate = time.Now()
+	a.mu.Unlock()
 
+	a.bus.Publish(snapshot)
 	return nil
 }
 
-func (a *App) collectSystemOverview(snapshot *models.MetricsSnapshot) error {
-	hostname, _ := os.Hostname()
+func collectSystemOverview(source *system.Source, snapshot *models.MetricsSnapshot) error {
+	hostname, err := source.Proc.ReadHostname()
+	if err != nil || hostname == "" {
+		hostname, _ = os.Hostname()
+	}
 
 	snapshot.Overview = models.SystemOverview{
 		Hostname:    hostname,
 		CurrentUser: os.Getenv("USER"),
 	}
 
+	if uptime, err := source.Proc.ReadUptime(); err == nil {
+		if fields := strings.Fields(uptime); len(fields) > 0 {
+			if seconds, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				snapshot.Overview.Uptime = time.Duration(seconds * float64(time.Second))
+				snapshot.Overview.BootTime = snapshot.Timestamp.Add(-snapshot.Overview.Uptime)
+			}
+		}
+	}
+
 	return nil
 }
 
 func (a *App) GetLastSnapshot() *models.MetricsSnapshot {
-	return a.lastSnapshot
+	return a.lastSnapshot.Load()
+}
+
+// History returns the trend data recorded from every published snapshot.
+func (a *App) History() *History {
+	return a.history
+}
+
+// Bus returns the bus snapshots are published on.
+func (a *App) Bus() *Bus {
+	return a.bus
 }
 
 func (a *App) GetConfig() models.SystemConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return a.config
 }
 
 func (a *App) SetConfig(config models.SystemConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	reinit := configPaths(config) != configPaths(a.config)
 	a.config = config
+	if reinit {
+		a.initCollectors()
+	} else {
+		a.registry.Configure(config.Collectors)
+		a.scheduler.SetDefaults(config.RefreshInterval, config.CollectorTimeout)
+		a.processes.SetSmaps(config.ProcessSmaps || a.smaps)
+	}
+	a.history.Resize(config.HistoryDuration, config.HistoryResolution)
+	a.bus.SetInterval(config.RefreshInterval)
+}
+
+// SetProcessSmaps collects smaps_rollup for as long as a view shows it,
+// without changing the config.
+func (a *App) SetProcessSmaps(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.smaps = enabled
+	a.processes.SetSmaps(a.config.ProcessSmaps || enabled)
+}
+
+func (a *App) Source() *system.Source {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.source
 }
 
 func (a *App) GetState() models.AppState {
-	return a.state
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	state := a.state
+	state.Paused = a.bus.Paused()
+	return state
 }
 
 func (a *App) SetCurrentView(view string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.state.CurrentView = view
 }
 
 func (a *App) TogglePause() {
-	a.state.Paused = !a.state.Paused
+	a.bus.TogglePause()
 }
 
 func (a *App) SetSearchQuery(query string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.state.SearchQuery = query
 }
 
 func (a *App) SetSelectedPID(pid int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.state.SelectedPID = pid
 }
 
 func (a *App) Shutdown() {
+	a.mu.RLock()
+	scheduler := a.scheduler
+	a.mu.RUnlock()
+
+	scheduler.Stop()
 	a.cancel()
 }
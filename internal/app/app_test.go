@@ -3,6 +3,9 @@ package app
 import (
 	"testing"
 	"time"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
 )
 
 func TestNewApp(t *testing.T) {
@@ -11,28 +14,17 @@ func TestNewApp(t *testing.T) {
 		t.Fatal("New() returned nil")
 	}
 
-	if app.cpuCollector == nil {
-		t.Error("CPU collector is nil")
-	}
-
-	if app.memoryCollector == nil {
-		t.Error("Memory collector is nil")
-	}
-
-	if app.processCollector == nil {
-		t.Error("Process collector is nil")
-	}
-
-	if app.storageCollector == nil {
-		t.Error("Storage collector is nil")
-	}
-
-	if app.networkCollector == nil {
-		t.Error("Network collector is nil")
-	}
-
-	if app.logCollector == nil {
-		t.Error("Log collector is nil")
+	for _, name := range []string{
+		collectors.NameCPU,
+		collectors.NameMemory,
+		collectors.NameProcesses,
+		collectors.NameStorage,
+		collectors.NameNetwork,
+		collectors.NameLogs,
+	} {
+		if _, ok := app.Registry().Get(name); !ok {
+			t.Errorf("%s collector is not registered", name)
+		}
 	}
 
 	if app.ctx == nil {
@@ -112,6 +104,40 @@ func TestAppSetConfig(t *testing.T) {
 	app.SetConfig(newConfig)
 }
 
+func TestAppSetConfigRoots(t *testing.T) {
+	app := New()
+
+	config := app.GetConfig()
+	config.ProcRoot = "/host/proc"
+	config.SysRoot = ""
+	app.SetConfig(config)
+
+	paths := app.Source().Paths()
+	if paths.ProcRoot != "/host/proc" {
+		t.Errorf("Expected proc root /host/proc, got %s", paths.ProcRoot)
+	}
+	if paths.SysRoot != "/sys" {
+		t.Errorf("Expected empty sys root to default to /sys, got %s", paths.SysRoot)
+	}
+}
+
+func TestAppSetProcessSmaps(t *testing.T) {
+	app := New()
+
+	app.SetProcessSmaps(true)
+	// A config change must not turn collection off behind the view's back.
+	app.SetConfig(app.GetConfig())
+	if err := app.CollectMetrics(); err != nil {
+		t.Fatalf("CollectMetrics failed: %v", err)
+	}
+	if !app.GetLastSnapshot().Processes.Smaps {
+		t.Error("Expected smaps_rollup to be collected")
+	}
+	if app.GetConfig().ProcessSmaps {
+		t.Error("SetProcessSmaps should not change the config")
+	}
+}
+
 func TestAppGetConfig(t *testing.T) {
 	app := New()
 
@@ -206,3 +232,123 @@ func TestAppContext(t *testing.T) {
 		t.Error("Context should be cancelled after calling cancel")
 	}
 }
+
+type uptimeResult struct {
+	seconds int
+}
+
+func (r uptimeResult) Apply(s *models.MetricsSnapshot) {
+	s.SetExtension("uptime", r.seconds)
+}
+
+func TestAppRegisterCollector(t *testing.T) {
+	app := New()
+
+	calls := 0
+	custom := collectors.NewTyped("uptime", func() (uptimeResult, error) {
+		calls++
+		return uptimeResult{seconds: 42}, nil
+	})
+	if err := app.RegisterCollector(custom); err != nil {
+		t.Fatalf("RegisterCollector failed: %v", err)
+	}
+	if err := app.RegisterCollector(custom); err == nil {
+		t.Error("Expected an error registering a duplicate collector")
+	}
+
+	if err := app.CollectMetrics(); err != nil {
+		t.Fatalf("CollectMetrics failed: %v", err)
+	}
+	if got := app.GetLastSnapshot().Extensions["uptime"]; got != 42 {
+		t.Errorf("Expected extension value 42, got %v", got)
+	}
+
+	if err := app.SetCollectorEnabled("uptime", false); err != nil {
+		t.Fatalf("SetCollectorEnabled failed: %v", err)
+	}
+	if cfg := app.GetConfig().Collectors["uptime"]; !cfg.Disabled {
+		t.Error("Expected the config to record the disabled collector")
+	}
+	if err := app.CollectMetrics(); err != nil {
+		t.Fatalf("CollectMetrics failed: %v", err)
+	}
+	if _, ok := app.GetLastSnapshot().Extensions["uptime"]; ok {
+		t.Error("Disabled collector should not contribute to the snapshot")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 collection, got %d", calls)
+	}
+
+	// Custom collectors survive the built-in ones being rebuilt.
+	config := app.GetConfig()
+	config.ProcRoot = t.TempDir()
+	config.Collectors = nil
+	app.SetConfig(config)
+	if c, ok := app.Registry().Get("uptime"); !ok || !c.Enabled() {
+		t.Error("Expected the custom collector to be registered and enabled")
+	}
+}
+
+func TestAppCollectorInterval(t *testing.T) {
+	app := New()
+
+	calls := 0
+	custom := collectors.NewTyped("slow", func() (uptimeResult, error) {
+		calls++
+		return uptimeResult{seconds: calls}, nil
+	})
+	custom.SetInterval(time.Hour)
+	if err := app.RegisterCollector(custom); err != nil {
+		t.Fatalf("RegisterCollector failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := app.CollectMetrics(); err != nil {
+			t.Fatalf("CollectMetrics failed: %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 collection within the interval, got %d", calls)
+	}
+	if got := app.GetLastSnapshot().Extensions["uptime"]; got != 1 {
+		t.Errorf("Expected the cached result to be applied, got %v", got)
+	}
+}
+
+func TestAppRunPublishes(t *testing.T) {
+	app := New()
+	app.Bus().SetInterval(10 * time.Millisecond)
+
+	snapshots, unsubscribe := app.Bus().Subscribe()
+	defer unsubscribe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case snapshot := <-snapshots:
+			if snapshot == nil || snapshot.Timestamp.IsZero() {
+				t.Fatal("Expected a published snapshot")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a snapshot")
+		}
+
+		// Exercise the accessors the TUI uses while Run is collecting.
+		_ = app.GetLastSnapshot()
+		_ = app.GetState()
+		app.SetConfig(app.GetConfig())
+	}
+
+	app.Shutdown()
+	if err := <-done; err != nil {
+		t.Errorf("Run returned %v", err)
+	}
+
+	for range snapshots {
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+// Bus fans snapshots out to any number of subscribers. Published snapshots
+// are shared between subscribers and must not be modified.
+//
+// Each subscriber has room for one pending snapshot; a subscriber that falls
+// behind only ever misses intermediate snapshots, never the latest one, and
+// never slows down the publisher.
+//
+// The bus also owns the collection cadence: App.Run follows its interval and
+// stops collecting while it is paused.
+type Bus struct {
+	mu       sync.Mutex
+	subs     map[int]chan *models.MetricsSnapshot
+	nextID   int
+	latest   *models.MetricsSnapshot
+	closed   bool
+	paused   bool
+	interval time.Duration
+	changed  chan struct{}
+}
+
+func NewBus(interval time.Duration) *Bus {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Bus{
+		subs:     make(map[int]chan *models.MetricsSnapshot),
+		interval: interval,
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Subscribe returns a channel of snapshots, primed with the latest one if
+// any, and a function that ends the subscription. The channel is closed
+// when the subscription ends or the bus is closed.
+func (b *Bus) Subscribe() (<-chan *models.MetricsSnapshot, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan *models.MetricsSnapshot, 1)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+	if b.latest != nil {
+		ch <- b.latest
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if sub, ok := b.subs[id]; ok {
+				delete(b.subs, id)
+				close(sub)
+			}
+		})
+	}
+}
+
+func (b *Bus) Publish(snapshot *models.MetricsSnapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.latest = snapshot
+
+	for _, ch := range b.subs {
+		select {
+		case ch <- snapshot:
+			continue
+		default:
+		}
+		// Replace the stale pending snapshot.
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- snapshot:
+		default:
+		}
+	}
+}
+
+func (b *Bus) Latest() *models.MetricsSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.latest
+}
+
+// Close ends every subscription. Later publishes are dropped.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for id, ch := range b.subs {
+		delete(b.subs, id)
+		close(ch)
+	}
+}
+
+func (b *Bus) Paused() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.paused
+}
+
+func (b *Bus) SetPaused(paused bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.paused = paused
+}
+
+func (b *Bus) TogglePause() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.paused = !b.paused
+}
+
+func (b *Bus) Interval() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.interval
+}
+
+func (b *Bus) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	changed := interval != b.interval
+	b.interval = interval
+	b.mu.Unlock()
+
+	if changed {
+		select {
+		case b.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// IntervalChanged is signalled whenever SetInterval changes the interval.
+func (b *Bus) IntervalChanged() <-chan struct{} {
+	return b.changed
+}
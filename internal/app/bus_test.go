@@ -0,0 +1,125 @@
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func TestBusDropsOldestForSlowSubscribers(t *testing.T) {
+	bus := NewBus(time.Second)
+
+	snapshots, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for i := 1; i <= 3; i++ {
+		bus.Publish(&models.MetricsSnapshot{Timestamp: time.Unix(int64(i), 0)})
+	}
+
+	select {
+	case snapshot := <-snapshots:
+		if snapshot.Timestamp.Unix() != 3 {
+			t.Errorf("Expected the latest snapshot, got %d", snapshot.Timestamp.Unix())
+		}
+	default:
+		t.Fatal("Expected a pending snapshot")
+	}
+
+	select {
+	case snapshot := <-snapshots:
+		t.Errorf("Expected no further snapshots, got %v", snapshot.Timestamp)
+	default:
+	}
+}
+
+func TestBusSubscribeReceivesLatest(t *testing.T) {
+	bus := NewBus(time.Second)
+	bus.Publish(&models.MetricsSnapshot{Timestamp: time.Unix(7, 0)})
+
+	snapshots, unsubscribe := bus.Subscribe()
+	if snapshot := <-snapshots; snapshot.Timestamp.Unix() != 7 {
+		t.Errorf("Expected the latest snapshot on subscribe, got %v", snapshot.Timestamp)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-snapshots; ok {
+		t.Error("Expected the channel to be closed after unsubscribe")
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	bus := NewBus(time.Second)
+	first, _ := bus.Subscribe()
+	bus.Close()
+
+	if _, ok := <-first; ok {
+		t.Error("Expected subscriptions to be closed")
+	}
+
+	later, _ := bus.Subscribe()
+	if _, ok := <-later; ok {
+		t.Error("Expected subscriptions after Close to be closed")
+	}
+
+	bus.Publish(&models.MetricsSnapshot{})
+}
+
+func TestBusConcurrentPublish(t *testing.T) {
+	bus := NewBus(time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		snapshots, unsubscribe := bus.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range snapshots {
+			}
+		}()
+		defer unsubscribe()
+	}
+
+	var publishers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish(&models.MetricsSnapshot{})
+			}
+		}()
+	}
+	publishers.Wait()
+
+	bus.Close()
+	wg.Wait()
+}
+
+func TestBusInterval(t *testing.T) {
+	bus := NewBus(time.Second)
+
+	bus.SetInterval(time.Second)
+	select {
+	case <-bus.IntervalChanged():
+		t.Error("Setting the same interval should not signal a change")
+	default:
+	}
+
+	bus.SetInterval(5 * time.Second)
+	select {
+	case <-bus.IntervalChanged():
+	default:
+		t.Error("Expected an interval change signal")
+	}
+	if bus.Interval() != 5*time.Second {
+		t.Errorf("Expected 5s, got %v", bus.Interval())
+	}
+
+	bus.TogglePause()
+	if !bus.Paused() {
+		t.Error("Expected the bus to be paused")
+	}
+}
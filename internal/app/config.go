@@ -29,12 +29,18 @@ func (a *App) LoadConfig() error {
 		return err
 	}
 
-	var config models.SystemConfig
+	// Settings missing from the file keep their defaults.
+	config := models.DefaultSystemConfig()
 	if err := json.Unmarshal(data, &config); err != nil {
 		return err
 	}
 
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config = config
+	a.initCollectors()
+	a.history.Resize(config.HistoryDuration, config.HistoryResolution)
+	a.bus.SetInterval(config.RefreshInterval)
 	return nil
 }
 
@@ -43,13 +49,42 @@ func (a *App) SaveConfig() error {
 	if err != nil {
 		return err
 	}
+	return writeConfig(configPath, a.GetConfig())
+}
+
+// SaveConfigChange applies change to the running config and to the config
+// file. The rest of the file is kept as it is on disk, so command line
+// overrides and other session state are not saved with the change.
+func (a *App) SaveConfigChange(change func(*models.SystemConfig)) error {
+	config := a.GetConfig()
+	change(&config)
+	a.SetConfig(config)
+
+	configPath, err := a.getConfigPath()
+	if err != nil {
+		return err
+	}
+	saved := models.DefaultSystemConfig()
+	data, err := os.ReadFile(configPath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	change(&saved)
+	return writeConfig(configPath, saved)
+}
 
+func writeConfig(configPath string, config models.SystemConfig) error {
 	configDir := filepath.Dir(configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(a.config, "", "  ")
+	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -67,33 +102,51 @@ func (a *App) getConfigPath() (string, error) {
 }
 
 func (a *App) UpdateRefreshInterval(interval time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.RefreshInterval = interval
+	a.scheduler.SetDefaults(interval, 0)
+	a.bus.SetInterval(interval)
 }
 
 func (a *App) UpdateMaxProcesses(max int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.MaxProcesses = max
 }
 
 func (a *App) UpdateTheme(theme string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.Theme = theme
 }
 
 func (a *App) UpdateSortBy(sortBy string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.SortBy = sortBy
 }
 
 func (a *App) UpdateSortOrder(order string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.SortOrder = order
 }
 
 func (a *App) UpdateViewMode(mode string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.ViewMode = mode
 }
 
 func (a *App) ToggleCPUPercent() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.ShowCPUPercent = !a.config.ShowCPUPercent
 }
 
 func (a *App) ToggleMemoryPercent() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.config.ShowMemoryPercent = !a.config.ShowMemoryPercent
 }
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func TestAppSaveConfigChange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	app := New()
+	if err := app.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	// Overrides as applied by command line options for this run only.
+	config := app.GetConfig()
+	config.ProcRoot = "/host/proc"
+	config.ReadOnly = true
+	config.ProcessSmaps = true
+	app.SetConfig(config)
+
+	err := app.SaveConfigChange(func(config *models.SystemConfig) {
+		config.ProcessFilters = map[string]string{"web": "nginx"}
+	})
+	if err != nil {
+		t.Fatalf("SaveConfigChange failed: %v", err)
+	}
+
+	running := app.GetConfig()
+	if running.ProcessFilters["web"] != "nginx" {
+		t.Error("Expected the change in the running config")
+	}
+	if running.ProcRoot != "/host/proc" || !running.ReadOnly {
+		t.Error("Expected the running config to keep its overrides")
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, DefaultConfigDir, DefaultConfigFile))
+	if err != nil {
+		t.Fatalf("Failed to read the config file: %v", err)
+	}
+	var saved models.SystemConfig
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Failed to parse the config file: %v", err)
+	}
+	if saved.ProcessFilters["web"] != "nginx" {
+		t.Errorf("Expected the filter in the config file, got %v", saved.ProcessFilters)
+	}
+	if saved.ProcRoot == "/host/proc" {
+		t.Error("The proc root override should not be saved")
+	}
+	if saved.ReadOnly {
+		t.Error("The read-only override should not be saved")
+	}
+	if saved.ProcessSmaps {
+		t.Error("The smaps setting should not be saved")
+	}
+}
+
+func TestAppConfigDefaultsFillMissingSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, DefaultConfigDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, DefaultConfigFile)
+	if err := os.WriteFile(path, []byte(`{"theme": "light"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	if err := app.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	defaults := models.DefaultSystemConfig()
+	config := app.GetConfig()
+	if config.Theme != "light" {
+		t.Errorf("Expected the theme from the file, got %q", config.Theme)
+	}
+	if config.RefreshInterval != defaults.RefreshInterval || config.ProcRoot != defaults.ProcRoot {
+		t.Errorf("Expected missing settings to keep their defaults, got %v and %q", config.RefreshInterval, config.ProcRoot)
+	}
+
+	err := app.SaveConfigChange(func(config *models.SystemConfig) {
+		config.ProcessFilters = map[string]string{"web": "nginx"}
+	})
+	if err != nil {
+		t.Fatalf("SaveConfigChange failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read the config file: %v", err)
+	}
+	var saved models.SystemConfig
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Failed to parse the config file: %v", err)
+	}
+	if saved.Theme != "light" || saved.RefreshInterval != defaults.RefreshInterval || saved.MaxProcesses != defaults.MaxProcesses {
+		t.Errorf("Expected the file settings and defaults saved, got %+v", saved)
+	}
+}
@@ -0,0 +1,371 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+// Series names recorded by History. Per-core, per-interface and per-disk
+// series are built with the helpers below.
+const (
+	SeriesCPUTotal          = "cpu.total"
+	SeriesLoad1             = "cpu.load1"
+	SeriesMemoryUsedPercent = "memory.used_percent"
+	SeriesMemoryUsed        = "memory.used"
+	SeriesSwapUsedPercent   = "swap.used_percent"
+)
+
+const historyTopProcesses = 10
+
+func SeriesCPUCore(id int) string {
+	return fmt.Sprintf("cpu.core.%d", id)
+}
+
+func SeriesNetRx(iface string) string {
+	return "net." + iface + ".rx"
+}
+
+func SeriesNetTx(iface string) string {
+	return "net." + iface + ".tx"
+}
+
+func SeriesDiskReadIOPS(device string) string {
+	return "disk." + device + ".read_iops"
+}
+
+func SeriesDiskWriteIOPS(device string) string {
+	return "disk." + device + ".write_iops"
+}
+
+func SeriesDiskReadBytes(device string) string {
+	return "disk." + device + ".read_bytes"
+}
+
+func SeriesDiskWriteBytes(device string) string {
+	return "disk." + device + ".write_bytes"
+}
+
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// ProcessSample is the top of the process list, by CPU, at one point in time.
+type ProcessSample struct {
+	Time      time.Time        `json:"time"`
+	Processes []models.Process `json:"processes"`
+}
+
+// ring is a fixed-capacity FIFO that overwrites its oldest entry when full.
+type ring[T any] struct {
+	items []T
+	start int
+	count int
+}
+
+func newRing[T any](capacity int) *ring[T] {
+	return &ring[T]{items: make([]T, capacity)}
+}
+
+func (r *ring[T]) push(item T) {
+	if len(r.items) == 0 {
+		return
+	}
+	if r.count < len(r.items) {
+		r.items[(r.start+r.count)%len(r.items)] = item
+		r.count++
+		return
+	}
+	r.items[r.start] = item
+	r.start = (r.start + 1) % len(r.items)
+}
+
+func (r *ring[T]) at(i int) T {
+	return r.items[(r.start+i)%len(r.items)]
+}
+
+func (r *ring[T]) slice() []T {
+	out := make([]T, r.count)
+	for i := range out {
+		out[i] = r.at(i)
+	}
+	return out
+}
+
+// History keeps a bounded window of every metric ltop graphs, sampled at a
+// fixed resolution. Snapshots arriving faster than the resolution are
+// dropped.
+type History struct {
+	mu         sync.RWMutex
+	duration   time.Duration
+	resolution time.Duration
+	capacity   int
+	series     map[string]*ring[Point]
+	processes  *ring[ProcessSample]
+	last       time.Time
+}
+
+// NewHistory keeps duration worth of samples taken every resolution. Zero
+// values, as found in configs written before history existed, fall back to
+// the defaults.
+func NewHistory(duration, resolution time.Duration) *History {
+	defaults := models.DefaultSystemConfig()
+	if duration <= 0 {
+		duration = defaults.HistoryDuration
+	}
+	if resolution <= 0 {
+		resolution = defaults.HistoryResolution
+	}
+	if duration < resolution {
+		duration = resolution
+	}
+
+	capacity := int(duration / resolution)
+	return &History{
+		duration:   duration,
+		resolution: resolution,
+		capacity:   capacity,
+		series:     make(map[string]*ring[Point]),
+		processes:  newRing[ProcessSample](capacity),
+	}
+}
+
+func (h *History) Duration() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.duration
+}
+
+func (h *History) Resolution() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.resolution
+}
+
+// Resize changes the retention and resolution, keeping the most recent
+// samples that still fit.
+func (h *History) Resize(duration, resolution time.Duration) {
+	resized := NewHistory(duration, resolution)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if resized.duration == h.duration && resized.resolution == h.resolution {
+		return
+	}
+
+	for name, r := range h.series {
+		target := newRing[Point](resized.capacity)
+		for _, p := range r.slice() {
+			target.push(p)
+		}
+		resized.series[name] = target
+	}
+	for _, sample := range h.processes.slice() {
+		resized.processes.push(sample)
+	}
+
+	h.duration = resized.duration
+	h.resolution = resized.resolution
+	h.capacity = resized.capacity
+	h.series = resized.series
+	h.processes = resized.processes
+}
+
+// Reset discards every recorded sample.
+func (h *History) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.series = make(map[string]*ring[Point])
+	h.processes = newRing[ProcessSample](h.capacity)
+	h.last = time.Time{}
+}
+
+// Record adds a snapshot to every series it has values for.
+func (h *History) Record(snapshot *models.MetricsSnapshot) {
+	if snapshot == nil {
+		return
+	}
+	ts := snapshot.Timestamp
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Allow some jitter so a 1s ticker keeps every sample at 1s resolution.
+	if !h.last.IsZero() && ts.Sub(h.last) < h.resolution*9/10 {
+		return
+	}
+	h.last = ts
+
+	h.add(SeriesCPUTotal, ts, snapshot.CPU.Usage)
+	h.add(SeriesLoad1, ts, snapshot.CPU.LoadAverage[0])
+	for _, core := range snapshot.CPU.Cores {
+		h.add(SeriesCPUCore(core.ID), ts, core.Usage)
+	}
+
+	h.add(SeriesMemoryUsedPercent, ts, snapshot.Memory.UsedPercent)
+	h.add(SeriesMemoryUsed, ts, float64(snapshot.Memory.Used))
+	h.add(SeriesSwapUsedPercent, ts, snapshot.Memory.Swap.UsedPercent)
+
+	for _, iface := range snapshot.Network.Interfaces {
+		h.add(SeriesNetRx(iface.Name), ts, iface.RecvBytesPerSec)
+		h.add(SeriesNetTx(iface.Name), ts, iface.SentBytesPerSec)
+	}
+
+	for _, disk := range snapshot.Storage.IOStats {
+		h.add(SeriesDiskReadIOPS(disk.Device), ts, disk.IOPSRead)
+		h.add(SeriesDiskWriteIOPS(disk.Device), ts, disk.IOPSWrite)
+		h.add(SeriesDiskReadBytes(disk.Device), ts, disk.ReadBytesPerSec)
+		h.add(SeriesDiskWriteBytes(disk.Device), ts, disk.WriteBytesPerSec)
+	}
+
+	h.processes.push(ProcessSample{
+		Time:      ts,
+		Processes: topProcesses(snapshot.Processes.Processes, historyTopProcesses),
+	})
+
+	// Series of interfaces and disks that went away age out entirely.
+	for name, r := range h.series {
+		if r.count > 0 && ts.Sub(r.at(r.count-1).Time) > h.duration {
+			delete(h.series, name)
+		}
+	}
+}
+
+func (h *History) add(name string, ts time.Time, value float64) {
+	r, ok := h.series[name]
+	if !ok {
+		r = newRing[Point](h.capacity)
+		h.series[name] = r
+	}
+	r.push(Point{Time: ts, Value: value})
+}
+
+func topProcesses(processes []models.Process, n int) []models.Process {
+	top := make([]models.Process, len(processes))
+	copy(top, processes)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].CPUPercent > top[j].CPUPercent
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Names lists the recorded series in sorted order.
+func (h *History) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	names := make([]string, 0, len(h.series))
+	for name := range h.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (h *History) Latest(name string) (Point, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.series[name]
+	if !ok || r.count == 0 {
+		return Point{}, false
+	}
+	return r.at(r.count - 1), true
+}
+
+// Window returns the points of a series recorded within d of its most
+// recent point, oldest first. A zero d returns the whole series.
+func (h *History) Window(name string, d time.Duration) []Point {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.series[name]
+	if !ok || r.count == 0 {
+		return nil
+	}
+
+	points := r.slice()
+	if d <= 0 {
+		return points
+	}
+	cutoff := points[len(points)-1].Time.Add(-d)
+	i := sort.Search(len(points), func(i int) bool {
+		return !points[i].Time.Before(cutoff)
+	})
+	return points[i:]
+}
+
+// Range downsamples the points of a series between from and to into at most
+// buckets evenly sized buckets, averaging the points that fall in each.
+// Buckets without points are omitted; each point is stamped with the start
+// of its bucket.
+func (h *History) Range(name string, from, to time.Time, buckets int) []Point {
+	if buckets <= 0 || !to.After(from) {
+		return nil
+	}
+
+	h.mu.RLock()
+	r, ok := h.series[name]
+	var points []Point
+	if ok {
+		points = r.slice()
+	}
+	h.mu.RUnlock()
+
+	width := to.Sub(from) / time.Duration(buckets)
+	if width <= 0 {
+		width = 1
+	}
+
+	sums := make([]float64, buckets)
+	counts := make([]int, buckets)
+	for _, p := range points {
+		if p.Time.Before(from) || p.Time.After(to) {
+			continue
+		}
+		i := int(p.Time.Sub(from) / width)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		sums[i] += p.Value
+		counts[i]++
+	}
+
+	var result []Point
+	for i := range sums {
+		if counts[i] == 0 {
+			continue
+		}
+		result = append(result, Point{
+			Time:  from.Add(time.Duration(i) * width),
+			Value: sums[i] / float64(counts[i]),
+		})
+	}
+	return result
+}
+
+// TopProcesses returns the process samples recorded within d of the most
+// recent one, oldest first. A zero d returns every sample.
+func (h *History) TopProcesses(d time.Duration) []ProcessSample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	samples := h.processes.slice()
+	if d <= 0 || len(samples) == 0 {
+		return samples
+	}
+	cutoff := samples[len(samples)-1].Time.Add(-d)
+	i := sort.Search(len(samples), func(i int) bool {
+		return !samples[i].Time.Before(cutoff)
+	})
+	return samples[i:]
+}
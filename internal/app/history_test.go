@@ -0,0 +1,148 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func historySnapshot(ts time.Time, cpu float64) *models.MetricsSnapshot {
+	return &models.MetricsSnapshot{
+		Timestamp: ts,
+		CPU: models.CPUMetrics{
+			Usage: cpu,
+			Cores: []models.CPUCoreMetrics{{ID: 0, Usage: cpu / 2}},
+		},
+		Network: models.NetworkMetrics{
+			Interfaces: []models.NetworkInterface{{Name: "eth0", RecvBytesPerSec: 100, SentBytesPerSec: 50}},
+		},
+		Storage: models.StorageMetrics{
+			IOStats: []models.DiskIOMetrics{{Device: "sda", IOPSRead: 10, IOPSWrite: 5}},
+		},
+		Processes: models.ProcessMetrics{
+			Processes: []models.Process{
+				{PID: 1, CPUPercent: 1},
+				{PID: 2, CPUPercent: cpu},
+			},
+		},
+	}
+}
+
+func TestHistoryWindowAndEviction(t *testing.T) {
+	history := NewHistory(10*time.Second, time.Second)
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 15; i++ {
+		history.Record(historySnapshot(start.Add(time.Duration(i)*time.Second), float64(i)))
+	}
+
+	all := history.Window(SeriesCPUTotal, 0)
+	if len(all) != 10 {
+		t.Fatalf("Expected the ring to hold 10 points, got %d", len(all))
+	}
+	if all[0].Value != 5 || all[9].Value != 14 {
+		t.Errorf("Expected values 5..14, got %.0f..%.0f", all[0].Value, all[9].Value)
+	}
+
+	window := history.Window(SeriesCPUTotal, 3*time.Second)
+	if len(window) != 4 || window[0].Value != 11 {
+		t.Errorf("Expected the last 4 points starting at 11, got %v", window)
+	}
+
+	if latest, ok := history.Latest(SeriesCPUCore(0)); !ok || latest.Value != 7 {
+		t.Errorf("Expected core 0 latest 7, got %v (%v)", latest.Value, ok)
+	}
+
+	for _, name := range []string{SeriesNetRx("eth0"), SeriesNetTx("eth0"), SeriesDiskReadIOPS("sda"), SeriesSwapUsedPercent} {
+		if len(history.Window(name, 0)) == 0 {
+			t.Errorf("Expected series %s to be recorded", name)
+		}
+	}
+
+	samples := history.TopProcesses(0)
+	if len(samples) != 10 {
+		t.Fatalf("Expected 10 process samples, got %d", len(samples))
+	}
+	if top := samples[len(samples)-1].Processes; len(top) != 2 || top[0].PID != 2 {
+		t.Errorf("Expected pid 2 to top the last sample, got %v", top)
+	}
+}
+
+func TestHistoryResolution(t *testing.T) {
+	history := NewHistory(time.Minute, 5*time.Second)
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 20; i++ {
+		history.Record(historySnapshot(start.Add(time.Duration(i)*time.Second), float64(i)))
+	}
+
+	points := history.Window(SeriesCPUTotal, 0)
+	if len(points) != 4 {
+		t.Fatalf("Expected one point every 5s, got %d", len(points))
+	}
+	if points[1].Value != 5 {
+		t.Errorf("Expected second point from t=5s, got %.0f", points[1].Value)
+	}
+}
+
+func TestHistoryRange(t *testing.T) {
+	history := NewHistory(time.Minute, time.Second)
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 10; i++ {
+		history.Record(historySnapshot(start.Add(time.Duration(i)*time.Second), float64(i)))
+	}
+
+	points := history.Range(SeriesCPUTotal, start, start.Add(10*time.Second), 5)
+	if len(points) != 5 {
+		t.Fatalf("Expected 5 buckets, got %d", len(points))
+	}
+	for i, p := range points {
+		expected := float64(i*2) + 0.5
+		if p.Value != expected {
+			t.Errorf("Bucket %d: expected %.1f, got %.1f", i, expected, p.Value)
+		}
+		if !p.Time.Equal(start.Add(time.Duration(i) * 2 * time.Second)) {
+			t.Errorf("Bucket %d: unexpected time %v", i, p.Time)
+		}
+	}
+
+	if points := history.Range("missing", start, start.Add(time.Second), 5); len(points) != 0 {
+		t.Errorf("Expected no points for an unknown series, got %d", len(points))
+	}
+}
+
+func TestHistoryResize(t *testing.T) {
+	history := NewHistory(10*time.Second, time.Second)
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 10; i++ {
+		history.Record(historySnapshot(start.Add(time.Duration(i)*time.Second), float64(i)))
+	}
+
+	history.Resize(5*time.Second, time.Second)
+	points := history.Window(SeriesCPUTotal, 0)
+	if len(points) != 5 || points[0].Value != 5 {
+		t.Errorf("Expected the 5 most recent points, got %v", points)
+	}
+
+	history = NewHistory(0, 0)
+	if history.Duration() != 15*time.Minute || history.Resolution() != time.Second {
+		t.Errorf("Expected default settings, got %v at %v", history.Duration(), history.Resolution())
+	}
+}
+
+func TestHistoryDropsVanishedSeries(t *testing.T) {
+	history := NewHistory(5*time.Second, time.Second)
+	start := time.Unix(1000, 0)
+
+	history.Record(historySnapshot(start, 1))
+	later := historySnapshot(start.Add(10*time.Second), 1)
+	later.Network.Interfaces = nil
+	history.Record(later)
+
+	if len(history.Window(SeriesNetRx("eth0"), 0)) != 0 {
+		t.Error("Expected the series of a removed interface to age out")
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+// Playback speeds stepped through by Faster and Slower.
+var playbackSpeeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32, 64}
+
+const playbackNormalSpeed = 2
+
+// playbackMaxDelay caps the wait between two snapshots so that gaps in a
+// recording, e.g. between two recording sessions, are skipped quickly.
+const playbackMaxDelay = 2 * time.Second
+
+// Player steps through recorded snapshots. An App using a player publishes
+// them on its bus instead of collecting; pausing the bus pauses playback.
+type Player struct {
+	mu        sync.Mutex
+	snapshots []*models.MetricsSnapshot
+	position  int
+	speed     int
+	changed   chan struct{}
+}
+
+// NewPlayer plays snapshots, which must be in timestamp order.
+func NewPlayer(snapshots []*models.MetricsSnapshot) *Player {
+	return &Player{
+		snapshots: snapshots,
+		speed:     playbackNormalSpeed,
+		changed:   make(chan struct{}, 1),
+	}
+}
+
+func (p *Player) Len() int {
+	return len(p.snapshots)
+}
+
+func (p *Player) Position() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.position
+}
+
+func (p *Player) Current() *models.MetricsSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.currentLocked()
+}
+
+func (p *Player) frame() (int, *models.MetricsSnapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.position, p.currentLocked()
+}
+
+func (p *Player) currentLocked() *models.MetricsSnapshot {
+	if len(p.snapshots) == 0 {
+		return nil
+	}
+	return p.snapshots[p.position]
+}
+
+// Start and End return the time span of the recording.
+func (p *Player) Start() time.Time {
+	if len(p.snapshots) == 0 {
+		return time.Time{}
+	}
+	return p.snapshots[0].Timestamp
+}
+
+func (p *Player) End() time.Time {
+	if len(p.snapshots) == 0 {
+		return time.Time{}
+	}
+	return p.snapshots[len(p.snapshots)-1].Timestamp
+}
+
+func (p *Player) Speed() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return playbackSpeeds[p.speed]
+}
+
+func (p *Player) Faster() {
+	p.mu.Lock()
+	if p.speed < len(playbackSpeeds)-1 {
+		p.speed++
+	}
+	p.mu.Unlock()
+	p.signal()
+}
+
+func (p *Player) Slower() {
+	p.mu.Lock()
+	if p.speed > 0 {
+		p.speed--
+	}
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Step moves n snapshots forward, or back for a negative n, stopping at
+// either end of the recording.
+func (p *Player) Step(n int) {
+	p.mu.Lock()
+	p.moveLocked(p.position + n)
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Seek moves to the last snapshot taken at or before ts, or to the first
+// snapshot if ts is before the recording started.
+func (p *Player) Seek(ts time.Time) {
+	p.mu.Lock()
+	p.moveLocked(p.indexAt(ts))
+	p.mu.Unlock()
+	p.signal()
+}
+
+func (p *Player) indexAt(ts time.Time) int {
+	i := sort.Search(len(p.snapshots), func(i int) bool {
+		return p.snapshots[i].Timestamp.After(ts)
+	})
+	if i > 0 {
+		i--
+	}
+	return i
+}
+
+func (p *Player) moveLocked(position int) {
+	if position >= len(p.snapshots) {
+		position = len(p.snapshots) - 1
+	}
+	if position < 0 {
+		position = 0
+	}
+	p.position = position
+}
+
+// advance moves to the next snapshot and reports whether there was one.
+func (p *Player) advance() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.position+1 >= len(p.snapshots) {
+		return false
+	}
+	p.position++
+	return true
+}
+
+// delay returns how long to show the current snapshot before the next one
+// at the current speed.
+func (p *Player) delay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.position+1 >= len(p.snapshots) {
+		return playbackMaxDelay
+	}
+	gap := p.snapshots[p.position+1].Timestamp.Sub(p.snapshots[p.position].Timestamp)
+	delay := time.Duration(float64(gap) / playbackSpeeds[p.speed])
+	if delay > playbackMaxDelay {
+		delay = playbackMaxDelay
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay
+}
+
+// recent returns the snapshots within d before the current one, and the
+// current one, oldest first.
+func (p *Player) recent(d time.Duration) []*models.MetricsSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := p.currentLocked()
+	if current == nil {
+		return nil
+	}
+	from := sort.Search(p.position, func(i int) bool {
+		return !p.snapshots[i].Timestamp.Before(current.Timestamp.Add(-d))
+	})
+	return p.snapshots[from : p.position+1]
+}
+
+func (p *Player) signal() {
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func recordedSnapshots(start time.Time, n int) []*models.MetricsSnapshot {
+	snapshots := make([]*models.MetricsSnapshot, n)
+	for i := range snapshots {
+		snapshots[i] = historySnapshot(start.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	return snapshots
+}
+
+func TestPlayerControls(t *testing.T) {
+	start := time.Unix(1000, 0)
+	player := NewPlayer(recordedSnapshots(start, 10))
+
+	player.Step(3)
+	if player.Position() != 3 {
+		t.Errorf("Expected position 3, got %d", player.Position())
+	}
+	player.Step(-10)
+	if player.Position() != 0 {
+		t.Errorf("Expected stepping back to stop at 0, got %d", player.Position())
+	}
+	player.Step(100)
+	if player.Position() != 9 {
+		t.Errorf("Expected stepping forward to stop at 9, got %d", player.Position())
+	}
+	if player.advance() {
+		t.Error("Expected no snapshot after the last one")
+	}
+
+	player.Seek(start.Add(4500 * time.Millisecond))
+	if player.Position() != 4 {
+		t.Errorf("Expected seek to the snapshot at 4s, got %d", player.Position())
+	}
+	player.Seek(start.Add(-time.Hour))
+	if player.Position() != 0 {
+		t.Errorf("Expected seek before the start to go to 0, got %d", player.Position())
+	}
+
+	if player.Speed() != 1 || player.delay() != time.Second {
+		t.Errorf("Expected 1s at normal speed, got %v at %vx", player.delay(), player.Speed())
+	}
+	player.Faster()
+	player.Faster()
+	if player.Speed() != 4 || player.delay() != 250*time.Millisecond {
+		t.Errorf("Expected 250ms at 4x, got %v at %vx", player.delay(), player.Speed())
+	}
+	for i := 0; i < 20; i++ {
+		player.Slower()
+	}
+	if player.Speed() != playbackSpeeds[0] {
+		t.Errorf("Expected the slowest speed, got %vx", player.Speed())
+	}
+	if player.delay() != playbackMaxDelay {
+		t.Errorf("Expected long gaps to be capped, got %v", player.delay())
+	}
+}
+
+func TestAppRunPlaysRecording(t *testing.T) {
+	start := time.Unix(1000, 0)
+	player := NewPlayer(recordedSnapshots(start, 5))
+	player.Faster()
+	player.Faster()
+	player.Faster()
+	player.Faster()
+
+	app := New()
+	app.UsePlayer(player)
+
+	snapshots, unsubscribe := app.Bus().Subscribe()
+	defer unsubscribe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+
+	next := func() *models.MetricsSnapshot {
+		select {
+		case snapshot := <-snapshots:
+			return snapshot
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a snapshot")
+			return nil
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		if snapshot := next(); !snapshot.Timestamp.Equal(start.Add(time.Duration(i) * time.Second)) {
+			t.Fatalf("Snapshot %d: unexpected timestamp %v", i, snapshot.Timestamp)
+		}
+	}
+	if points := app.History().Window(SeriesCPUTotal, 0); len(points) != 5 {
+		t.Errorf("Expected 5 points of history, got %d", len(points))
+	}
+
+	// Jumping back rebuilds the history up to the new position.
+	app.Bus().SetPaused(true)
+	player.Seek(start.Add(time.Second))
+	if snapshot := next(); snapshot.CPU.Usage != 1 {
+		t.Errorf("Expected the snapshot at 1s after seeking, got %.0f", snapshot.CPU.Usage)
+	}
+	if points := app.History().Window(SeriesCPUTotal, 0); len(points) != 2 {
+		t.Errorf("Expected 2 points of history after seeking back, got %d", len(points))
+	}
+
+	app.Shutdown()
+	if err := <-done; err != nil {
+		t.Errorf("Run returned %v", err)
+	}
+}
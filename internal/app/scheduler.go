@@ -0,0 +1,308 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
+)
+
+// Scheduler runs the collectors of a registry, each in its own goroutine on
+// its own interval and bounded by its own timeout. A collector that fails or
+// overruns its timeout keeps its previous result, marked stale, so one slow
+// source never holds up a snapshot.
+type Scheduler struct {
+	registry *collectors.Registry
+	clock    func() time.Time
+
+	mu       sync.Mutex
+	interval time.Duration
+	timeout  time.Duration
+	entries  map[string]*schedulerEntry
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type schedulerEntry struct {
+	result  collectors.Result
+	status  models.CollectorStatus
+	lastRun time.Time
+	running bool
+}
+
+type outcome struct {
+	result collectors.Result
+	err    error
+}
+
+func NewScheduler(registry *collectors.Registry, clock func() time.Time) *Scheduler {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &Scheduler{
+		registry: registry,
+		clock:    clock,
+		interval: time.Second,
+		timeout:  2 * time.Second,
+		entries:  make(map[string]*schedulerEntry),
+	}
+}
+
+// SetDefaults sets the interval and timeout of collectors that do not have
+// their own.
+func (s *Scheduler) SetDefaults(interval, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if interval > 0 {
+		s.interval = interval
+	}
+	if timeout > 0 {
+		s.timeout = timeout
+	}
+}
+
+func (s *Scheduler) intervalFor(c collectors.Collector) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.intervalForLocked(c)
+}
+
+func (s *Scheduler) intervalForLocked(c collectors.Collector) time.Duration {
+	if interval := c.Interval(); interval > 0 {
+		return interval
+	}
+	return s.interval
+}
+
+func (s *Scheduler) timeoutFor(c collectors.Collector) time.Duration {
+	if timeout := c.Timeout(); timeout > 0 {
+		return timeout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.timeout
+}
+
+func (s *Scheduler) entry(name string) *schedulerEntry {
+	e, ok := s.entries[name]
+	if !ok {
+		e = &schedulerEntry{}
+		s.entries[name] = e
+	}
+	return e
+}
+
+// Start runs every registered collector in the background until ctx is done
+// or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	ctx = s.ctx
+	s.mu.Unlock()
+
+	for _, c := range s.registry.All() {
+		s.launch(ctx, c)
+	}
+}
+
+// Add starts the loop of a collector registered after Start.
+func (s *Scheduler) Add(c collectors.Collector) {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx != nil {
+		s.launch(ctx, c)
+	}
+}
+
+func (s *Scheduler) launch(ctx context.Context, c collectors.Collector) {
+	s.wg.Add(1)
+	go s.loop(ctx, c)
+}
+
+func (s *Scheduler) loop(ctx context.Context, c collectors.Collector) {
+	defer s.wg.Done()
+
+	interval := s.intervalFor(c)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// A collector that CollectOnce has just run waits for its first tick.
+	first := true
+	for {
+		if !c.Enabled() {
+			s.Forget(c.Name())
+		} else if !first || s.due(c, s.clock()) {
+			s.run(ctx, c)
+		}
+		first = false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if next := s.intervalFor(c); next != interval {
+			interval = next
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// Stop ends the background loops. Runs still in flight are abandoned.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.ctx, s.cancel = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		s.wg.Wait()
+	}
+}
+
+func (s *Scheduler) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancel != nil
+}
+
+// CollectOnce runs every enabled collector that is due, concurrently, and
+// returns once each has finished or timed out.
+func (s *Scheduler) CollectOnce(ctx context.Context) {
+	now := s.clock()
+
+	var wg sync.WaitGroup
+	for _, c := range s.registry.Enabled() {
+		if !s.due(c, now) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c collectors.Collector) {
+			defer wg.Done()
+			s.run(ctx, c)
+		}(c)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) due(c collectors.Collector, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[c.Name()]
+	return !ok || e.lastRun.IsZero() || now.Sub(e.lastRun) >= s.intervalForLocked(c)
+}
+
+// run performs one pass of c. It returns when the pass completes or its
+// timeout expires; a pass that overruns is left to finish in the background
+// and no new pass of c starts until it has.
+func (s *Scheduler) run(ctx context.Context, c collectors.Collector) {
+	name := c.Name()
+	timeout := s.timeoutFor(c)
+
+	s.mu.Lock()
+	e := s.entry(name)
+	if e.running {
+		s.mu.Unlock()
+		return
+	}
+	e.running = true
+	e.lastRun = s.clock()
+	s.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	started := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := c.Collect(runCtx)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		cancel()
+		s.finish(name, o, time.Since(started))
+	case <-runCtx.Done():
+		s.markStale(name, fmt.Errorf("timed out after %v", timeout))
+		go func() {
+			o := <-done
+			cancel()
+			s.finish(name, o, time.Since(started))
+		}()
+	}
+}
+
+func (s *Scheduler) finish(name string, o outcome, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entry(name)
+	e.running = false
+	e.status.Duration = duration
+	if o.err != nil {
+		log.Printf("%s collection failed: %v", name, o.err)
+		e.status.Stale = e.result != nil
+		e.status.Error = o.err.Error()
+		return
+	}
+
+	e.result = o.result
+	e.status = models.CollectorStatus{
+		LastUpdate: s.clock(),
+		Duration:   duration,
+	}
+}
+
+func (s *Scheduler) markStale(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entry(name)
+	e.status.Stale = true
+	e.status.Error = err.Error()
+}
+
+// Forget drops the stored result of a collector, such as one that has been
+// disabled.
+func (s *Scheduler) Forget(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[name]; ok && !e.running {
+		delete(s.entries, name)
+	}
+}
+
+// Apply merges the latest result of every enabled collector into snapshot,
+// in registration order, and records their status.
+func (s *Scheduler) Apply(snapshot *models.MetricsSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.registry.Enabled() {
+		e, ok := s.entries[c.Name()]
+		if !ok {
+			continue
+		}
+		if e.result != nil {
+			e.result.Apply(snapshot)
+		}
+		if snapshot.Collectors == nil {
+			snapshot.Collectors = make(map[string]models.CollectorStatus)
+		}
+		snapshot.Collectors[c.Name()] = e.status
+	}
+}
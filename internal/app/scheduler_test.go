@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
+)
+
+func TestSchedulerTimeoutKeepsStaleResult(t *testing.T) {
+	registry := collectors.NewRegistry()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+
+	slow := collectors.NewTypedContext("slow", func(ctx context.Context) (*models.CPUMetrics, error) {
+		if calls.Add(1) == 1 {
+			return &models.CPUMetrics{Usage: 10}, nil
+		}
+		// Ignore ctx like a hung statfs would.
+		<-release
+		return &models.CPUMetrics{Usage: 99}, nil
+	})
+	slow.SetTimeout(20 * time.Millisecond)
+	fast := collectors.NewTyped("fast", func() (*models.MemoryMetrics, error) {
+		return &models.MemoryMetrics{Total: 1024}, nil
+	})
+
+	for _, c := range []collectors.Collector{slow, fast} {
+		if err := registry.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock := time.Unix(0, 0)
+	scheduler := NewScheduler(registry, func() time.Time { return clock })
+	scheduler.SetDefaults(time.Second, time.Second)
+
+	scheduler.CollectOnce(context.Background())
+
+	clock = clock.Add(time.Second)
+	start := time.Now()
+	scheduler.CollectOnce(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("CollectOnce blocked for %v on a hung collector", elapsed)
+	}
+
+	var snapshot models.MetricsSnapshot
+	scheduler.Apply(&snapshot)
+
+	if snapshot.CPU.Usage != 10 {
+		t.Errorf("Expected the previous CPU result, got %.0f", snapshot.CPU.Usage)
+	}
+	if status := snapshot.Collectors["slow"]; !status.Stale || status.Error == "" {
+		t.Errorf("Expected slow collector to be stale with an error, got %+v", status)
+	}
+	if snapshot.Memory.Total != 1024 {
+		t.Errorf("Expected memory total 1024, got %d", snapshot.Memory.Total)
+	}
+	if status := snapshot.Collectors["fast"]; status.Stale {
+		t.Error("Fast collector should not be stale")
+	}
+
+	// The hung pass is still in flight, so the next one is skipped.
+	clock = clock.Add(time.Second)
+	scheduler.CollectOnce(context.Background())
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected 2 passes of the slow collector, got %d", n)
+	}
+}
+
+func TestSchedulerIntervals(t *testing.T) {
+	registry := collectors.NewRegistry()
+
+	var cpuCalls, logCalls int
+	cpu := collectors.NewTyped("cpu", func() (*models.CPUMetrics, error) {
+		cpuCalls++
+		return &models.CPUMetrics{}, nil
+	})
+	logs := collectors.NewTyped("logs", func() (*models.LogMetrics, error) {
+		logCalls++
+		return &models.LogMetrics{}, nil
+	})
+	logs.SetInterval(5 * time.Second)
+
+	for _, c := range []collectors.Collector{cpu, logs} {
+		if err := registry.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock := time.Unix(0, 0)
+	scheduler := NewScheduler(registry, func() time.Time { return clock })
+	scheduler.SetDefaults(500*time.Millisecond, time.Second)
+
+	for i := 0; i < 10; i++ {
+		scheduler.CollectOnce(context.Background())
+		clock = clock.Add(500 * time.Millisecond)
+	}
+
+	if cpuCalls != 10 {
+		t.Errorf("Expected 10 cpu passes, got %d", cpuCalls)
+	}
+	if logCalls != 1 {
+		t.Errorf("Expected 1 log pass, got %d", logCalls)
+	}
+}
+
+func TestSchedulerStart(t *testing.T) {
+	registry := collectors.NewRegistry()
+
+	var calls atomic.Int32
+	cpu := collectors.NewTyped("cpu", func() (*models.CPUMetrics, error) {
+		calls.Add(1)
+		return &models.CPUMetrics{Usage: 50}, nil
+	})
+	if err := registry.Register(cpu); err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := NewScheduler(registry, nil)
+	scheduler.SetDefaults(10*time.Millisecond, time.Second)
+	scheduler.Start(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	scheduler.Stop()
+
+	if calls.Load() < 3 {
+		t.Fatalf("Expected at least 3 background passes, got %d", calls.Load())
+	}
+	if scheduler.Running() {
+		t.Error("Scheduler should not be running after Stop")
+	}
+
+	var snapshot models.MetricsSnapshot
+	scheduler.Apply(&snapshot)
+	if snapshot.CPU.Usage != 50 {
+		t.Errorf("Expected usage 50, got %.0f", snapshot.CPU.Usage)
+	}
+}
@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// An audit file is JSON lines, one Record per process an action was applied
+// to. The file is only ever appended to, and opened for every write so that
+// it can be rotated while ltop runs:
+//
+//	{"time":"...","user":"alice","uid":1000,"pid":4242,"command":"...","owner":"bob","action":"kill","value":"SIGTERM","result":"ok"}
+//	{"time":"...","user":"root","sudo_user":"alice",...,"result":"failed","errno":"EPERM","error":"..."}
+
+// Record is one action on one process. Command and Owner describe the
+// process as it was when the action was taken.
+type Record struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	UID      int       `json:"uid"`
+	SudoUser string    `json:"sudo_user,omitempty"`
+	PID      int       `json:"pid"`
+	Command  string    `json:"command"`
+	Owner    string    `json:"owner"`
+	Action   string    `json:"action"`
+	Value    string    `json:"value"`
+	Result   string    `json:"result"`
+	Errno    string    `json:"errno,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// SetResult fills the result of the record from the error of the action.
+func (r *Record) SetResult(err error) {
+	if err == nil {
+		r.Result = "ok"
+		return
+	}
+	r.Result = "failed"
+	r.Errno = ErrnoName(err)
+	r.Error = err.Error()
+}
+
+var errnoNames = map[syscall.Errno]string{
+	syscall.EPERM:  "EPERM",
+	syscall.ESRCH:  "ESRCH",
+	syscall.EACCES: "EACCES",
+	syscall.EINVAL: "EINVAL",
+	syscall.EBUSY:  "EBUSY",
+	syscall.ENOSYS: "ENOSYS",
+}
+
+// ErrnoName names the errno an error wraps, such as EPERM, or returns ""
+// if it wraps none.
+func ErrnoName(err error) string {
+	if errors.Is(err, os.ErrProcessDone) {
+		return "ESRCH"
+	}
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return ""
+	}
+	if name, ok := errnoNames[errno]; ok {
+		return name
+	}
+	return "errno " + strconv.Itoa(int(errno))
+}
+
+// Log appends records to an audit file and keeps the most recent ones for
+// the action history. A Log without a path keeps the history only.
+type Log struct {
+	mu     sync.Mutex
+	path   string
+	max    int
+	recent []Record
+	actor  Record
+}
+
+// Open starts a log appending to path, which may be empty, and loads the
+// last max records already in the file into the history.
+func Open(path string, max int) (*Log, error) {
+	l := &Log{path: path, max: max, actor: currentActor()}
+	if path == "" {
+		return l, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		// A line cut short by a crash or written by a newer version is
+		// skipped rather than hiding the rest of the history.
+		if json.Unmarshal(scanner.Bytes(), &record) == nil {
+			l.remember(record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return l, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return l, nil
+}
+
+// currentActor describes the user ltop runs as. Under sudo the invoking
+// user is kept as well, since that is who acted on a shared machine.
+func currentActor() Record {
+	actor := Record{UID: os.Getuid(), User: strconv.Itoa(os.Getuid())}
+	if u, err := user.Current(); err == nil {
+		actor.User = u.Username
+	}
+	actor.SudoUser = os.Getenv("SUDO_USER")
+	return actor
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+// NewRecord starts a record of an action by the current user, taken now.
+func (l *Log) NewRecord(action, value string) Record {
+	record := l.actor
+	record.Time = time.Now()
+	record.Action = action
+	record.Value = value
+	return record
+}
+
+// Append adds records to the history and writes them to the file in a
+// single write, so that concurrent writers do not interleave them.
+func (l *Log) Append(records ...Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, record := range records {
+		l.remember(record)
+	}
+	if l.path == "" || len(records) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) remember(record Record) {
+	l.recent = append(l.recent, record)
+	if l.max > 0 && len(l.recent) > l.max {
+		l.recent = append([]Record(nil), l.recent[len(l.recent)-l.max:]...)
+	}
+}
+
+// Recent returns the history, newest first.
+func (l *Log) Recent() []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	recent := make([]Record, len(l.recent))
+	for i, record := range l.recent {
+		recent[len(l.recent)-1-i] = record
+	}
+	return recent
+}
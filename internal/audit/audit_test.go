@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestAppendAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path, 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ok := l.NewRecord("kill", "SIGTERM")
+	ok.PID, ok.Command, ok.Owner = 42, "/usr/bin/worker --fast", "bob"
+	ok.SetResult(nil)
+	failed := l.NewRecord("nice", "10")
+	failed.PID = 1
+	failed.SetResult(fmt.Errorf("failed to set priority for process 1: %w", syscall.EPERM))
+	if err := l.Append(ok, failed); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected 2 lines, got %d:\n%s", lines, data)
+	}
+
+	// A line cut short by a crash is skipped.
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	_, _ = file.WriteString("{\"time\":\n")
+	_ = file.Close()
+
+	l, err = Open(path, 3)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	recent := l.Recent()
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(recent))
+	}
+	if recent[0].Action != "nice" || recent[0].Result != "failed" || recent[0].Errno != "EPERM" {
+		t.Errorf("Unexpected newest record: %+v", recent[0])
+	}
+	if recent[1].Command != "/usr/bin/worker --fast" || recent[1].Owner != "bob" || recent[1].Result != "ok" {
+		t.Errorf("Unexpected oldest record: %+v", recent[1])
+	}
+	if recent[1].User == "" || recent[1].Time.IsZero() {
+		t.Errorf("Expected the acting user and time, got %+v", recent[1])
+	}
+
+	// The history keeps the last max records.
+	for i := 0; i < 3; i++ {
+		record := l.NewRecord("stop", "SIGSTOP")
+		record.PID = 100 + i
+		if err := l.Append(record); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	recent = l.Recent()
+	if len(recent) != 3 || recent[0].PID != 102 || recent[2].PID != 100 {
+		t.Errorf("Expected the last 3 records, got %+v", recent)
+	}
+}
+
+func TestHistoryOnly(t *testing.T) {
+	l, err := Open("", 10)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := l.Append(l.NewRecord("kill", "SIGTERM")); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if len(l.Recent()) != 1 {
+		t.Errorf("Expected 1 record, got %d", len(l.Recent()))
+	}
+}
+
+func TestErrnoName(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("wrapped: %w", syscall.EPERM), "EPERM"},
+		{os.ErrProcessDone, "ESRCH"},
+		{syscall.Errno(200), "errno 200"},
+		{fmt.Errorf("plain"), ""},
+	}
+	for _, tt := range tests {
+		if got := ErrnoName(tt.err); got != tt.want {
+			t.Errorf("ErrnoName(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
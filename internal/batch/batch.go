@@ -0,0 +1,244 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+	FormatTable  Format = "table"
+)
+
+var formats = []Format{FormatJSON, FormatNDJSON, FormatCSV, FormatTable}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (want one of %s)", s, joinNames(formats))
+}
+
+// Section names match the keys of a snapshot in JSON output.
+type Section string
+
+const (
+	SectionOverview  Section = "overview"
+	SectionCPU       Section = "cpu"
+	SectionMemory    Section = "memory"
+	SectionStorage   Section = "storage"
+	SectionNetwork   Section = "network"
+	SectionProcesses Section = "processes"
+	SectionLogs      Section = "logs"
+)
+
+var AllSections = []Section{
+	SectionOverview, SectionCPU, SectionMemory, SectionStorage,
+	SectionNetwork, SectionProcesses, SectionLogs,
+}
+
+// ParseSections reads a comma separated list of sections. An empty list
+// selects every section.
+func ParseSections(s string) ([]Section, error) {
+	if strings.TrimSpace(s) == "" {
+		return AllSections, nil
+	}
+
+	var sections []Section
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, section := range AllSections {
+			if string(section) == name {
+				sections = append(sections, section)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown section %q (want some of %s)", name, joinNames(AllSections))
+		}
+	}
+	return sections, nil
+}
+
+func joinNames[T ~string](values []T) string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = string(v)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Writer writes one snapshot per iteration. Close must be called after the
+// last one to complete the output.
+type Writer interface {
+	Write(snapshot *models.MetricsSnapshot) error
+	Close() error
+}
+
+func NewWriter(w io.Writer, format Format, sections []Section) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{w: w, sections: sections}, nil
+	case FormatNDJSON:
+		return &jsonWriter{w: w, sections: sections, lines: true}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), sections: sections, headers: make(map[string]bool)}, nil
+	case FormatTable:
+		return &tableWriter{w: w, sections: sections}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// selectSections returns the selected sections of a snapshot, keyed like
+// the full snapshot is in JSON.
+func selectSections(snapshot *models.MetricsSnapshot, sections []Section) map[string]any {
+	out := map[string]any{"timestamp": snapshot.Timestamp}
+	for _, section := range sections {
+		switch section {
+		case SectionOverview:
+			out[string(section)] = snapshot.Overview
+		case SectionCPU:
+			out[string(section)] = snapshot.CPU
+		case SectionMemory:
+			out[string(section)] = snapshot.Memory
+		case SectionStorage:
+			out[string(section)] = snapshot.Storage
+		case SectionNetwork:
+			out[string(section)] = snapshot.Network
+		case SectionProcesses:
+			out[string(section)] = snapshot.Processes
+		case SectionLogs:
+			out[string(section)] = snapshot.Logs
+		}
+	}
+	return out
+}
+
+// jsonWriter writes a JSON array of snapshots, or one snapshot per line.
+type jsonWriter struct {
+	w        io.Writer
+	sections []Section
+	lines    bool
+	count    int
+}
+
+func (j *jsonWriter) Write(snapshot *models.MetricsSnapshot) error {
+	data, err := json.Marshal(selectSections(snapshot, j.sections))
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if !j.lines {
+		prefix = ",\n"
+		if j.count == 0 {
+			prefix = "[\n"
+		}
+	}
+	suffix := ""
+	if j.lines {
+		suffix = "\n"
+	}
+	j.count++
+
+	_, err = io.WriteString(j.w, prefix+string(data)+suffix)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.lines {
+		return nil
+	}
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+// csvWriter writes the tables of every snapshot as rows prefixed with the
+// snapshot time and table name. Each table's header row is written before
+// its first row.
+type csvWriter struct {
+	w        *csv.Writer
+	sections []Section
+	headers  map[string]bool
+}
+
+func (c *csvWriter) Write(snapshot *models.MetricsSnapshot) error {
+	timestamp := snapshot.Timestamp.Format("2006-01-02T15:04:05.000Z07:00")
+	for _, t := range buildTables(snapshot, c.sections) {
+		if !c.headers[t.name] {
+			c.headers[t.name] = true
+			header := append([]string{"timestamp", "table"}, t.columns...)
+			if err := c.w.Write(header); err != nil {
+				return err
+			}
+		}
+		for _, row := range t.rows {
+			record := []string{timestamp, t.name}
+			for _, cell := range row {
+				record = append(record, formatRaw(cell))
+			}
+			if err := c.w.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// tableWriter writes human readable, aligned tables, like top -b.
+type tableWriter struct {
+	w        io.Writer
+	sections []Section
+	count    int
+}
+
+func (t *tableWriter) Write(snapshot *models.MetricsSnapshot) error {
+	tw := tabwriter.NewWriter(t.w, 0, 0, 2, ' ', 0)
+	if t.count > 0 {
+		fmt.Fprintln(tw)
+	}
+	t.count++
+	fmt.Fprintf(tw, "=== %s ===\n", utils.FormatDateTime(snapshot.Timestamp))
+
+	for _, table := range buildTables(snapshot, t.sections) {
+		fmt.Fprintf(tw, "\n%s\n", strings.ToUpper(table.name))
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(table.columns, "\t")))
+		for _, row := range table.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = formatHuman(cell)
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+func (t *tableWriter) Close() error {
+	return nil
+}
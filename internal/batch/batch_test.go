@@ -0,0 +1,167 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func testSnapshot(ts time.Time) *models.MetricsSnapshot {
+	return &models.MetricsSnapshot{
+		Timestamp: ts,
+		CPU: models.CPUMetrics{
+			Usage:     30,
+			Breakdown: models.CPUBreakdown{User: 20, System: 2.5, Idle: 70, Steal: 7.5},
+			Cores:     []models.CPUCoreMetrics{{ID: 0, Usage: 30, Breakdown: models.CPUBreakdown{User: 30, Idle: 70}}},
+		},
+		Memory: models.MemoryMetrics{Total: 2048, Used: 1024, UsedPercent: 50},
+		Processes: models.ProcessMetrics{
+			Count: 2,
+			Processes: []models.Process{
+				{PID: 1, Name: "init", Command: "/sbin/init\tsplash", CPUPercent: 1.5, MemoryRSS: 4096},
+				{PID: 42, Name: "worker, main", CPUTime: 1500 * time.Millisecond},
+			},
+		},
+	}
+}
+
+func writeAll(t *testing.T, format Format, sections []Section, n int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, sections)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		if err := w.Write(testSnapshot(start.Add(time.Duration(i) * time.Second))); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestParse(t *testing.T) {
+	sections, err := ParseSections("processes, memory")
+	if err != nil || len(sections) != 2 || sections[0] != SectionProcesses || sections[1] != SectionMemory {
+		t.Errorf("Unexpected sections %v (%v)", sections, err)
+	}
+	if sections, _ := ParseSections(""); len(sections) != len(AllSections) {
+		t.Errorf("Expected every section by default, got %v", sections)
+	}
+	if _, err := ParseSections("memory,disks"); err == nil {
+		t.Error("Expected an unknown section to be rejected")
+	}
+
+	if format, err := ParseFormat("ndjson"); err != nil || format != FormatNDJSON {
+		t.Errorf("Unexpected format %q (%v)", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	out := writeAll(t, FormatJSON, []Section{SectionMemory}, 2)
+
+	var snapshots []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out), &snapshots); err != nil {
+		t.Fatalf("Expected a JSON array, got %v:\n%s", err, out)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snapshots))
+	}
+	if _, ok := snapshots[0]["memory"]; !ok {
+		t.Error("Expected the memory section")
+	}
+	if _, ok := snapshots[0]["processes"]; ok {
+		t.Error("Expected unselected sections to be left out")
+	}
+
+	if out := writeAll(t, FormatJSON, AllSections, 0); strings.TrimSpace(out) != "[]" {
+		t.Errorf("Expected an empty array, got %q", out)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	out := writeAll(t, FormatNDJSON, AllSections, 3)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var snapshot models.MetricsSnapshot
+		if err := json.Unmarshal([]byte(line), &snapshot); err != nil {
+			t.Fatalf("Invalid line %q: %v", line, err)
+		}
+		if len(snapshot.Processes.Processes) != 2 {
+			t.Errorf("Expected processes in every line")
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	out := writeAll(t, FormatCSV, []Section{SectionProcesses}, 2)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	// One header, then two processes per iteration.
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(records))
+	}
+	if records[0][0] != "timestamp" || records[0][2] != "pid" {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	last := records[4]
+	if last[1] != "processes" || last[2] != "42" || last[12] != "worker, main" || last[11] != "1.5" {
+		t.Errorf("Unexpected row %v", last)
+	}
+}
+
+func TestCPUBreakdownColumns(t *testing.T) {
+	out := writeAll(t, FormatCSV, []Section{SectionCPU}, 1)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header, the total and one core, got %d records", len(records))
+	}
+	columns := make(map[string]int)
+	for i, column := range records[0] {
+		columns[column] = i
+	}
+	total, core := records[1], records[2]
+	if total[columns["steal_percent"]] != "7.50" || total[columns["user_percent"]] != "20.00" {
+		t.Errorf("Unexpected total row %v", total)
+	}
+	if core[columns["cpu"]] != "0" || core[columns["user_percent"]] != "30.00" {
+		t.Errorf("Unexpected core row %v", core)
+	}
+
+	out = writeAll(t, FormatJSON, []Section{SectionCPU}, 1)
+	if !strings.Contains(out, `"breakdown":{"user":20,"nice":0,"system":2.5,"idle":70,"iowait":0,"irq":0,"softirq":0,"steal":7.5,"guest":0}`) {
+		t.Errorf("Expected the breakdown in JSON output:\n%s", out)
+	}
+}
+
+func TestTable(t *testing.T) {
+	out := writeAll(t, FormatTable, []Section{SectionMemory, SectionProcesses}, 1)
+
+	for _, expected := range []string{"=== 2025-01-01 00:00:00 ===", "MEMORY", "USED_PERCENT", "50.0%", "PROCESSES", "4.0 KB", "/sbin/init splash"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected table output to contain %q:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package batch
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+// Cell types that are written raw in CSV but formatted for people in
+// tables.
+type (
+	byteCount uint64
+	rate      float64
+	percent   float64
+)
+
+type table struct {
+	name    string
+	columns []string
+	rows    [][]any
+}
+
+// buildTables flattens the selected sections into tables. Sections with
+// lists, such as storage, become one table per list.
+func buildTables(snapshot *models.MetricsSnapshot, sections []Section) []table {
+	var tables []table
+	for _, section := range sections {
+		switch section {
+		case SectionOverview:
+			tables = append(tables, overviewTable(&snapshot.Overview))
+		case SectionCPU:
+			tables = append(tables, cpuTable(&snapshot.CPU))
+		case SectionMemory:
+			tables = append(tables, memoryTable(&snapshot.Memory))
+		case SectionStorage:
+			tables = append(tables, filesystemTable(&snapshot.Storage), diskIOTable(&snapshot.Storage))
+		case SectionNetwork:
+			tables = append(tables, networkTable(&snapshot.Network))
+		case SectionProcesses:
+			tables = append(tables, processTable(&snapshot.Processes))
+		case SectionLogs:
+			tables = append(tables, logTable(&snapshot.Logs))
+		}
+	}
+	return tables
+}
+
+func overviewTable(o *models.SystemOverview) table {
+	return table{
+		name:    "overview",
+		columns: []string{"hostname", "kernel", "uptime", "boot_time", "cpu_cores", "total_memory"},
+		rows: [][]any{{
+			o.Hostname, o.Kernel, o.Uptime, o.BootTime, o.CPUCores, byteCount(o.TotalMemory),
+		}},
+	}
+}
+
+func cpuTable(cpu *models.CPUMetrics) table {
+	t := table{
+		name: "cpu",
+		columns: []string{"cpu", "usage", "user", "nice", "system", "idle", "iowait", "irq", "softirq", "steal", "load1", "load5", "load15",
+			"user_percent", "nice_percent", "system_percent", "idle_percent", "iowait_percent", "irq_percent",
+			"softirq_percent", "steal_percent", "guest_percent"},
+	}
+	// The raw counters come first, followed by the share of each state
+	// since the previous snapshot.
+	row := func(name string, usage float64, times models.CPUTimes, load []any, b models.CPUBreakdown) []any {
+		r := append([]any{
+			name, percent(usage), times.User, times.Nice, times.System, times.Idle,
+			times.IOWait, times.IRQ, times.SoftIRQ, times.Steal,
+		}, load...)
+		return append(r,
+			percent(b.User), percent(b.Nice), percent(b.System), percent(b.Idle), percent(b.IOWait),
+			percent(b.IRQ), percent(b.SoftIRQ), percent(b.Steal), percent(b.Guest))
+	}
+	t.rows = append(t.rows, row("total", cpu.Usage, cpu.Times,
+		[]any{cpu.LoadAverage[0], cpu.LoadAverage[1], cpu.LoadAverage[2]}, cpu.Breakdown))
+	for _, core := range cpu.Cores {
+		t.rows = append(t.rows, row(strconv.Itoa(core.ID), core.Usage, core.Times, []any{"", "", ""}, core.Breakdown))
+	}
+	return t
+}
+
+func memoryTable(mem *models.MemoryMetrics) table {
+	return table{
+		name: "memory",
+		columns: []string{"total", "used", "used_percent", "available", "free", "cached", "buffers", "shared",
+			"swap_total", "swap_used", "swap_used_percent"},
+		rows: [][]any{{
+			byteCount(mem.Total), byteCount(mem.Used), percent(mem.UsedPercent), byteCount(mem.Available),
+			byteCount(mem.Free), byteCount(mem.Cached), byteCount(mem.Buffers), byteCount(mem.Shared),
+			byteCount(mem.Swap.Total), byteCount(mem.Swap.Used), percent(mem.Swap.UsedPercent),
+		}},
+	}
+}
+
+func filesystemTable(storage *models.StorageMetrics) table {
+	t := table{
+		name:    "filesystems",
+		columns: []string{"mountpoint", "device", "fstype", "total", "used", "free", "used_percent", "inodes_used", "inodes_free"},
+	}
+	for _, fs := range storage.Filesystems {
+		t.rows = append(t.rows, []any{
+			fs.Mountpoint, fs.Device, fs.FSType, byteCount(fs.Total), byteCount(fs.Used), byteCount(fs.Free),
+			percent(fs.UsedPercent), fs.InodesUsed, fs.InodesFree,
+		})
+	}
+	return t
+}
+
+func diskIOTable(storage *models.StorageMetrics) table {
+	t := table{
+		name:    "disk_io",
+		columns: []string{"device", "read_iops", "write_iops", "read_bytes_per_sec", "write_bytes_per_sec", "busy_percent", "in_flight"},
+	}
+	for _, io := range storage.IOStats {
+		t.rows = append(t.rows, []any{
+			io.Device, io.IOPSRead, io.IOPSWrite, rate(io.ReadBytesPerSec), rate(io.WriteBytesPerSec),
+			percent(io.IOWaitPercent), io.InFlight,
+		})
+	}
+	return t
+}
+
+func networkTable(network *models.NetworkMetrics) table {
+	t := table{
+		name: "interfaces",
+		columns: []string{"interface", "state", "recv_bytes_per_sec", "sent_bytes_per_sec", "bytes_recv", "bytes_sent",
+			"errors_recv", "errors_sent", "dropped_recv", "dropped_sent"},
+	}
+	for _, iface := range network.Interfaces {
+		t.rows = append(t.rows, []any{
+			iface.Name, iface.State, rate(iface.RecvBytesPerSec), rate(iface.SentBytesPerSec),
+			byteCount(iface.BytesRecv), byteCount(iface.BytesSent),
+			iface.ErrorsRecv, iface.ErrorsSent, iface.DroppedRecv, iface.DroppedSent,
+		})
+	}
+	return t
+}
+
+func processTable(processes *models.ProcessMetrics) table {
+	t := table{
+		name:    "processes",
+		columns: []string{"pid", "ppid", "user", "state", "cpu_percent", "memory_percent", "rss", "vms", "threads", "cpu_time", "name", "command"},
+	}
+	for _, p := range processes.Processes {
+		t.rows = append(t.rows, []any{
+			p.PID, p.PPID, p.User, p.State, percent(p.CPUPercent), percent(p.MemoryPercent),
+			byteCount(p.MemoryRSS), byteCount(p.MemoryVMS), p.NumThreads, p.CPUTime, p.Name, p.Command,
+		})
+	}
+	return t
+}
+
+func logTable(logs *models.LogMetrics) table {
+	t := table{
+		name:    "logs",
+		columns: []string{"time", "level", "service", "message"},
+	}
+	for _, entry := range logs.Entries {
+		t.rows = append(t.rows, []any{entry.Timestamp, entry.Level, entry.Service, entry.Message})
+	}
+	return t
+}
+
+// formatRaw formats a cell for machines: plain numbers, seconds for
+// durations and RFC 3339 for times.
+func formatRaw(cell any) string {
+	switch v := cell.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case byteCount:
+		return strconv.FormatUint(uint64(v), 10)
+	case rate:
+		return strconv.FormatFloat(float64(v), 'f', 2, 64)
+	case percent:
+		return strconv.FormatFloat(float64(v), 'f', 2, 64)
+	case time.Duration:
+		return strconv.FormatFloat(v.Seconds(), 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	}
+	return ""
+}
+
+// formatHuman formats a cell for a table meant to be read in a terminal.
+func formatHuman(cell any) string {
+	switch v := cell.(type) {
+	case string:
+		return strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' {
+				return ' '
+			}
+			return r
+		}, v)
+	case float64:
+		return utils.FormatFloat(v, 2)
+	case byteCount:
+		return utils.FormatBytes(uint64(v))
+	case rate:
+		return utils.FormatBytesPerSecond(float64(v))
+	case percent:
+		return utils.FormatPercent(float64(v))
+	case time.Duration:
+		return utils.FormatDuration(v)
+	case time.Time:
+		if v.IsZero() {
+			return "-"
+		}
+		return utils.FormatDateTime(v)
+	}
+	return formatRaw(cell)
+}
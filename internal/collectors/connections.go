@@ -0,0 +1,102 @@
+package collectors
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+// ConnectionCollector lists every socket of the monitored system with the
+// process that owns it, like ss -p. Owners are found by reading the fd
+// links of every process, so it is only run while the connections are
+// shown.
+type ConnectionCollector struct {
+	procReader *system.ProcReader
+}
+
+func NewConnectionCollector(src *system.Source) *ConnectionCollector {
+	return &ConnectionCollector{procReader: src.Proc}
+}
+
+// Collect returns the sockets sorted by protocol and local port. Sockets of
+// processes that cannot be inspected have no owner.
+func (c *ConnectionCollector) Collect() ([]models.Socket, error) {
+	sockets, err := readSockets(c.procReader, "net")
+	if err != nil {
+		return nil, err
+	}
+
+	owners := c.socketOwners()
+	names := make(map[int]string)
+	for i := range sockets {
+		pid, ok := owners[sockets[i].Inode]
+		if !ok || sockets[i].Inode == 0 {
+			continue
+		}
+		if _, ok := names[pid]; !ok {
+			names[pid] = c.processName(pid)
+		}
+		sockets[i].PID = pid
+		sockets[i].Process = names[pid]
+	}
+
+	order := make(map[string]int, len(socketProtocols))
+	for i, protocol := range socketProtocols {
+		order[protocol] = i
+	}
+	sort.SliceStable(sockets, func(i, j int) bool {
+		a, b := sockets[i], sockets[j]
+		if a.Protocol != b.Protocol {
+			return order[a.Protocol] < order[b.Protocol]
+		}
+		if a.LocalPort != b.LocalPort {
+			return a.LocalPort < b.LocalPort
+		}
+		return a.Path < b.Path
+	})
+	return sockets, nil
+}
+
+// socketOwners maps socket inodes to the PID of the first process with the
+// socket open.
+func (c *ConnectionCollector) socketOwners() map[uint64]int {
+	owners := make(map[uint64]int)
+	pids, err := c.procReader.ReadProcesses()
+	if err != nil {
+		return owners
+	}
+	for _, pid := range pids {
+		fds, err := c.procReader.ReadProcessFDs(pid)
+		if err != nil {
+			continue
+		}
+		pidInt, _ := strconv.Atoi(pid)
+		for _, fd := range fds {
+			target, err := c.procReader.ReadLink(pid + "/fd/" + fd)
+			if err != nil || fileType(target) != "socket" {
+				continue
+			}
+			if inode := socketInode(target); inode != 0 {
+				if _, ok := owners[inode]; !ok {
+					owners[inode] = pidInt
+				}
+			}
+		}
+	}
+	return owners
+}
+
+func (c *ConnectionCollector) processName(pid int) string {
+	stat, err := c.procReader.ReadProcessStat(strconv.Itoa(pid))
+	if err != nil {
+		return ""
+	}
+	open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return ""
+	}
+	return stat[open+1 : end]
+}
@@ -0,0 +1,54 @@
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+func TestConnectionCollector(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "net/tcp", "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"+
+		"   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1068 1 0000000000000000 100 0 0 10 0\n"+
+		"   1: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0\n")
+	writeProcFile(t, root, "net/unix", "Num       RefCount Protocol Flags    Type St Inode Path\n"+
+		"0000000000000000: 00000002 00000000 00010000 0001 01 4001 /run/app.sock\n")
+	writeProcFile(t, root, "42/stat", fakeProcessStat("42", "web server", 1))
+	writeProcFile(t, root, "7/stat", fakeProcessStat("7", "sshd", 1))
+	for path, target := range map[string]string{
+		"42/fd/3": "socket:[1068]",
+		"42/fd/4": "socket:[4001]",
+		"42/fd/5": "/var/log/web.log",
+		"7/fd/3":  "socket:[2001]",
+	} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, full); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()})
+	sockets, err := NewConnectionCollector(src).Collect()
+	if err != nil {
+		t.Fatalf("Connection collection failed: %v", err)
+	}
+	if len(sockets) != 3 {
+		t.Fatalf("Expected 3 sockets, got %d", len(sockets))
+	}
+
+	// Sorted by protocol, then local port.
+	if s := sockets[0]; s.LocalPort != 22 || s.PID != 7 || s.Process != "sshd" {
+		t.Errorf("Unexpected first socket %+v", s)
+	}
+	if s := sockets[1]; s.LocalPort != 8080 || s.PID != 42 || s.Process != "web server" {
+		t.Errorf("Unexpected second socket %+v", s)
+	}
+	if s := sockets[2]; s.Protocol != "unix" || s.PID != 42 {
+		t.Errorf("Unexpected unix socket %+v", s)
+	}
+}
@@ -11,6 +11,7 @@ import (
 )
 
 type CPUCollector struct {
+	source       *system.Source
 	procReader   *system.ProcReader
 	sysReader    *system.SysReader
 	lastCPUTimes map[int]models.CPUTimes
@@ -18,17 +19,22 @@ type CPUCollector struct {
 }
 
 func NewCPUCollector() *CPUCollector {
+	return NewCPUCollectorWithSource(system.DefaultSource())
+}
+
+func NewCPUCollectorWithSource(src *system.Source) *CPUCollector {
 	return &CPUCollector{
-		procReader:   system.NewProcReader(),
-		sysReader:    system.NewSysReader(),
+		source:       src,
+		procReader:   src.Proc,
+		sysReader:    src.Sys,
 		lastCPUTimes: make(map[int]models.CPUTimes),
-		lastUpdate:   time.Now(),
+		lastUpdate:   src.Now(),
 	}
 }
 
 func (c *CPUCollector) Collect() (*models.CPUMetrics, error) {
 	metrics := &models.CPUMetrics{
-		Timestamp: time.Now(),
+		Timestamp: c.source.Now(),
 	}
 
 	if err := c.collectCPUStats(metrics); err != nil {
@@ -70,6 +76,7 @@ func (c *CPUCollector) collectCPUStats(metrics *models.CPUMetrics) error {
 			lastTimes, exists := c.lastCPUTimes[-1]
 			if exists {
 				metrics.Usage = c.calculateCPUUsage(lastTimes, times)
+				metrics.Breakdown = calculateCPUBreakdown(lastTimes, times)
 			}
 			c.lastCPUTimes[-1] = times
 		} else if strings.HasPrefix(line, "cpu") {
@@ -86,6 +93,7 @@ func (c *CPUCollector) collectCPUStats(metrics *models.CPUMetrics) error {
 			lastTimes, exists := c.lastCPUTimes[cpuID]
 			if exists {
 				core.Usage = c.calculateCPUUsage(lastTimes, times)
+				core.Breakdown = calculateCPUBreakdown(lastTimes, times)
 			}
 			c.lastCPUTimes[cpuID] = times
 
@@ -95,7 +103,7 @@ func (c *CPUCollector) collectCPUStats(metrics *models.CPUMetrics) error {
 
 	metrics.Times = totalTimes
 	metrics.Cores = cores
-	c.lastUpdate = time.Now()
+	c.lastUpdate = c.source.Now()
 
 	return nil
 }
@@ -188,6 +196,53 @@ func (c *CPUCollector) calculateCPUUsage(prev, curr models.CPUTimes) float64 {
 	return (float64(totalDiff-idleDiff) / float64(totalDiff)) * 100.0
 }
 
+// calculateCPUBreakdown splits the time between two readings by state. Guest
+// time is taken out of user and nice time, which include it. A counter
+// that went backwards, e.g. after a CPU came back online, counts as zero.
+func calculateCPUBreakdown(prev, curr models.CPUTimes) models.CPUBreakdown {
+	delta := func(prev, curr uint64) float64 {
+		if curr < prev {
+			return 0
+		}
+		return float64(curr - prev)
+	}
+	guest := delta(prev.Guest, curr.Guest)
+	guestNice := delta(prev.GuestNice, curr.GuestNice)
+
+	b := models.CPUBreakdown{
+		User:    delta(prev.User, curr.User) - guest,
+		Nice:    delta(prev.Nice, curr.Nice) - guestNice,
+		System:  delta(prev.System, curr.System),
+		Idle:    delta(prev.Idle, curr.Idle),
+		IOWait:  delta(prev.IOWait, curr.IOWait),
+		IRQ:     delta(prev.IRQ, curr.IRQ),
+		SoftIRQ: delta(prev.SoftIRQ, curr.SoftIRQ),
+		Steal:   delta(prev.Steal, curr.Steal),
+		Guest:   guest + guestNice,
+	}
+	// The counters are read one after another, so guest time can run ahead
+	// of the user time that includes it.
+	b.User = max(b.User, 0)
+	b.Nice = max(b.Nice, 0)
+
+	total := b.User + b.Nice + b.System + b.Idle + b.IOWait + b.IRQ + b.SoftIRQ + b.Steal + b.Guest
+	if total == 0 {
+		return models.CPUBreakdown{}
+	}
+	scale := 100 / total
+	return models.CPUBreakdown{
+		User:    b.User * scale,
+		Nice:    b.Nice * scale,
+		System:  b.System * scale,
+		Idle:    b.Idle * scale,
+		IOWait:  b.IOWait * scale,
+		IRQ:     b.IRQ * scale,
+		SoftIRQ: b.SoftIRQ * scale,
+		Steal:   b.Steal * scale,
+		Guest:   b.Guest * scale,
+	}
+}
+
 func (c *CPUCollector) collectLoadAverage(metrics *models.CPUMetrics) error {
 	loadStr, err := c.procReader.ReadLoadAvg()
 	if err != nil {
@@ -1,10 +1,14 @@
 package collectors
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/admiller/ltop/internal/fixture"
 	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
 )
 
 func TestCPUCollector(t *testing.T) {
@@ -78,6 +82,120 @@ func TestCPUCalculation(t *testing.T) {
 	}
 }
 
+func TestCPUBreakdown(t *testing.T) {
+	prev := models.CPUTimes{User: 1000, Nice: 100, System: 500, Idle: 8000, IOWait: 400, Steal: 50, Guest: 200}
+	// 1000 jiffies: 300 user of which 100 guest, 100 system, 400 idle, 50
+	// iowait, 20 irq, 30 softirq and 100 steal.
+	curr := models.CPUTimes{User: 1300, Nice: 100, System: 600, Idle: 8400, IOWait: 450, IRQ: 20, SoftIRQ: 30, Steal: 150, Guest: 300}
+
+	b := calculateCPUBreakdown(prev, curr)
+	expected := models.CPUBreakdown{User: 20, System: 10, Idle: 40, IOWait: 5, IRQ: 2, SoftIRQ: 3, Steal: 10, Guest: 10}
+	if b != expected {
+		t.Errorf("Expected breakdown %+v, got %+v", expected, b)
+	}
+
+	// A counter that went backwards does not make a state negative.
+	b = calculateCPUBreakdown(curr, prev)
+	if b != (models.CPUBreakdown{}) {
+		t.Errorf("Expected an empty breakdown for counters that went back, got %+v", b)
+	}
+}
+
+func TestCPUCollectorWithSource(t *testing.T) {
+	procRoot := t.TempDir()
+	writeFile(t, filepath.Join(procRoot, "stat"), "cpu  100 0 100 800 0 0 0 0 0 0\ncpu0 100 0 100 800 0 0 0 0 0 0\n")
+	writeFile(t, filepath.Join(procRoot, "loadavg"), "0.50 0.25 0.10 1/100 1234\n")
+
+	src := system.NewSource(system.Paths{ProcRoot: procRoot, SysRoot: t.TempDir()})
+	collector := NewCPUCollectorWithSource(src)
+
+	if _, err := collector.Collect(); err != nil {
+		t.Fatalf("CPU collection failed: %v", err)
+	}
+
+	writeFile(t, filepath.Join(procRoot, "stat"), "cpu  150 0 150 900 0 0 0 0 0 0\ncpu0 150 0 150 900 0 0 0 0 0 0\n")
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("CPU collection failed: %v", err)
+	}
+
+	if abs(metrics.Usage-50.0) > 0.01 {
+		t.Errorf("Expected 50%% usage from fixture, got %f", metrics.Usage)
+	}
+
+	if len(metrics.Cores) != 1 {
+		t.Fatalf("Expected 1 core from fixture, got %d", len(metrics.Cores))
+	}
+	if abs(metrics.Breakdown.User-25.0) > 0.01 || abs(metrics.Cores[0].Breakdown.Idle-50.0) > 0.01 {
+		t.Errorf("Expected 25%% user and 50%% idle from fixture, got %+v", metrics.Breakdown)
+	}
+
+	if metrics.LoadAverage[0] != 0.5 {
+		t.Errorf("Expected load average 0.5 from fixture, got %f", metrics.LoadAverage[0])
+	}
+}
+
+func TestCPUCollectorReplay(t *testing.T) {
+	replay := openTestFixture(t)
+	collector := NewCPUCollectorWithSource(replay.Source())
+
+	replay.Next()
+	if _, err := collector.Collect(); err != nil {
+		t.Fatalf("CPU collection failed: %v", err)
+	}
+
+	replay.Next()
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("CPU collection failed: %v", err)
+	}
+
+	// Frame deltas: total 500 jiffies, 300 of them idle or iowait.
+	if abs(metrics.Usage-40.0) > 0.01 {
+		t.Errorf("Expected total usage 40%%, got %f", metrics.Usage)
+	}
+
+	expectedCores := []float64{75.0, 100.0 / 6.0}
+	if len(metrics.Cores) != len(expectedCores) {
+		t.Fatalf("Expected %d cores, got %d", len(expectedCores), len(metrics.Cores))
+	}
+	for i, expected := range expectedCores {
+		if abs(metrics.Cores[i].Usage-expected) > 0.01 {
+			t.Errorf("Core %d: expected usage %f, got %f", i, expected, metrics.Cores[i].Usage)
+		}
+	}
+
+	if metrics.LoadAverage[0] != 0.6 {
+		t.Errorf("Expected load average 0.6, got %f", metrics.LoadAverage[0])
+	}
+
+	if !metrics.Timestamp.Equal(time.Date(2025, 1, 1, 0, 0, 2, 0, time.UTC)) {
+		t.Errorf("Expected the frame timestamp, got %v", metrics.Timestamp)
+	}
+}
+
+// openTestFixture replays testdata/fixture, two frames captured two seconds
+// apart.
+func openTestFixture(t *testing.T) *fixture.Replay {
+	t.Helper()
+	replay, err := fixture.Open(filepath.Join("testdata", "fixture"))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	t.Cleanup(func() { _ = replay.Close() })
+	return replay
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func abs(x float64) float64 {
 	if x < 0 {
 		return -x
@@ -0,0 +1,168 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+var namespaceTypes = []string{"cgroup", "ipc", "mnt", "net", "pid", "pid_for_children", "time", "user", "uts"}
+
+// DetailCollector reads everything /proc shows about one process, for
+// inspecting it in depth. Like ThreadCollector it is created on demand.
+type DetailCollector struct {
+	procReader *system.ProcReader
+	pid        int
+}
+
+func NewDetailCollector(src *system.Source, pid int) *DetailCollector {
+	return &DetailCollector{procReader: src.Proc, pid: pid}
+}
+
+// Collect fails only when the process does not exist. Sections that cannot
+// be read are listed in Unavailable.
+func (d *DetailCollector) Collect() (*models.ProcessDetail, error) {
+	status, err := d.procReader.ReadLines(d.path("status"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process %d: %w", d.pid, err)
+	}
+
+	detail := &models.ProcessDetail{
+		PID:         d.pid,
+		Status:      parseFields(status),
+		Unavailable: make(map[string]string),
+	}
+
+	detail.Exe = d.readLink(detail, "exe")
+	detail.Cwd = d.readLink(detail, "cwd")
+	detail.Args = d.readList(detail, "cmdline")
+	detail.Environ = d.readList(detail, "environ")
+
+	if lines, err := d.procReader.ReadLines(d.path("limits")); err == nil {
+		detail.Limits = parseLimits(lines)
+	} else {
+		d.unavailable(detail, "limits", err)
+	}
+
+	if lines, err := d.procReader.ReadLines(d.path("cgroup")); err == nil {
+		detail.Cgroups = lines
+	} else {
+		d.unavailable(detail, "cgroup", err)
+	}
+
+	for _, ns := range namespaceTypes {
+		if link, err := d.procReader.ReadLink(d.path("ns/" + ns)); err == nil {
+			detail.Namespaces = append(detail.Namespaces, models.Field{Name: ns, Value: link})
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			d.unavailable(detail, "namespaces", err)
+		}
+	}
+
+	if wchan, err := d.procReader.ReadFirstLine(d.path("wchan")); err == nil {
+		detail.WChan = wchan
+	} else {
+		d.unavailable(detail, "wchan", err)
+	}
+
+	if lines, err := d.procReader.ReadLines(d.path("smaps_rollup")); err == nil {
+		// The first line is the address range the rollup covers.
+		if len(lines) > 0 {
+			lines = lines[1:]
+		}
+		detail.SmapsRollup = parseFields(lines)
+	} else {
+		d.unavailable(detail, "smaps_rollup", err)
+	}
+
+	return detail, nil
+}
+
+func (d *DetailCollector) path(name string) string {
+	return strconv.Itoa(d.pid) + "/" + name
+}
+
+func (d *DetailCollector) readLink(detail *models.ProcessDetail, name string) string {
+	link, err := d.procReader.ReadLink(d.path(name))
+	if err != nil {
+		d.unavailable(detail, name, err)
+	}
+	return link
+}
+
+// readList reads a file of NUL terminated strings, such as cmdline.
+func (d *DetailCollector) readList(detail *models.ProcessDetail, name string) []string {
+	data, err := d.procReader.ReadFile(d.path(name))
+	if err != nil {
+		d.unavailable(detail, name, err)
+		return nil
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+}
+
+func (d *DetailCollector) unavailable(detail *models.ProcessDetail, section string, err error) {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	detail.Unavailable[section] = err.Error()
+}
+
+// parseFields splits "Name: value" lines, keeping their order.
+func parseFields(lines []string) []models.Field {
+	fields := make([]models.Field, 0, len(lines))
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields = append(fields, models.Field{
+			Name:  strings.TrimSpace(name),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return fields
+}
+
+// parseLimits reads the fixed width table of /proc/[pid]/limits, using the
+// header to find the columns since limit names contain spaces.
+func parseLimits(lines []string) []models.ProcessLimit {
+	if len(lines) == 0 {
+		return nil
+	}
+	header := lines[0]
+	soft := strings.Index(header, "Soft Limit")
+	hard := strings.Index(header, "Hard Limit")
+	units := strings.Index(header, "Units")
+	if soft < 0 || hard < soft || units < hard {
+		return nil
+	}
+
+	column := func(line string, start, end int) string {
+		if start >= len(line) {
+			return ""
+		}
+		if end < 0 || end > len(line) {
+			end = len(line)
+		}
+		return strings.TrimSpace(line[start:end])
+	}
+
+	limits := make([]models.ProcessLimit, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		limits = append(limits, models.ProcessLimit{
+			Name:  column(line, 0, soft),
+			Soft:  column(line, soft, hard),
+			Hard:  column(line, hard, units),
+			Units: column(line, units, -1),
+		})
+	}
+	return limits
+}
@@ -0,0 +1,68 @@
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+func TestDetailCollector(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "42/status", "Name:\tjava\nState:\tS (sleeping)\nVmRSS:\t  2048 kB\n")
+	writeProcFile(t, root, "42/cmdline", "java\x00-Xmx1g\x00-jar\x00app with spaces.jar\x00")
+	writeProcFile(t, root, "42/limits", "Limit                     Soft Limit           Hard Limit           Units     \n"+
+		"Max cpu time              unlimited            unlimited            seconds   \n"+
+		"Max open files            1024                 524288               files     \n"+
+		"Max realtime timeout      unlimited            unlimited            us        \n")
+	writeProcFile(t, root, "42/cgroup", "0::/system.slice/app.service\n")
+	writeProcFile(t, root, "42/wchan", "futex_wait_queue")
+	writeProcFile(t, root, "42/smaps_rollup", "00400000-7fff0000 ---p 00000000 00:00 0    [rollup]\nRss:  2048 kB\nPss:  1024 kB\n")
+	if err := os.Symlink("/usr/bin/java", filepath.Join(root, "42/exe")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "42/ns"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("net:[4026531840]", filepath.Join(root, "42/ns/net")); err != nil {
+		t.Fatal(err)
+	}
+
+	src := system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()})
+	detail, err := NewDetailCollector(src, 42).Collect()
+	if err != nil {
+		t.Fatalf("Detail collection failed: %v", err)
+	}
+
+	if detail.Exe != "/usr/bin/java" || len(detail.Args) != 4 || detail.Args[3] != "app with spaces.jar" {
+		t.Errorf("Unexpected exe %q and args %q", detail.Exe, detail.Args)
+	}
+	if len(detail.Status) != 3 || detail.Status[2].Name != "VmRSS" || detail.Status[2].Value != "2048 kB" {
+		t.Errorf("Unexpected status %+v", detail.Status)
+	}
+	if len(detail.Limits) != 3 || detail.Limits[1].Name != "Max open files" || detail.Limits[1].Hard != "524288" || detail.Limits[2].Units != "us" {
+		t.Errorf("Unexpected limits %+v", detail.Limits)
+	}
+	if len(detail.Namespaces) != 1 || detail.Namespaces[0].Value != "net:[4026531840]" {
+		t.Errorf("Unexpected namespaces %+v", detail.Namespaces)
+	}
+	if len(detail.SmapsRollup) != 2 || detail.SmapsRollup[1].Name != "Pss" {
+		t.Errorf("Unexpected smaps_rollup %+v", detail.SmapsRollup)
+	}
+	if detail.WChan != "futex_wait_queue" || len(detail.Cgroups) != 1 {
+		t.Errorf("Unexpected wchan %q or cgroups %q", detail.WChan, detail.Cgroups)
+	}
+
+	// Missing files are reported rather than failing the whole detail.
+	if _, ok := detail.Unavailable["environ"]; !ok {
+		t.Errorf("Expected environ to be unavailable, got %v", detail.Unavailable)
+	}
+	if _, ok := detail.Unavailable["cwd"]; !ok {
+		t.Errorf("Expected cwd to be unavailable, got %v", detail.Unavailable)
+	}
+
+	if _, err := NewDetailCollector(src, 7).Collect(); err == nil {
+		t.Error("Expected an error for a missing process")
+	}
+}
@@ -0,0 +1,91 @@
+package collectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+// FileCollector lists the open file descriptors of one process and resolves
+// its sockets, like a small lsof. It is created on demand.
+type FileCollector struct {
+	procReader *system.ProcReader
+	pid        string
+}
+
+func NewFileCollector(src *system.Source, pid int) *FileCollector {
+	return &FileCollector{procReader: src.Proc, pid: strconv.Itoa(pid)}
+}
+
+func (f *FileCollector) Collect() (*models.ProcessFiles, error) {
+	fds, err := f.procReader.ReadProcessFDs(f.pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read open files of PID %s: %w", f.pid, err)
+	}
+
+	files := &models.ProcessFiles{Files: make([]models.OpenFile, 0, len(fds))}
+	files.PID, _ = strconv.Atoi(f.pid)
+
+	var sockets map[uint64]models.Socket
+	for _, fd := range fds {
+		target, err := f.procReader.ReadLink(f.pid + "/fd/" + fd)
+		if err != nil {
+			// Closed since the directory was read.
+			continue
+		}
+
+		file := models.OpenFile{Target: target, Type: fileType(target)}
+		file.FD, _ = strconv.Atoi(fd)
+		if file.Type == "socket" {
+			if sockets == nil {
+				// Sockets are looked up in the namespace of the process.
+				list, _ := readSockets(f.procReader, f.pid+"/net")
+				sockets = socketsByInode(list)
+			}
+			if socket, ok := sockets[socketInode(target)]; ok {
+				file.Socket = &socket
+			}
+		}
+		files.Files = append(files.Files, file)
+	}
+
+	sort.Slice(files.Files, func(i, j int) bool {
+		return files.Files[i].FD < files.Files[j].FD
+	})
+
+	if lines, err := f.procReader.ReadLines(f.pid + "/limits"); err == nil {
+		for _, limit := range parseLimits(lines) {
+			if limit.Name == "Max open files" {
+				files.FDLimit, _ = strconv.ParseUint(limit.Soft, 10, 64)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// fileType classifies the target of a /proc/[pid]/fd link, such as
+// "socket:[1234]" or "/dev/null".
+func fileType(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:["):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:["):
+		return "pipe"
+	case strings.HasPrefix(target, "anon_inode:"):
+		return "anon_inode"
+	case strings.HasPrefix(target, "/dev/"):
+		return "device"
+	}
+	return "file"
+}
+
+// socketInode returns the inode of a "socket:[1234]" link target.
+func socketInode(target string) uint64 {
+	inode, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+	return inode
+}
@@ -0,0 +1,60 @@
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+func TestFileCollector(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "42/limits", "Limit                     Soft Limit           Hard Limit           Units     \n"+
+		"Max open files            1024                 524288               files     \n")
+	writeProcFile(t, root, "42/net/tcp", "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"+
+		"   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1068 1 0000000000000000 100 0 0 10 0\n")
+	if err := os.MkdirAll(filepath.Join(root, "42/fd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for fd, target := range map[string]string{
+		"0":  "/dev/null",
+		"1":  "pipe:[51500]",
+		"3":  "/var/log/app.log",
+		"10": "socket:[1068]",
+		"11": "socket:[9999]",
+		"12": "anon_inode:[eventfd]",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, "42/fd", fd)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()})
+	files, err := NewFileCollector(src, 42).Collect()
+	if err != nil {
+		t.Fatalf("File collection failed: %v", err)
+	}
+
+	if files.FDLimit != 1024 || len(files.Files) != 6 {
+		t.Fatalf("Expected 6 files and a limit of 1024, got %d and %d", len(files.Files), files.FDLimit)
+	}
+	expected := []struct {
+		fd    int
+		kind  string
+		bound bool
+	}{{0, "device", false}, {1, "pipe", false}, {3, "file", false}, {10, "socket", true}, {11, "socket", false}, {12, "anon_inode", false}}
+	for i, want := range expected {
+		file := files.Files[i]
+		if file.FD != want.fd || file.Type != want.kind || (file.Socket != nil) != want.bound {
+			t.Errorf("Unexpected file %d: %+v", i, file)
+		}
+	}
+	if socket := files.Files[3].Socket; socket.State != "LISTEN" || socket.LocalPort != 8080 {
+		t.Errorf("Unexpected socket %+v", socket)
+	}
+
+	if _, err := NewFileCollector(src, 7).Collect(); err == nil {
+		t.Error("Expected an error for a missing process")
+	}
+}
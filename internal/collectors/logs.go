@@ -2,6 +2,7 @@ package collectors
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,9 +11,11 @@ import (
 	"time"
 
 	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
 )
 
 type LogCollector struct {
+	source        *system.Source
 	sources       []string
 	maxEntries    int
 	lastEntries   map[string]time.Time
@@ -20,6 +23,10 @@ type LogCollector struct {
 }
 
 func NewLogCollector(sources []string, maxEntries int) *LogCollector {
+	return NewLogCollectorWithSource(sources, maxEntries, system.DefaultSource())
+}
+
+func NewLogCollectorWithSource(sources []string, maxEntries int, src *system.Source) *LogCollector {
 	if len(sources) == 0 {
 		sources = []string{"/var/log/syslog", "journalctl"}
 	}
@@ -30,6 +37,7 @@ func NewLogCollector(sources []string, maxEntries int) *LogCollector {
 	logLevelRegex := regexp.MustCompile(`(?i)\b(emerg|alert|crit|err|error|warn|warning|notice|info|debug)\b`)
 
 	return &LogCollector{
+		source:        src,
 		sources:       sources,
 		maxEntries:    maxEntries,
 		lastEntries:   make(map[string]time.Time),
@@ -38,15 +46,23 @@ func NewLogCollector(sources []string, maxEntries int) *LogCollector {
 }
 
 func (l *LogCollector) Collect() (*models.LogMetrics, error) {
+	return l.CollectContext(context.Background())
+}
+
+// CollectContext is Collect with journalctl bounded by ctx.
+func (l *LogCollector) CollectContext(ctx context.Context) (*models.LogMetrics, error) {
 	metrics := &models.LogMetrics{
-		Timestamp: time.Now(),
+		Timestamp: l.source.Now(),
 		Sources:   l.sources,
 	}
 
 	allEntries := make([]models.LogEntry, 0)
 
 	for _, source := range l.sources {
-		entries, err := l.collectFromSource(source)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entries, err := l.collectFromSource(ctx, source)
 		if err != nil {
 			continue
 		}
@@ -60,15 +76,15 @@ func (l *LogCollector) Collect() (*models.LogMetrics, error) {
 	return metrics, nil
 }
 
-func (l *LogCollector) collectFromSource(source string) ([]models.LogEntry, error) {
+func (l *LogCollector) collectFromSource(ctx context.Context, source string) ([]models.LogEntry, error) {
 	if source == "journalctl" {
-		return l.collectFromJournalctl()
+		return l.collectFromJournalctl(ctx)
 	}
 	return l.collectFromFile(source)
 }
 
 func (l *LogCollector) collectFromFile(filename string) ([]models.LogEntry, error) {
-	file, err := os.Open(filename)
+	file, err := os.Open(l.source.HostPath(filename))
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +113,12 @@ func (l *LogCollector) collectFromFile(filename string) ([]models.LogEntry, erro
 	return entries, scanner.Err()
 }
 
-func (l *LogCollector) collectFromJournalctl() ([]models.LogEntry, error) {
-	cmd := exec.Command("journalctl", "-n", fmt.Sprintf("%d", l.maxEntries), "--no-pager", "-o", "short-iso")
+func (l *LogCollector) collectFromJournalctl(ctx context.Context) ([]models.LogEntry, error) {
+	args := []string{"-n", fmt.Sprintf("%d", l.maxEntries), "--no-pager", "-o", "short-iso"}
+	if l.source.IsHostRooted() {
+		args = append(args, "--root", l.source.HostPath("/"))
+	}
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
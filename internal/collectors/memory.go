@@ -4,27 +4,32 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/admiller/ltop/internal/models"
 	"github.com/admiller/ltop/internal/system"
 )
 
 type MemoryCollector struct {
+	source     *system.Source
 	procReader *system.ProcReader
 	sysReader  *system.SysReader
 }
 
 func NewMemoryCollector() *MemoryCollector {
+	return NewMemoryCollectorWithSource(system.DefaultSource())
+}
+
+func NewMemoryCollectorWithSource(src *system.Source) *MemoryCollector {
 	return &MemoryCollector{
-		procReader: system.NewProcReader(),
-		sysReader:  system.NewSysReader(),
+		source:     src,
+		procReader: src.Proc,
+		sysReader:  src.Sys,
 	}
 }
 
 func (m *MemoryCollector) Collect() (*models.MemoryMetrics, error) {
 	metrics := &models.MemoryMetrics{
-		Timestamp: time.Now(),
+		Timestamp: m.source.Now(),
 		Details:   make(map[string]uint64),
 	}
 
@@ -11,6 +11,7 @@ import (
 )
 
 type NetworkCollector struct {
+	source             *system.Source
 	procReader         *system.ProcReader
 	sysReader          *system.SysReader
 	lastInterfaceStats map[string]models.NetworkInterface
@@ -18,17 +19,22 @@ type NetworkCollector struct {
 }
 
 func NewNetworkCollector() *NetworkCollector {
+	return NewNetworkCollectorWithSource(system.DefaultSource())
+}
+
+func NewNetworkCollectorWithSource(src *system.Source) *NetworkCollector {
 	return &NetworkCollector{
-		procReader:         system.NewProcReader(),
-		sysReader:          system.NewSysReader(),
+		source:             src,
+		procReader:         src.Proc,
+		sysReader:          src.Sys,
 		lastInterfaceStats: make(map[string]models.NetworkInterface),
-		lastUpdate:         time.Now(),
+		lastUpdate:         src.Now(),
 	}
 }
 
 func (n *NetworkCollector) Collect() (*models.NetworkMetrics, error) {
 	metrics := &models.NetworkMetrics{
-		Timestamp: time.Now(),
+		Timestamp: n.source.Now(),
 	}
 
 	if err := n.collectNetworkStats(metrics); err != nil {
@@ -45,7 +51,7 @@ func (n *NetworkCollector) collectNetworkStats(metrics *models.NetworkMetrics) e
 	}
 
 	interfaces := make([]models.NetworkInterface, 0)
-	currentTime := time.Now()
+	currentTime := n.source.Now()
 	timeDelta := currentTime.Sub(n.lastUpdate).Seconds()
 
 	for i, line := range netStats {
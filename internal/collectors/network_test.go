@@ -49,3 +49,34 @@ func TestNetworkBandwidthCalculation(t *testing.T) {
 		}
 	}
 }
+
+func TestNetworkCollectorReplay(t *testing.T) {
+	replay := openTestFixture(t)
+	collector := NewNetworkCollectorWithSource(replay.Source())
+
+	replay.Next()
+	if _, err := collector.Collect(); err != nil {
+		t.Fatalf("Network collection failed: %v", err)
+	}
+
+	replay.Next()
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Network collection failed: %v", err)
+	}
+
+	if len(metrics.Interfaces) != 1 {
+		t.Fatalf("Expected eth0 only, got %d interfaces", len(metrics.Interfaces))
+	}
+
+	eth0 := metrics.Interfaces[0]
+	if eth0.State != "up" || eth0.Speed != 1000000000 {
+		t.Errorf("Unexpected interface details: state %q, speed %d", eth0.State, eth0.Speed)
+	}
+	if abs(eth0.RecvBytesPerSec-100000) > 0.01 {
+		t.Errorf("Expected 100000 B/s received, got %f", eth0.RecvBytesPerSec)
+	}
+	if abs(eth0.SentBytesPerSec-50000) > 0.01 {
+		t.Errorf("Expected 50000 B/s sent, got %f", eth0.SentBytesPerSec)
+	}
+}
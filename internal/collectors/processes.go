@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/admiller/ltop/internal/models"
@@ -13,25 +14,44 @@ import (
 )
 
 type ProcessCollector struct {
+	source       *system.Source
 	procReader   *system.ProcReader
 	lastCPUTimes map[int]uint64
 	lastUpdate   time.Time
 	totalCPU     uint64
 	lastTotalCPU uint64
+	lastIO       map[int]models.ProcessIOStats
+	nextIO       map[int]models.ProcessIOStats
+	lastIOTime   time.Time
+	ioInterval   time.Duration
+	smaps        atomic.Bool
 }
 
 func NewProcessCollector() *ProcessCollector {
+	return NewProcessCollectorWithSource(system.DefaultSource())
+}
+
+func NewProcessCollectorWithSource(src *system.Source) *ProcessCollector {
 	return &ProcessCollector{
-		procReader:   system.NewProcReader(),
+		source:       src,
+		procReader:   src.Proc,
 		lastCPUTimes: make(map[int]uint64),
-		lastUpdate:   time.Now(),
+		lastUpdate:   src.Now(),
+		lastIO:       make(map[int]models.ProcessIOStats),
 	}
 }
 
+// SetSmaps turns collection of the smaps_rollup memory breakdown on or off.
+// It is safe to call while Collect runs.
+func (p *ProcessCollector) SetSmaps(enabled bool) {
+	p.smaps.Store(enabled)
+}
+
 func (p *ProcessCollector) Collect() (*models.ProcessMetrics, error) {
 	metrics := &models.ProcessMetrics{
-		Timestamp: time.Now(),
+		Timestamp: p.source.Now(),
 		Processes: make([]models.Process, 0),
+		Smaps:     p.smaps.Load(),
 	}
 
 	pids, err := p.procReader.ReadProcesses()
@@ -47,11 +67,20 @@ func (p *ProcessCollector) Collect() (*models.ProcessMetrics, error) {
 	processes := make([]models.Process, 0, len(pids))
 	stateCounts := make(map[string]int)
 
+	p.nextIO = make(map[int]models.ProcessIOStats, len(pids))
+	if !p.lastIOTime.IsZero() {
+		p.ioInterval = metrics.Timestamp.Sub(p.lastIOTime)
+	}
+
 	for _, pid := range pids {
 		process, err := p.collectProcess(pid, totalCPU)
 		if err != nil {
 			continue
 		}
+		if metrics.Smaps {
+			// Kernel threads have no mappings and no smaps_rollup.
+			_ = p.collectProcessSmaps(pid, &process)
+		}
 
 		processes = append(processes, process)
 		stateCounts[process.State]++
@@ -60,6 +89,7 @@ func (p *ProcessCollector) Collect() (*models.ProcessMetrics, error) {
 	sort.Slice(processes, func(i, j int) bool {
 		return processes[i].CPUPercent > processes[j].CPUPercent
 	})
+	linkChildren(processes)
 
 	metrics.Processes = processes
 	metrics.Count = len(processes)
@@ -70,11 +100,30 @@ func (p *ProcessCollector) Collect() (*models.ProcessMetrics, error) {
 
 	p.lastTotalCPU = p.totalCPU
 	p.totalCPU = totalCPU
-	p.lastUpdate = time.Now()
+	p.lastUpdate = p.source.Now()
+	p.lastIO = p.nextIO
+	p.lastIOTime = metrics.Timestamp
 
 	return metrics, nil
 }
 
+// linkChildren fills in the Children of every process from the PPIDs.
+func linkChildren(processes []models.Process) {
+	index := make(map[int]int, len(processes))
+	for i := range processes {
+		index[processes[i].PID] = i
+	}
+	for i := range processes {
+		proc := &processes[i]
+		if parent, ok := index[proc.PPID]; ok && proc.PPID != proc.PID {
+			processes[parent].Children = append(processes[parent].Children, proc.PID)
+		}
+	}
+	for i := range processes {
+		sort.Ints(processes[i].Children)
+	}
+}
+
 func (p *ProcessCollector) collectProcess(pid string, totalCPU uint64) (models.Process, error) {
 	pidInt, err := strconv.Atoi(pid)
 	if err != nil {
@@ -89,6 +138,16 @@ func (p *ProcessCollector) collectProcess(pid string, totalCPU uint64) (models.P
 		return process, err
 	}
 
+	// Without permission to read the counters the process is still listed.
+	_ = p.collectProcessIO(pid, &process)
+
+	// FDSize in status is the size of the fd table, not the number of open
+	// files. Like the IO counters, the fd directory is only readable by the
+	// owner.
+	if fds, err := p.procReader.ReadProcessFDs(pid); err == nil {
+		process.NumFDs = len(fds)
+	}
+
 	if err := p.collectProcessStatus(pid, &process); err != nil {
 		return process, nil
 	}
@@ -155,6 +214,61 @@ func (p *ProcessCollector) collectProcessStat(pid string, process *models.Proces
 	return nil
 }
 
+func (p *ProcessCollector) collectProcessIO(pid string, process *models.Process) error {
+	values, err := p.procReader.ReadProcessIO(pid)
+	if err != nil {
+		return err
+	}
+
+	counter := func(key string) uint64 {
+		value, _ := strconv.ParseUint(values[key], 10, 64)
+		return value
+	}
+	stats := models.ProcessIOStats{
+		ReadBytes:  counter("read_bytes"),
+		WriteBytes: counter("write_bytes"),
+		ReadCount:  counter("syscr"),
+		WriteCount: counter("syscw"),
+	}
+
+	if last, ok := p.lastIO[process.PID]; ok && p.ioInterval > 0 {
+		seconds := p.ioInterval.Seconds()
+		if stats.ReadBytes >= last.ReadBytes {
+			stats.ReadBytesPerSec = float64(stats.ReadBytes-last.ReadBytes) / seconds
+		}
+		if stats.WriteBytes >= last.WriteBytes {
+			stats.WriteBytesPerSec = float64(stats.WriteBytes-last.WriteBytes) / seconds
+		}
+	}
+
+	process.IOStats = stats
+	p.nextIO[process.PID] = stats
+	return nil
+}
+
+func (p *ProcessCollector) collectProcessSmaps(pid string, process *models.Process) error {
+	values, err := p.procReader.ReadProcessSmapsRollup(pid)
+	if err != nil {
+		return err
+	}
+
+	kilobytes := func(keys ...string) uint64 {
+		var total uint64
+		for _, key := range keys {
+			if fields := strings.Fields(values[key]); len(fields) > 0 {
+				value, _ := strconv.ParseUint(fields[0], 10, 64)
+				total += value * 1024
+			}
+		}
+		return total
+	}
+	process.MemoryPSS = kilobytes("Pss")
+	process.MemoryUSS = kilobytes("Private_Clean", "Private_Dirty", "Private_Hugetlb")
+	process.MemoryShared = kilobytes("Shared_Clean", "Shared_Dirty", "Shared_Hugetlb")
+	process.MemorySwap = kilobytes("Swap")
+	return nil
+}
+
 func (p *ProcessCollector) collectProcessStatus(pid string, process *models.Process) error {
 	status, err := p.procReader.ReadProcessStatus(pid)
 	if err != nil {
@@ -183,12 +297,6 @@ func (p *ProcessCollector) collectProcessStatus(pid string, process *models.Proc
 		}
 	}
 
-	if fdSize, exists := status["FDSize"]; exists {
-		if fds, err := strconv.Atoi(strings.Fields(fdSize)[0]); err == nil {
-			process.NumFDs = fds
-		}
-	}
-
 	return nil
 }
 
@@ -255,18 +363,18 @@ func (p *ProcessCollector) getTotalCPUTime() (uint64, error) {
 func (p *ProcessCollector) getBootTime() time.Time {
 	uptime, err := p.procReader.ReadUptime()
 	if err != nil {
-		return time.Now()
+		return p.source.Now()
 	}
 
 	fields := strings.Fields(uptime)
 	if len(fields) == 0 {
-		return time.Now()
+		return p.source.Now()
 	}
 
 	uptimeSeconds, err := strconv.ParseFloat(fields[0], 64)
 	if err != nil {
-		return time.Now()
+		return p.source.Now()
 	}
 
-	return time.Now().Add(-time.Duration(uptimeSeconds) * time.Second)
+	return p.source.Now().Add(-time.Duration(uptimeSeconds) * time.Second)
 }
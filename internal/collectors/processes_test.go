@@ -1,8 +1,15 @@
 package collectors
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/admiller/ltop/internal/system"
 )
 
 func TestProcessCollector(t *testing.T) {
@@ -79,3 +86,131 @@ func TestProcessCPUCalculation(t *testing.T) {
 		}
 	}
 }
+
+// writeProcFile writes a file below a fake proc root.
+func writeProcFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeProcessStat returns a /proc/[pid]/stat line with every field the
+// collector needs.
+func fakeProcessStat(pid, name string, ppid int) string {
+	fields := make([]string, 52)
+	for i := range fields {
+		fields[i] = "0"
+	}
+	fields[0] = pid
+	fields[1] = "(" + name + ")"
+	fields[2] = "S"
+	fields[3] = strconv.Itoa(ppid)
+	fields[19] = "1"
+	return strings.Join(fields, " ") + "\n"
+}
+
+func TestProcessIORates(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "stat", "cpu  100 0 100 800 0 0 0 0 0 0\n")
+	writeProcFile(t, root, "uptime", "100.00 50.00\n")
+	writeProcFile(t, root, "42/stat", fakeProcessStat("42", "writer", 1))
+	writeProcFile(t, root, "42/status", "Name:\twriter\n")
+	writeProcFile(t, root, "42/cmdline", "writer\x00--fast")
+	writeProcFile(t, root, "42/io", "rchar: 10\nwchar: 20\nsyscr: 1\nsyscw: 2\nread_bytes: 4096\nwrite_bytes: 8192\ncancelled_write_bytes: 0\n")
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()})
+	src.SetClock(func() time.Time { return now })
+	collector := NewProcessCollectorWithSource(src)
+
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Process collection failed: %v", err)
+	}
+	if len(metrics.Processes) != 1 {
+		t.Fatalf("Expected 1 process, got %d", len(metrics.Processes))
+	}
+	io := metrics.Processes[0].IOStats
+	if io.ReadBytes != 4096 || io.WriteCount != 2 || io.BytesPerSec() != 0 {
+		t.Errorf("Unexpected first sample %+v", io)
+	}
+
+	now = now.Add(2 * time.Second)
+	writeProcFile(t, root, "42/io", "rchar: 10\nwchar: 20\nsyscr: 1\nsyscw: 2\nread_bytes: 8192\nwrite_bytes: 1056768\ncancelled_write_bytes: 0\n")
+
+	metrics, err = collector.Collect()
+	if err != nil {
+		t.Fatalf("Process collection failed: %v", err)
+	}
+	io = metrics.Processes[0].IOStats
+	if io.ReadBytesPerSec != 2048 || io.WriteBytesPerSec != 524288 {
+		t.Errorf("Expected 2048 B/s read and 512 KiB/s write, got %.0f and %.0f", io.ReadBytesPerSec, io.WriteBytesPerSec)
+	}
+}
+
+func TestProcessChildren(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "stat", "cpu  100 0 100 800 0 0 0 0 0 0\n")
+	writeProcFile(t, root, "uptime", "100.00 50.00\n")
+	for _, proc := range []struct {
+		pid  string
+		ppid int
+	}{{"1", 0}, {"10", 1}, {"11", 10}, {"12", 10}, {"20", 1}} {
+		writeProcFile(t, root, proc.pid+"/stat", fakeProcessStat(proc.pid, "p"+proc.pid, proc.ppid))
+		writeProcFile(t, root, proc.pid+"/status", "Name:\tp"+proc.pid+"\n")
+		writeProcFile(t, root, proc.pid+"/cmdline", "")
+	}
+
+	collector := NewProcessCollectorWithSource(system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()}))
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Process collection failed: %v", err)
+	}
+
+	children := make(map[int][]int)
+	for _, proc := range metrics.Processes {
+		children[proc.PID] = proc.Children
+	}
+	expected := map[int][]int{1: {10, 20}, 10: {11, 12}, 11: nil, 12: nil, 20: nil}
+	for pid, want := range expected {
+		if fmt.Sprint(children[pid]) != fmt.Sprint(want) {
+			t.Errorf("PID %d: expected children %v, got %v", pid, want, children[pid])
+		}
+	}
+}
+
+func TestProcessSmaps(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "stat", "cpu  100 0 100 800 0 0 0 0 0 0\n")
+	writeProcFile(t, root, "uptime", "100.00 50.00\n")
+	writeProcFile(t, root, "42/stat", fakeProcessStat("42", "worker", 1))
+	writeProcFile(t, root, "42/status", "Name:\tworker\n")
+	writeProcFile(t, root, "42/cmdline", "worker")
+	writeProcFile(t, root, "42/smaps_rollup", "00400000-7ffc0000 ---p 00000000 00:00 0    [rollup]\n"+
+		"Rss:                3000 kB\nPss:                1500 kB\nShared_Clean:       1800 kB\nShared_Dirty:        200 kB\n"+
+		"Private_Clean:       100 kB\nPrivate_Dirty:       900 kB\nSwap:                 64 kB\n")
+
+	collector := NewProcessCollectorWithSource(system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()}))
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Process collection failed: %v", err)
+	}
+	if metrics.Smaps || metrics.Processes[0].MemoryPSS != 0 {
+		t.Error("Expected smaps_rollup to be skipped by default")
+	}
+
+	collector.SetSmaps(true)
+	metrics, err = collector.Collect()
+	if err != nil {
+		t.Fatalf("Process collection failed: %v", err)
+	}
+	proc := metrics.Processes[0]
+	if !metrics.Smaps || proc.MemoryPSS != 1500<<10 || proc.MemoryUSS != 1000<<10 || proc.MemoryShared != 2000<<10 || proc.MemorySwap != 64<<10 {
+		t.Errorf("Unexpected smaps breakdown %+v", proc)
+	}
+}
@@ -0,0 +1,205 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+const (
+	NameCPU       = "cpu"
+	NameMemory    = "memory"
+	NameProcesses = "processes"
+	NameStorage   = "storage"
+	NameNetwork   = "network"
+	NameLogs      = "logs"
+)
+
+// Result is the typed output of one collection pass. Apply merges it into
+// the snapshot being assembled.
+type Result interface {
+	Apply(snapshot *models.MetricsSnapshot)
+}
+
+// Collector is a source of metrics the App polls. A zero Interval or Timeout
+// means the collector follows the global refresh interval or timeout.
+// Collect should give up once ctx is done.
+type Collector interface {
+	Name() string
+	Enabled() bool
+	Interval() time.Duration
+	Timeout() time.Duration
+	Collect(ctx context.Context) (Result, error)
+}
+
+// Configurable is implemented by collectors whose settings can be changed
+// from SystemConfig.
+type Configurable interface {
+	SetEnabled(enabled bool)
+	SetInterval(interval time.Duration)
+	SetTimeout(timeout time.Duration)
+}
+
+// Base implements the bookkeeping half of Collector. Embed it and add a
+// Collect method to write a collector.
+type Base struct {
+	mu       sync.RWMutex
+	name     string
+	enabled  bool
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func NewBase(name string) *Base {
+	return &Base{
+		name:    name,
+		enabled: true,
+	}
+}
+
+func (b *Base) Name() string {
+	return b.name
+}
+
+func (b *Base) Enabled() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.enabled
+}
+
+func (b *Base) SetEnabled(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.enabled = enabled
+}
+
+func (b *Base) Interval() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.interval
+}
+
+func (b *Base) SetInterval(interval time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.interval = interval
+}
+
+func (b *Base) Timeout() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.timeout
+}
+
+func (b *Base) SetTimeout(timeout time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timeout = timeout
+}
+
+// Typed adapts a collect function with a concrete result type, such as the
+// Collect method of the built-in collectors, to the Collector interface.
+type Typed[T Result] struct {
+	*Base
+	collect func(ctx context.Context) (T, error)
+}
+
+// NewTyped wraps a collect function that cannot be cancelled.
+func NewTyped[T Result](name string, collect func() (T, error)) *Typed[T] {
+	return NewTypedContext(name, func(context.Context) (T, error) {
+		return collect()
+	})
+}
+
+func NewTypedContext[T Result](name string, collect func(ctx context.Context) (T, error)) *Typed[T] {
+	return &Typed[T]{
+		Base:    NewBase(name),
+		collect: collect,
+	}
+}
+
+func (c *Typed[T]) Collect(ctx context.Context) (Result, error) {
+	result, err := c.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CollectTyped runs a pass and returns the concrete result.
+func (c *Typed[T]) CollectTyped(ctx context.Context) (T, error) {
+	return c.collect(ctx)
+}
+
+// Registry holds the collectors an App polls, in registration order.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+	byName     map[string]Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		byName: make(map[string]Collector),
+	}
+}
+
+func (r *Registry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byName[c.Name()]; exists {
+		return fmt.Errorf("collector %q is already registered", c.Name())
+	}
+
+	r.collectors = append(r.collectors, c)
+	r.byName[c.Name()] = c
+	return nil
+}
+
+func (r *Registry) Get(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byName[name]
+	return c, ok
+}
+
+func (r *Registry) All() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]Collector, len(r.collectors))
+	copy(all, r.collectors)
+	return all
+}
+
+func (r *Registry) Enabled() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var enabled []Collector
+	for _, c := range r.collectors {
+		if c.Enabled() {
+			enabled = append(enabled, c)
+		}
+	}
+	return enabled
+}
+
+// Configure applies per-collector settings from the config. Collectors
+// without an entry are enabled and follow the global refresh interval.
+func (r *Registry) Configure(settings map[string]models.CollectorConfig) {
+	for _, c := range r.All() {
+		configurable, ok := c.(Configurable)
+		if !ok {
+			continue
+		}
+		cfg := settings[c.Name()]
+		configurable.SetEnabled(!cfg.Disabled)
+		configurable.SetInterval(cfg.Interval)
+		configurable.SetTimeout(cfg.Timeout)
+	}
+}
@@ -0,0 +1,67 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+
+	cpu := NewTyped(NameCPU, func() (*models.CPUMetrics, error) {
+		return &models.CPUMetrics{Usage: 12.5}, nil
+	})
+	failing := NewTyped(NameMemory, func() (*models.MemoryMetrics, error) {
+		return nil, errors.New("boom")
+	})
+
+	for _, c := range []Collector{cpu, failing} {
+		if err := registry.Register(c); err != nil {
+			t.Fatalf("Register(%s) failed: %v", c.Name(), err)
+		}
+	}
+	if err := registry.Register(cpu); err == nil {
+		t.Error("Expected an error registering a duplicate name")
+	}
+
+	all := registry.All()
+	if len(all) != 2 || all[0].Name() != NameCPU || all[1].Name() != NameMemory {
+		t.Errorf("Expected collectors in registration order, got %v", all)
+	}
+
+	registry.Configure(map[string]models.CollectorConfig{
+		NameMemory: {Disabled: true},
+		NameCPU:    {Interval: 5 * time.Second},
+	})
+
+	enabled := registry.Enabled()
+	if len(enabled) != 1 || enabled[0].Name() != NameCPU {
+		t.Fatalf("Expected only the cpu collector enabled, got %d", len(enabled))
+	}
+	if enabled[0].Interval() != 5*time.Second {
+		t.Errorf("Expected interval 5s, got %v", enabled[0].Interval())
+	}
+
+	result, err := enabled[0].Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var snapshot models.MetricsSnapshot
+	result.Apply(&snapshot)
+	if snapshot.CPU.Usage != 12.5 {
+		t.Errorf("Expected usage 12.5, got %.1f", snapshot.CPU.Usage)
+	}
+
+	if _, err := failing.Collect(context.Background()); err == nil {
+		t.Error("Expected the collect error to be returned")
+	}
+
+	registry.Configure(nil)
+	if len(registry.Enabled()) != 2 {
+		t.Error("Expected collectors without config to be enabled")
+	}
+}
@@ -0,0 +1,166 @@
+package collectors
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+var socketProtocols = []string{"tcp", "tcp6", "udp", "udp6", "unix"}
+
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+var unixStates = map[string]string{
+	"01": "UNCONNECTED",
+	"02": "CONNECTING",
+	"03": "CONNECTED",
+	"04": "DISCONNECTING",
+}
+
+// readSockets reads the socket tables under netDir, such as "net" or
+// "[pid]/net". Tables that are missing, for example tcp6 without IPv6, are
+// skipped; it fails only if none can be read.
+func readSockets(procReader *system.ProcReader, netDir string) ([]models.Socket, error) {
+	var sockets []models.Socket
+	var lastErr error
+	read := 0
+	for _, protocol := range socketProtocols {
+		lines, err := procReader.ReadLines(netDir + "/" + protocol)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		read++
+		if len(lines) == 0 {
+			continue
+		}
+		// The first line is a header.
+		for _, line := range lines[1:] {
+			var socket models.Socket
+			if protocol == "unix" {
+				socket, err = parseUnixSocket(line)
+			} else {
+				socket, err = parseInetSocket(protocol, line)
+			}
+			if err == nil {
+				sockets = append(sockets, socket)
+			}
+		}
+	}
+	if read == 0 {
+		return nil, fmt.Errorf("failed to read socket tables: %w", lastErr)
+	}
+	return sockets, nil
+}
+
+// socketsByInode indexes sockets by inode. Sockets without one, such as
+// those in TIME_WAIT, are left out since no file refers to them.
+func socketsByInode(sockets []models.Socket) map[uint64]models.Socket {
+	byInode := make(map[uint64]models.Socket, len(sockets))
+	for _, socket := range sockets {
+		if socket.Inode != 0 {
+			byInode[socket.Inode] = socket
+		}
+	}
+	return byInode
+}
+
+// parseInetSocket parses a line of /proc/net/{tcp,tcp6,udp,udp6}:
+//
+//	sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+func parseInetSocket(protocol, line string) (models.Socket, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 {
+		return models.Socket{}, fmt.Errorf("invalid %s socket line", protocol)
+	}
+
+	socket := models.Socket{Protocol: protocol}
+	var err error
+	if socket.LocalAddr, socket.LocalPort, err = parseSocketAddr(fields[1]); err != nil {
+		return socket, err
+	}
+	if socket.RemoteAddr, socket.RemotePort, err = parseSocketAddr(fields[2]); err != nil {
+		return socket, err
+	}
+
+	socket.State = tcpStates[fields[3]]
+	if strings.HasPrefix(protocol, "udp") {
+		// UDP sockets are either connected or not.
+		socket.State = ""
+		if fields[3] == "01" {
+			socket.State = "ESTABLISHED"
+		}
+	}
+
+	if tx, rx, ok := strings.Cut(fields[4], ":"); ok {
+		socket.TxQueue, _ = strconv.ParseUint(tx, 16, 64)
+		socket.RxQueue, _ = strconv.ParseUint(rx, 16, 64)
+	}
+	socket.Inode, err = strconv.ParseUint(fields[9], 10, 64)
+	return socket, err
+}
+
+// parseSocketAddr decodes an address such as 0100007F:1F90. The address is
+// made of 32 bit words in host byte order, which is little endian on every
+// platform ltop targets.
+func parseSocketAddr(s string) (string, int, error) {
+	hexAddr, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid socket address %q", s)
+	}
+	raw, err := hex.DecodeString(hexAddr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid socket address %q", s)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid socket port %q", s)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	return ip.String(), int(port), nil
+}
+
+// parseUnixSocket parses a line of /proc/net/unix:
+//
+//	Num RefCount Protocol Flags Type St Inode Path
+func parseUnixSocket(line string) (models.Socket, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 7 {
+		return models.Socket{}, fmt.Errorf("invalid unix socket line")
+	}
+
+	socket := models.Socket{Protocol: "unix", State: unixStates[fields[5]]}
+	if fields[3] == "00010000" {
+		// __SO_ACCEPTCON, a listening socket.
+		socket.State = "LISTEN"
+	}
+	if len(fields) > 7 {
+		socket.Path = fields[7]
+	}
+
+	var err error
+	socket.Inode, err = strconv.ParseUint(fields[6], 10, 64)
+	return socket, err
+}
@@ -0,0 +1,76 @@
+package collectors
+
+import (
+	"testing"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+func TestParseSocketAddr(t *testing.T) {
+	tests := []struct {
+		input string
+		addr  string
+		port  int
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080},
+		{"00000000:0016", "0.0.0.0", 22},
+		{"00000000000000000000000001000000:0277", "::1", 631},
+		{"0000000000000000FFFF00000100007F:C350", "127.0.0.1", 50000},
+		{"B80D0120000000000000000001000000:01BB", "2001:db8::1", 443},
+	}
+	for _, test := range tests {
+		addr, port, err := parseSocketAddr(test.input)
+		if err != nil || addr != test.addr || port != test.port {
+			t.Errorf("parseSocketAddr(%q) = %s, %d, %v; want %s, %d", test.input, addr, port, err, test.addr, test.port)
+		}
+	}
+
+	for _, input := range []string{"0100007F", "0100:1F90", "0100007F:XYZ"} {
+		if _, _, err := parseSocketAddr(input); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestReadSockets(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "net/tcp", "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"+
+		"   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1068 1 0000000000000000 100 0 0 10 0\n"+
+		"   1: 0100007F:1F90 0100007F:D431 01 0000001A:00000010 00:00000000 00000000  1000        0 2001 1 0000000000000000 20 4 30 10 -1\n"+
+		"   2: 0100007F:1F90 0100007F:D432 06 00000000:00000000 03:00000A3C 00000000     0        0 0 3 0000000000000000\n")
+	writeProcFile(t, root, "net/udp", "   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n"+
+		"  100: 00000000:0044 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 3001 2 0000000000000000 0\n")
+	writeProcFile(t, root, "net/unix", "Num       RefCount Protocol Flags    Type St Inode Path\n"+
+		"0000000000000000: 00000002 00000000 00010000 0001 01 4001 /run/app.sock\n"+
+		"0000000000000000: 00000003 00000000 00000000 0001 03 4002\n")
+
+	src := system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()})
+	list, err := readSockets(src.Proc, "net")
+	if err != nil {
+		t.Fatalf("Reading sockets failed: %v", err)
+	}
+	sockets := socketsByInode(list)
+	if len(list) != 6 || len(sockets) != 5 {
+		t.Fatalf("Expected 6 sockets, 5 with an inode, got %d and %d", len(list), len(sockets))
+	}
+
+	if s := sockets[1068]; s.Protocol != "tcp" || s.State != "LISTEN" || s.Local() != "127.0.0.1:8080" {
+		t.Errorf("Unexpected listening socket %+v", s)
+	}
+	if s := sockets[2001]; s.State != "ESTABLISHED" || s.Remote() != "127.0.0.1:54321" || s.TxQueue != 26 || s.RxQueue != 16 {
+		t.Errorf("Unexpected connection %+v", s)
+	}
+	if s := sockets[3001]; s.Protocol != "udp" || s.State != "" || s.LocalPort != 68 {
+		t.Errorf("Unexpected UDP socket %+v", s)
+	}
+	if s := sockets[4001]; s.State != "LISTEN" || s.Local() != "/run/app.sock" {
+		t.Errorf("Unexpected unix listener %+v", s)
+	}
+	if s := sockets[4002]; s.State != "CONNECTED" || s.Path != "" {
+		t.Errorf("Unexpected unix connection %+v", s)
+	}
+
+	if _, err := readSockets(src.Proc, "42/net"); err == nil {
+		t.Error("Expected an error without any socket table")
+	}
+}
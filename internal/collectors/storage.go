@@ -11,6 +11,7 @@ import (
 )
 
 type StorageCollector struct {
+	source        *system.Source
 	procReader    *system.ProcReader
 	sysReader     *system.SysReader
 	lastDiskStats map[string]models.DiskIOMetrics
@@ -18,17 +19,22 @@ type StorageCollector struct {
 }
 
 func NewStorageCollector() *StorageCollector {
+	return NewStorageCollectorWithSource(system.DefaultSource())
+}
+
+func NewStorageCollectorWithSource(src *system.Source) *StorageCollector {
 	return &StorageCollector{
-		procReader:    system.NewProcReader(),
-		sysReader:     system.NewSysReader(),
+		source:        src,
+		procReader:    src.Proc,
+		sysReader:     src.Sys,
 		lastDiskStats: make(map[string]models.DiskIOMetrics),
-		lastUpdate:    time.Now(),
+		lastUpdate:    src.Now(),
 	}
 }
 
 func (s *StorageCollector) Collect() (*models.StorageMetrics, error) {
 	metrics := &models.StorageMetrics{
-		Timestamp: time.Now(),
+		Timestamp: s.source.Now(),
 	}
 
 	if err := s.collectFilesystems(metrics); err != nil {
@@ -47,7 +53,7 @@ func (s *StorageCollector) Collect() (*models.StorageMetrics, error) {
 }
 
 func (s *StorageCollector) collectFilesystems(metrics *models.StorageMetrics) error {
-	mounts, err := s.procReader.ReadMounts()
+	mounts, err := s.readMounts()
 	if err != nil {
 		return err
 	}
@@ -73,7 +79,7 @@ func (s *StorageCollector) collectFilesystems(metrics *models.StorageMetrics) er
 			continue
 		}
 
-		diskUsage, err := system.GetDiskUsage(mountpoint)
+		diskUsage, err := s.source.DiskUsage(mountpoint)
 		if err != nil {
 			continue
 		}
@@ -96,6 +102,17 @@ func (s *StorageCollector) collectFilesystems(metrics *models.StorageMetrics) er
 	return nil
 }
 
+func (s *StorageCollector) readMounts() ([]string, error) {
+	// With a foreign root filesystem, "mounts" resolves to ltop's own mount
+	// namespace; init's view is the one that matches the host root.
+	if s.source.IsHostRooted() {
+		if mounts, err := s.procReader.ReadProcessMounts("1"); err == nil {
+			return mounts, nil
+		}
+	}
+	return s.procReader.ReadMounts()
+}
+
 func (s *StorageCollector) shouldIncludeFilesystem(device, mountpoint, fstype string) bool {
 	if strings.HasPrefix(device, "/dev/loop") {
 		return false
@@ -125,7 +142,7 @@ func (s *StorageCollector) collectDiskStats(metrics *models.StorageMetrics) erro
 	}
 
 	ioStats := make([]models.DiskIOMetrics, 0)
-	currentTime := time.Now()
+	currentTime := s.source.Now()
 	timeDelta := currentTime.Sub(s.lastUpdate).Seconds()
 
 	for _, line := range diskStats {
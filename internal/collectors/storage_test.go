@@ -57,3 +57,51 @@ func TestStorageCalculation(t *testing.T) {
 		}
 	}
 }
+
+func TestStorageCollectorReplay(t *testing.T) {
+	replay := openTestFixture(t)
+	collector := NewStorageCollectorWithSource(replay.Source())
+
+	replay.Next()
+	if _, err := collector.Collect(); err != nil {
+		t.Fatalf("Storage collection failed: %v", err)
+	}
+
+	replay.Next()
+	metrics, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Storage collection failed: %v", err)
+	}
+
+	if len(metrics.Filesystems) != 1 || metrics.Filesystems[0].Mountpoint != "/" {
+		t.Fatalf("Expected only the root filesystem, got %+v", metrics.Filesystems)
+	}
+	if abs(metrics.Filesystems[0].UsedPercent-80.0) > 0.01 {
+		t.Errorf("Expected root filesystem 80%% used, got %f", metrics.Filesystems[0].UsedPercent)
+	}
+
+	if len(metrics.IOStats) != 1 {
+		t.Fatalf("Expected IO stats for sda only, got %d devices", len(metrics.IOStats))
+	}
+
+	// Frame deltas over 2s: 4096 sectors read, 8192 written, 200 read IOs,
+	// 100 write IOs and 1000ms of IO time.
+	sda := metrics.IOStats[0]
+	testCases := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"ReadBytesPerSec", sda.ReadBytesPerSec, 1048576},
+		{"WriteBytesPerSec", sda.WriteBytesPerSec, 2097152},
+		{"IOPSRead", sda.IOPSRead, 100},
+		{"IOPSWrite", sda.IOPSWrite, 50},
+		{"IOWaitPercent", sda.IOWaitPercent, 50},
+	}
+
+	for _, tc := range testCases {
+		if abs(tc.got-tc.expected) > 0.01 {
+			t.Errorf("%s: expected %f, got %f", tc.name, tc.expected, tc.got)
+		}
+	}
+}
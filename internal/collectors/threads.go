@@ -0,0 +1,114 @@
+package collectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+// ThreadCollector lists the threads of one process. It is created when a
+// process is drilled into rather than registered with the other collectors.
+type ThreadCollector struct {
+	source     *system.Source
+	procReader *system.ProcReader
+	pid        string
+	lastTicks  map[int]uint64
+	lastUpdate time.Time
+}
+
+func NewThreadCollector(src *system.Source, pid int) *ThreadCollector {
+	return &ThreadCollector{
+		source:     src,
+		procReader: src.Proc,
+		pid:        strconv.Itoa(pid),
+		lastTicks:  make(map[int]uint64),
+	}
+}
+
+// Collect reads every thread of the process, sorted by TID. CPUPercent is
+// the share of one CPU since the previous call, so the first call reports
+// zero.
+func (t *ThreadCollector) Collect() ([]models.Thread, error) {
+	tids, err := t.procReader.ReadProcessThreads(t.pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read threads of PID %s: %w", t.pid, err)
+	}
+
+	now := t.source.Now()
+	elapsed := now.Sub(t.lastUpdate).Seconds()
+	if t.lastUpdate.IsZero() {
+		elapsed = 0
+	}
+
+	threads := make([]models.Thread, 0, len(tids))
+	ticks := make(map[int]uint64, len(tids))
+	for _, tid := range tids {
+		thread, threadTicks, err := t.collectThread(tid)
+		if err != nil {
+			// The thread exited while the task directory was being read.
+			continue
+		}
+
+		if last, ok := t.lastTicks[thread.TID]; ok && elapsed > 0 && threadTicks >= last {
+			thread.CPUPercent = float64(threadTicks-last) / (elapsed * system.UserHZ) * 100
+		}
+		ticks[thread.TID] = threadTicks
+		threads = append(threads, thread)
+	}
+
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].TID < threads[j].TID
+	})
+
+	t.lastTicks = ticks
+	t.lastUpdate = now
+	return threads, nil
+}
+
+func (t *ThreadCollector) collectThread(tid string) (models.Thread, uint64, error) {
+	statLine, err := t.procReader.ReadThreadStat(t.pid, tid)
+	if err != nil {
+		return models.Thread{}, 0, err
+	}
+
+	// Thread names may contain spaces and parentheses, so the fields are
+	// counted from the last closing parenthesis.
+	open, end := strings.IndexByte(statLine, '('), strings.LastIndexByte(statLine, ')')
+	if open < 0 || end < open {
+		return models.Thread{}, 0, fmt.Errorf("invalid stat format for TID %s", tid)
+	}
+	fields := strings.Fields(statLine[end+1:])
+	if len(fields) < 37 {
+		return models.Thread{}, 0, fmt.Errorf("invalid stat format for TID %s", tid)
+	}
+
+	thread := models.Thread{
+		Name:  statLine[open+1 : end],
+		State: fields[0],
+	}
+	thread.TID, err = strconv.Atoi(tid)
+	if err != nil {
+		return models.Thread{}, 0, err
+	}
+
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	ticks := utime + stime
+	thread.CPUTime = time.Duration(ticks) * time.Second / system.UserHZ
+
+	if processor, err := strconv.Atoi(fields[36]); err == nil {
+		thread.Processor = processor
+	}
+
+	if status, err := t.procReader.ReadThreadStatus(t.pid, tid); err == nil {
+		thread.VoluntaryCtxSwitches, _ = strconv.ParseUint(status["voluntary_ctxt_switches"], 10, 64)
+		thread.InvoluntaryCtxSwitches, _ = strconv.ParseUint(status["nonvoluntary_ctxt_switches"], 10, 64)
+	}
+
+	return thread, ticks, nil
+}
@@ -0,0 +1,64 @@
+package collectors
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+// fakeThreadStat returns a /proc/[pid]/task/[tid]/stat line with the given
+// CPU ticks and last processor.
+func fakeThreadStat(tid, name string, utime, stime, processor string) string {
+	fields := strings.Fields(fakeProcessStat(tid, "x", 1))
+	fields[1] = "(" + name + ")"
+	fields[13], fields[14], fields[38] = utime, stime, processor
+	return strings.Join(fields, " ") + "\n"
+}
+
+func TestThreadCollector(t *testing.T) {
+	root := t.TempDir()
+	writeProcFile(t, root, "42/task/42/stat", fakeThreadStat("42", "java", "100", "50", "3"))
+	writeProcFile(t, root, "42/task/42/status", "Name:\tjava\nvoluntary_ctxt_switches:\t12\nnonvoluntary_ctxt_switches:\t3\n")
+	writeProcFile(t, root, "42/task/57/stat", fakeThreadStat("57", "C2 Compiler (1)", "10", "0", "1"))
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := system.NewSource(system.Paths{ProcRoot: root, SysRoot: t.TempDir()})
+	src.SetClock(func() time.Time { return now })
+	collector := NewThreadCollector(src, 42)
+
+	threads, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Thread collection failed: %v", err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("Expected 2 threads, got %d", len(threads))
+	}
+	main := threads[0]
+	if main.TID != 42 || main.Name != "java" || main.Processor != 3 || main.CPUTime != 1500*time.Millisecond {
+		t.Errorf("Unexpected main thread %+v", main)
+	}
+	if main.VoluntaryCtxSwitches != 12 || main.InvoluntaryCtxSwitches != 3 || main.CPUPercent != 0 {
+		t.Errorf("Unexpected main thread counters %+v", main)
+	}
+	if threads[1].Name != "C2 Compiler (1)" || threads[1].State != "S" {
+		t.Errorf("Unexpected compiler thread %+v", threads[1])
+	}
+
+	// 150 ticks in two seconds is three quarters of one CPU.
+	now = now.Add(2 * time.Second)
+	writeProcFile(t, root, "42/task/57/stat", fakeThreadStat("57", "C2 Compiler (1)", "110", "50", "1"))
+
+	threads, err = collector.Collect()
+	if err != nil {
+		t.Fatalf("Thread collection failed: %v", err)
+	}
+	if threads[0].CPUPercent != 0 || threads[1].CPUPercent != 75 {
+		t.Errorf("Unexpected CPU usage %.1f%%, %.1f%%", threads[0].CPUPercent, threads[1].CPUPercent)
+	}
+
+	if _, err := NewThreadCollector(src, 7).Collect(); err == nil {
+		t.Error("Expected an error for a missing process")
+	}
+}
@@ -0,0 +1,372 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+// ContentType is the Prometheus text exposition format, version 0.0.4.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const namespace = "ltop_"
+
+// sectorSize is the unit of the sector counts in /proc/diskstats.
+const sectorSize = 512
+
+type metricType string
+
+const (
+	counter metricType = "counter"
+	gauge   metricType = "gauge"
+)
+
+type sample struct {
+	labels []string // name, value pairs
+	value  float64
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     metricType
+	samples []sample
+}
+
+// metrics collects families in the order they are first added; samples of a
+// family are kept together as the format requires.
+type metrics struct {
+	families []*family
+	byName   map[string]*family
+}
+
+func (m *metrics) add(name string, typ metricType, help string, value float64, labels ...string) {
+	if m.byName == nil {
+		m.byName = make(map[string]*family)
+	}
+	f, ok := m.byName[name]
+	if !ok {
+		f = &family{name: namespace + name, help: help, typ: typ}
+		m.byName[name] = f
+		m.families = append(m.families, f)
+	}
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func (m *metrics) gauge(name, help string, value float64, labels ...string) {
+	m.add(name, gauge, help, value, labels...)
+}
+
+func (m *metrics) counter(name, help string, value float64, labels ...string) {
+	m.add(name, counter, help, value, labels...)
+}
+
+func (m *metrics) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range m.families {
+		bw.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+		bw.WriteString("# TYPE " + f.name + " " + string(f.typ) + "\n")
+		for _, s := range f.samples {
+			bw.WriteString(f.name)
+			if len(s.labels) > 0 {
+				bw.WriteByte('{')
+				for i := 0; i+1 < len(s.labels); i += 2 {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(s.labels[i] + `="` + escapeLabel(s.labels[i+1]) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatValue(s.value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Options select the optional series.
+type Options struct {
+	// TopProcesses exports per-process series for this many processes
+	// with the highest CPU usage. Zero exports none.
+	TopProcesses int
+}
+
+// Write writes snapshot in the Prometheus text format.
+func Write(w io.Writer, snapshot *models.MetricsSnapshot, opts Options) error {
+	m := &metrics{}
+	addOverview(m, snapshot)
+	addCPU(m, &snapshot.CPU)
+	addMemory(m, &snapshot.Memory)
+	addStorage(m, &snapshot.Storage)
+	addNetwork(m, &snapshot.Network)
+	addProcesses(m, &snapshot.Processes, opts.TopProcesses)
+	addLogs(m, &snapshot.Logs)
+	addCollectors(m, snapshot.Collectors)
+	return m.write(w)
+}
+
+func addOverview(m *metrics, snapshot *models.MetricsSnapshot) {
+	o := &snapshot.Overview
+	m.gauge("info", "Host information, always 1.", 1,
+		"hostname", o.Hostname, "os", o.OS, "platform", o.Platform, "kernel", o.Kernel,
+		"architecture", o.Architecture, "cpu_model", o.CPUModel)
+	m.gauge("snapshot_timestamp_seconds", "Time the snapshot was taken.", unixSeconds(snapshot.Timestamp.UnixNano()))
+	m.gauge("uptime_seconds", "System uptime.", o.Uptime.Seconds())
+	if !o.BootTime.IsZero() {
+		m.gauge("boot_time_seconds", "System boot time.", unixSeconds(o.BootTime.UnixNano()))
+	}
+	if o.CPUCores > 0 {
+		m.gauge("cpu_cores", "Number of CPU cores.", float64(o.CPUCores))
+	}
+	if o.TotalMemory > 0 {
+		m.gauge("total_memory_bytes", "Installed memory.", float64(o.TotalMemory))
+	}
+}
+
+func unixSeconds(nanos int64) float64 {
+	return float64(nanos) / 1e9
+}
+
+func addCPUTimes(m *metrics, cpu string, times models.CPUTimes) {
+	const help = "Seconds the CPU spent in each mode."
+	modes := []struct {
+		mode  string
+		ticks uint64
+	}{
+		{"user", times.User},
+		{"nice", times.Nice},
+		{"system", times.System},
+		{"idle", times.Idle},
+		{"iowait", times.IOWait},
+		{"irq", times.IRQ},
+		{"softirq", times.SoftIRQ},
+		{"steal", times.Steal},
+		{"guest", times.Guest},
+		{"guest_nice", times.GuestNice},
+	}
+	for _, mode := range modes {
+		m.counter("cpu_seconds_total", help, float64(mode.ticks)/system.UserHZ, "cpu", cpu, "mode", mode.mode)
+	}
+}
+
+func addCPU(m *metrics, cpu *models.CPUMetrics) {
+	const usageHelp = "CPU usage in percent over the last collection interval."
+	m.gauge("cpu_usage_percent", usageHelp, cpu.Usage, "cpu", "total")
+	for _, core := range cpu.Cores {
+		m.gauge("cpu_usage_percent", usageHelp, core.Usage, "cpu", strconv.Itoa(core.ID))
+	}
+
+	addCPUTimes(m, "total", cpu.Times)
+	for _, core := range cpu.Cores {
+		addCPUTimes(m, strconv.Itoa(core.ID), core.Times)
+	}
+
+	m.gauge("load1", "1 minute load average.", cpu.LoadAverage[0])
+	m.gauge("load5", "5 minute load average.", cpu.LoadAverage[1])
+	m.gauge("load15", "15 minute load average.", cpu.LoadAverage[2])
+
+	for _, key := range sortedKeys(cpu.Frequency) {
+		m.gauge("cpu_frequency_hertz", "Current CPU frequency.", float64(cpu.Frequency[key]),
+			"cpu", strings.TrimPrefix(key, "cpu"))
+	}
+	if cpu.Temperature != 0 {
+		m.gauge("cpu_temperature_celsius", "CPU temperature.", cpu.Temperature)
+	}
+}
+
+func addMemory(m *metrics, mem *models.MemoryMetrics) {
+	m.gauge("memory_total_bytes", "Total usable memory.", float64(mem.Total))
+	m.gauge("memory_free_bytes", "Unused memory.", float64(mem.Free))
+	m.gauge("memory_available_bytes", "Memory available for new allocations.", float64(mem.Available))
+	m.gauge("memory_used_bytes", "Memory in use.", float64(mem.Used))
+	m.gauge("memory_used_percent", "Memory in use, in percent of the total.", mem.UsedPercent)
+	m.gauge("memory_cached_bytes", "Page cache.", float64(mem.Cached))
+	m.gauge("memory_buffers_bytes", "Block device buffers.", float64(mem.Buffers))
+	m.gauge("memory_shared_bytes", "Shared memory.", float64(mem.Shared))
+	m.gauge("swap_total_bytes", "Total swap space.", float64(mem.Swap.Total))
+	m.gauge("swap_free_bytes", "Unused swap space.", float64(mem.Swap.Free))
+	m.gauge("swap_used_bytes", "Swap space in use.", float64(mem.Swap.Used))
+	m.gauge("swap_used_percent", "Swap space in use, in percent of the total.", mem.Swap.UsedPercent)
+
+	for _, key := range sortedKeys(mem.Details) {
+		m.gauge("memory_detail_bytes", "Fields of /proc/meminfo.", float64(mem.Details[key]), "field", key)
+	}
+}
+
+func addStorage(m *metrics, storage *models.StorageMetrics) {
+	for _, fs := range storage.Filesystems {
+		labels := []string{"device", fs.Device, "mountpoint", fs.Mountpoint, "fstype", fs.FSType}
+		m.gauge("filesystem_size_bytes", "Filesystem size.", float64(fs.Total), labels...)
+		m.gauge("filesystem_free_bytes", "Free filesystem space.", float64(fs.Free), labels...)
+		m.gauge("filesystem_used_bytes", "Used filesystem space.", float64(fs.Used), labels...)
+		m.gauge("filesystem_used_percent", "Used filesystem space, in percent of the size.", fs.UsedPercent, labels...)
+		m.gauge("filesystem_inodes", "Total inodes.", float64(fs.InodesTotal), labels...)
+		m.gauge("filesystem_inodes_free", "Free inodes.", float64(fs.InodesFree), labels...)
+		m.gauge("filesystem_inodes_used", "Used inodes.", float64(fs.InodesUsed), labels...)
+	}
+
+	for _, disk := range storage.Disks {
+		m.gauge("disk_info", "Disk information, always 1.", 1,
+			"device", disk.Device, "model", disk.Model,
+			"read_only", strconv.FormatBool(disk.ReadOnly), "removable", strconv.FormatBool(disk.Removable))
+		m.gauge("disk_size_bytes", "Disk size.", float64(disk.Size), "device", disk.Device)
+	}
+
+	for _, stats := range storage.IOStats {
+		d := []string{"device", stats.Device}
+		m.counter("disk_reads_completed_total", "Reads completed.", float64(stats.ReadIOs), d...)
+		m.counter("disk_reads_merged_total", "Adjacent reads merged.", float64(stats.ReadMerged), d...)
+		m.counter("disk_read_bytes_total", "Bytes read.", float64(stats.ReadSectors*sectorSize), d...)
+		m.counter("disk_read_time_seconds_total", "Time spent reading.", float64(stats.ReadTicks)/1000, d...)
+		m.counter("disk_writes_completed_total", "Writes completed.", float64(stats.WriteIOs), d...)
+		m.counter("disk_writes_merged_total", "Adjacent writes merged.", float64(stats.WriteMerged), d...)
+		m.counter("disk_written_bytes_total", "Bytes written.", float64(stats.WriteSectors*sectorSize), d...)
+		m.counter("disk_write_time_seconds_total", "Time spent writing.", float64(stats.WriteTicks)/1000, d...)
+		m.gauge("disk_io_now", "I/Os in progress.", float64(stats.InFlight), d...)
+		m.counter("disk_io_time_seconds_total", "Time spent doing I/Os.", float64(stats.IOTicks)/1000, d...)
+		m.counter("disk_io_time_weighted_seconds_total", "Weighted time spent doing I/Os.", float64(stats.TimeInQueue)/1000, d...)
+		m.gauge("disk_read_bytes_per_second", "Read throughput over the last collection interval.", stats.ReadBytesPerSec, d...)
+		m.gauge("disk_write_bytes_per_second", "Write throughput over the last collection interval.", stats.WriteBytesPerSec, d...)
+		m.gauge("disk_read_iops", "Reads per second over the last collection interval.", stats.IOPSRead, d...)
+		m.gauge("disk_write_iops", "Writes per second over the last collection interval.", stats.IOPSWrite, d...)
+		m.gauge("disk_iowait_percent", "Time the disk was busy, in percent of the last collection interval.", stats.IOWaitPercent, d...)
+	}
+}
+
+func addNetwork(m *metrics, network *models.NetworkMetrics) {
+	for _, iface := range network.Interfaces {
+		i := []string{"interface", iface.Name}
+		m.gauge("network_info", "Interface information, always 1.", 1,
+			"interface", iface.Name, "state", iface.State, "duplex", iface.Duplex)
+		m.gauge("network_up", "Whether the interface is operationally up.", boolValue(iface.State == "up"), i...)
+		m.counter("network_receive_bytes_total", "Bytes received.", float64(iface.BytesRecv), i...)
+		m.counter("network_transmit_bytes_total", "Bytes sent.", float64(iface.BytesSent), i...)
+		m.counter("network_receive_packets_total", "Packets received.", float64(iface.PacketsRecv), i...)
+		m.counter("network_transmit_packets_total", "Packets sent.", float64(iface.PacketsSent), i...)
+		m.counter("network_receive_errs_total", "Receive errors.", float64(iface.ErrorsRecv), i...)
+		m.counter("network_transmit_errs_total", "Transmit errors.", float64(iface.ErrorsSent), i...)
+		m.counter("network_receive_drop_total", "Received packets dropped.", float64(iface.DroppedRecv), i...)
+		m.counter("network_transmit_drop_total", "Sent packets dropped.", float64(iface.DroppedSent), i...)
+		m.gauge("network_speed_bytes", "Link speed.", float64(iface.Speed)/8, i...)
+		m.gauge("network_mtu_bytes", "Interface MTU.", float64(iface.MTU), i...)
+		m.gauge("network_receive_bytes_per_second", "Receive throughput over the last collection interval.", iface.RecvBytesPerSec, i...)
+		m.gauge("network_transmit_bytes_per_second", "Transmit throughput over the last collection interval.", iface.SentBytesPerSec, i...)
+	}
+}
+
+func addProcesses(m *metrics, processes *models.ProcessMetrics, top int) {
+	m.gauge("processes", "Number of processes.", float64(processes.Count))
+	const stateHelp = "Number of processes in each state."
+	m.gauge("processes_state", stateHelp, float64(processes.Running), "state", "running")
+	m.gauge("processes_state", stateHelp, float64(processes.Sleeping), "state", "sleeping")
+	m.gauge("processes_state", stateHelp, float64(processes.Stopped), "state", "stopped")
+	m.gauge("processes_state", stateHelp, float64(processes.Zombie), "state", "zombie")
+
+	if top <= 0 {
+		return
+	}
+	sorted := make([]models.Process, len(processes.Processes))
+	copy(sorted, processes.Processes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CPUPercent > sorted[j].CPUPercent
+	})
+	if len(sorted) > top {
+		sorted = sorted[:top]
+	}
+
+	for _, p := range sorted {
+		l := []string{"pid", strconv.Itoa(p.PID), "name", p.Name, "user", p.User}
+		m.gauge("process_cpu_percent", "Process CPU usage over the last collection interval.", p.CPUPercent, l...)
+		m.counter("process_cpu_seconds_total", "Process CPU time.", p.CPUTime.Seconds(), l...)
+		m.gauge("process_resident_memory_bytes", "Process resident set size.", float64(p.MemoryRSS), l...)
+		m.gauge("process_virtual_memory_bytes", "Process virtual memory size.", float64(p.MemoryVMS), l...)
+		m.gauge("process_memory_percent", "Process resident memory, in percent of total memory.", p.MemoryPercent, l...)
+		m.gauge("process_threads", "Process threads.", float64(p.NumThreads), l...)
+		m.gauge("process_open_fds", "Process open file descriptors.", float64(p.NumFDs), l...)
+		m.gauge("process_nice", "Process nice value.", float64(p.Nice), l...)
+		m.counter("process_read_bytes_total", "Bytes read by the process.", float64(p.IOStats.ReadBytes), l...)
+		m.counter("process_written_bytes_total", "Bytes written by the process.", float64(p.IOStats.WriteBytes), l...)
+		m.gauge("process_read_bytes_per_second", "Process storage read rate over the last collection interval.", p.IOStats.ReadBytesPerSec, l...)
+		m.gauge("process_written_bytes_per_second", "Process storage write rate over the last collection interval.", p.IOStats.WriteBytesPerSec, l...)
+		if !p.CreateTime.IsZero() {
+			m.gauge("process_start_time_seconds", "Process start time.", unixSeconds(p.CreateTime.UnixNano()), l...)
+		}
+	}
+}
+
+func addLogs(m *metrics, logs *models.LogMetrics) {
+	const help = "Log entries of each level among the recent entries."
+	m.gauge("log_entries", help, float64(logs.ErrorCount), "level", "error")
+	m.gauge("log_entries", help, float64(logs.WarnCount), "level", "warn")
+	m.gauge("log_entries", help, float64(logs.InfoCount), "level", "info")
+}
+
+func addCollectors(m *metrics, statuses map[string]models.CollectorStatus) {
+	for _, name := range sortedKeys(statuses) {
+		status := statuses[name]
+		c := []string{"collector", name}
+		m.gauge("collector_duration_seconds", "Duration of the collector's last run.", status.Duration.Seconds(), c...)
+		m.gauge("collector_stale", "Whether the collector's result is from an earlier run.", boolValue(status.Stale), c...)
+		m.gauge("collector_error", "Whether the collector's last run failed.", boolValue(status.Error != ""), c...)
+		if !status.LastUpdate.IsZero() {
+			m.gauge("collector_last_update_timestamp_seconds", "Time of the collector's last successful run.",
+				unixSeconds(status.LastUpdate.UnixNano()), c...)
+		}
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Handler serves the snapshot returned by latest on every request.
+func Handler(latest func() *models.MetricsSnapshot, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot := latest()
+		if snapshot == nil {
+			http.Error(w, "no metrics collected yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		_ = Write(w, snapshot, opts)
+	})
+}
@@ -0,0 +1,135 @@
+package exporter
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func testSnapshot() *models.MetricsSnapshot {
+	return &models.MetricsSnapshot{
+		Timestamp: time.Unix(1700000000, 0),
+		Overview:  models.SystemOverview{Hostname: `web"1`},
+		CPU: models.CPUMetrics{
+			Usage:       12.5,
+			LoadAverage: [3]float64{1, 0.5, 0.25},
+			Times:       models.CPUTimes{User: 250, Idle: 1000},
+			Cores: []models.CPUCoreMetrics{
+				{ID: 0, Usage: 25, Times: models.CPUTimes{User: 150}},
+				{ID: 1, Usage: 0, Times: models.CPUTimes{User: 100}},
+			},
+		},
+		Memory: models.MemoryMetrics{
+			Total:   8 << 30,
+			Details: map[string]uint64{"MemTotal": 8 << 30, "Dirty": 4096},
+		},
+		Storage: models.StorageMetrics{
+			Filesystems: []models.FilesystemMetrics{{Device: "/dev/sda1", Mountpoint: "/", FSType: "ext4", Total: 1000}},
+			IOStats:     []models.DiskIOMetrics{{Device: "sda", ReadSectors: 2, WriteTicks: 1500}},
+		},
+		Network: models.NetworkMetrics{
+			Interfaces: []models.NetworkInterface{{Name: "eth0", BytesRecv: 42, State: "up", Speed: 1000000000}},
+		},
+		Processes: models.ProcessMetrics{
+			Count: 3,
+			Processes: []models.Process{
+				{PID: 1, Name: "init", CPUPercent: 0.1},
+				{PID: 20, Name: "busy", CPUPercent: 90},
+				{PID: 30, Name: "idle", CPUPercent: 0},
+			},
+		},
+		Collectors: map[string]models.CollectorStatus{
+			"cpu": {Duration: 2 * time.Millisecond, Stale: true},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testSnapshot(), Options{TopProcesses: 1}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+
+	expected := []string{
+		"# TYPE ltop_cpu_seconds_total counter",
+		`ltop_cpu_seconds_total{cpu="total",mode="user"} 2.5`,
+		`ltop_cpu_seconds_total{cpu="0",mode="user"} 1.5`,
+		`ltop_cpu_usage_percent{cpu="1"} 0`,
+		"# TYPE ltop_load1 gauge",
+		"ltop_load5 0.5",
+		`ltop_memory_detail_bytes{field="Dirty"} 4096`,
+		"ltop_memory_total_bytes 8.589934592e+09",
+		`ltop_filesystem_size_bytes{device="/dev/sda1",mountpoint="/",fstype="ext4"} 1000`,
+		`ltop_disk_read_bytes_total{device="sda"} 1024`,
+		`ltop_disk_write_time_seconds_total{device="sda"} 1.5`,
+		`ltop_network_receive_bytes_total{interface="eth0"} 42`,
+		`ltop_network_speed_bytes{interface="eth0"} 1.25e+08`,
+		`ltop_network_up{interface="eth0"} 1`,
+		"ltop_processes 3",
+		`ltop_process_cpu_percent{pid="20",name="busy",user=""} 90`,
+		`ltop_collector_stale{collector="cpu"} 1`,
+		`hostname="web\"1"`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected output to contain %q", line)
+		}
+	}
+
+	if strings.Contains(out, `pid="1"`) {
+		t.Error("Expected only the top process to be exported")
+	}
+
+	// Every family is declared exactly once, before its samples.
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			name := strings.Fields(line)[2]
+			if seen[name] {
+				t.Errorf("Family %s declared twice", name)
+			}
+			seen[name] = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.FieldsFunc(line, func(r rune) bool { return r == '{' || r == ' ' })[0]
+		if !seen[name] {
+			t.Errorf("Sample %q precedes its TYPE line", line)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var snapshot *models.MetricsSnapshot
+	handler := Handler(func() *models.MetricsSnapshot { return snapshot }, Options{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first snapshot, got %d", rec.Code)
+	}
+
+	snapshot = testSnapshot()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "ltop_uptime_seconds") {
+		t.Error("Expected metrics in the response body")
+	}
+	if strings.Contains(rec.Body.String(), "ltop_process_cpu_percent") {
+		t.Error("Expected no per-process series by default")
+	}
+}
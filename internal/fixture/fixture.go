@@ -0,0 +1,305 @@
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+// A fixture is a directory of numbered frames, one per collection pass:
+//
+//	0000/frame.json   timestamp and statfs results of the pass
+//	0000/proc/...     every /proc file the collectors read
+//	0000/sys/...      every /sys file the collectors read
+//
+// Replaying the frames in order feeds collectors exactly what they saw
+// while capturing, so rate calculations become deterministic.
+const frameFile = "frame.json"
+
+type Frame struct {
+	Timestamp time.Time                   `json:"timestamp"`
+	DiskUsage map[string]system.DiskUsage `json:"disk_usage,omitempty"`
+}
+
+func frameDir(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("%04d", index))
+}
+
+// Writer is a system.Recorder that stores what a Source serves into frames.
+type Writer struct {
+	dir     string
+	mu      sync.Mutex
+	frames  int
+	open    bool
+	current Frame
+	err     error
+}
+
+func NewWriter(dir string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Writer{dir: dir}, nil
+}
+
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+func (w *Writer) Frames() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.frames
+}
+
+// BeginFrame starts recording a new collection pass taken at timestamp.
+func (w *Writer) BeginFrame(timestamp time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.open = true
+	w.current = Frame{
+		Timestamp: timestamp,
+		DiskUsage: make(map[string]system.DiskUsage),
+	}
+}
+
+// EndFrame finishes the current pass and reports the first error hit while
+// recording it.
+func (w *Writer) EndFrame() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.open {
+		return fmt.Errorf("no frame in progress")
+	}
+	w.open = false
+
+	dir := frameDir(w.dir, w.frames)
+	w.frames++
+
+	if err := w.err; err != nil {
+		w.err = nil
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(w.current, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, frameFile), data, 0644)
+}
+
+func (w *Writer) RecordFile(kind system.Kind, path string, data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.open || w.err != nil {
+		return
+	}
+
+	target := filepath.Join(frameDir(w.dir, w.frames), string(kind), path)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		w.err = err
+		return
+	}
+	// A directory listing may already have created a placeholder here.
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		_ = os.Remove(target)
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		w.err = err
+	}
+}
+
+func (w *Writer) RecordDir(kind system.Kind, path string, names []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.open || w.err != nil {
+		return
+	}
+
+	base := filepath.Join(frameDir(w.dir, w.frames), string(kind), path)
+	for _, name := range names {
+		target := filepath.Join(base, name)
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(target, 0755); err != nil {
+			w.err = err
+			return
+		}
+	}
+}
+
+func (w *Writer) RecordDiskUsage(mountpoint string, usage *system.DiskUsage) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.open || usage == nil {
+		return
+	}
+	w.current.DiskUsage[mountpoint] = *usage
+}
+
+// Replay serves the frames of a fixture through a Source, one frame per
+// call to Next.
+type Replay struct {
+	dir     string
+	tempDir string
+	frames  []Frame
+	mu      sync.RWMutex
+	index   int
+	source  *system.Source
+}
+
+// Open loads a fixture directory or a tarball written by Archive.
+func Open(path string) (*Replay, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{dir: path, index: -1}
+	if !info.IsDir() {
+		tempDir, err := os.MkdirTemp("", "ltop-fixture-")
+		if err != nil {
+			return nil, err
+		}
+		r.dir = tempDir
+		r.tempDir = tempDir
+		if err := Extract(path, tempDir); err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("failed to extract fixture: %w", err)
+		}
+	}
+
+	if err := r.loadFrames(); err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+
+	r.source = system.NewSource(r.framePaths(0))
+	r.source.SetClock(r.now)
+	r.source.SetDiskUsageFunc(r.diskUsage)
+
+	return r, nil
+}
+
+func (r *Replay) loadFrames() error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+
+	var indexes []int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if index, err := strconv.Atoi(entry.Name()); err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	for i, index := range indexes {
+		if index != i {
+			return fmt.Errorf("fixture frame %04d is missing", i)
+		}
+
+		data, err := os.ReadFile(filepath.Join(frameDir(r.dir, i), frameFile))
+		if err != nil {
+			return err
+		}
+		var frame Frame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			return fmt.Errorf("invalid fixture frame %04d: %w", i, err)
+		}
+		r.frames = append(r.frames, frame)
+	}
+
+	if len(r.frames) == 0 {
+		return fmt.Errorf("no frames found in %s", r.dir)
+	}
+	return nil
+}
+
+func (r *Replay) framePaths(index int) system.Paths {
+	dir := frameDir(r.dir, index)
+	return system.Paths{
+		ProcRoot: filepath.Join(dir, string(system.KindProc)),
+		SysRoot:  filepath.Join(dir, string(system.KindSys)),
+	}
+}
+
+func (r *Replay) current() Frame {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.index < 0 {
+		return r.frames[0]
+	}
+	return r.frames[r.index]
+}
+
+func (r *Replay) now() time.Time {
+	return r.current().Timestamp
+}
+
+func (r *Replay) diskUsage(mountpoint string) (*system.DiskUsage, error) {
+	usage, ok := r.current().DiskUsage[mountpoint]
+	if !ok {
+		return nil, fmt.Errorf("no disk usage recorded for %s", mountpoint)
+	}
+	return &usage, nil
+}
+
+// Source returns the source collectors should be built on. It is positioned
+// before the first frame until Next is called.
+func (r *Replay) Source() *system.Source {
+	return r.source
+}
+
+// Next moves to the following frame. It returns false once every frame has
+// been served, leaving the source on the last one.
+func (r *Replay) Next() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.index+1 >= len(r.frames) {
+		return false
+	}
+	r.index++
+
+	paths := r.framePaths(r.index)
+	r.source.Proc.SetBasePath(paths.ProcRoot)
+	r.source.Sys.SetBasePath(paths.SysRoot)
+	return true
+}
+
+func (r *Replay) Len() int {
+	return len(r.frames)
+}
+
+func (r *Replay) Index() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index
+}
+
+func (r *Replay) Close() error {
+	if r.tempDir == "" {
+		return nil
+	}
+	return os.RemoveAll(r.tempDir)
+}
@@ -0,0 +1,126 @@
+package fixture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+func TestCaptureAndReplay(t *testing.T) {
+	procRoot := t.TempDir()
+	writeFile(t, filepath.Join(procRoot, "loadavg"), "1.00 0.50 0.25 1/10 100\n")
+	writeFile(t, filepath.Join(procRoot, "42", "stat"), "42 (worker) S 1\n")
+
+	src := system.NewSource(system.Paths{ProcRoot: procRoot, SysRoot: t.TempDir()})
+	src.SetDiskUsageFunc(func(string) (*system.DiskUsage, error) {
+		return &system.DiskUsage{Total: 100, Free: 40, Used: 60}, nil
+	})
+
+	dir := t.TempDir()
+	writer, err := NewWriter(dir)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	src.SetRecorder(writer)
+
+	timestamps := []time.Time{
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC),
+	}
+	loads := []string{"1.00 0.50 0.25 1/10 100\n", "2.00 0.50 0.25 1/10 100\n"}
+
+	for i, ts := range timestamps {
+		writeFile(t, filepath.Join(procRoot, "loadavg"), loads[i])
+
+		writer.BeginFrame(ts)
+		if _, err := src.Proc.ReadProcesses(); err != nil {
+			t.Fatalf("ReadProcesses failed: %v", err)
+		}
+		if _, err := src.Proc.ReadLoadAvg(); err != nil {
+			t.Fatalf("ReadLoadAvg failed: %v", err)
+		}
+		if _, err := src.DiskUsage("/"); err != nil {
+			t.Fatalf("DiskUsage failed: %v", err)
+		}
+		if err := writer.EndFrame(); err != nil {
+			t.Fatalf("EndFrame failed: %v", err)
+		}
+	}
+
+	if writer.Frames() != 2 {
+		t.Fatalf("Expected 2 frames, got %d", writer.Frames())
+	}
+
+	archive := filepath.Join(t.TempDir(), "capture.tar.gz")
+	if err := Archive(dir, archive); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+
+	for _, path := range []string{dir, archive} {
+		replay, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open(%s) failed: %v", path, err)
+		}
+
+		if replay.Len() != 2 {
+			t.Errorf("Expected 2 frames, got %d", replay.Len())
+		}
+
+		for i := 0; replay.Next(); i++ {
+			load, err := replay.Source().Proc.ReadLoadAvg()
+			if err != nil {
+				t.Fatalf("Frame %d: ReadLoadAvg failed: %v", i, err)
+			}
+			if load+"\n" != loads[i] {
+				t.Errorf("Frame %d: expected load %q, got %q", i, loads[i], load)
+			}
+
+			if !replay.Source().Now().Equal(timestamps[i]) {
+				t.Errorf("Frame %d: expected clock %v, got %v", i, timestamps[i], replay.Source().Now())
+			}
+
+			pids, err := replay.Source().Proc.ReadProcesses()
+			if err != nil || len(pids) != 1 || pids[0] != "42" {
+				t.Errorf("Frame %d: expected pid 42, got %v (%v)", i, pids, err)
+			}
+
+			usage, err := replay.Source().DiskUsage("/")
+			if err != nil || usage.Used != 60 {
+				t.Errorf("Frame %d: unexpected disk usage %+v (%v)", i, usage, err)
+			}
+		}
+
+		if replay.Index() != 1 {
+			t.Errorf("Expected replay to stop on the last frame, got %d", replay.Index())
+		}
+
+		if err := replay.Close(); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	}
+}
+
+func TestOpenMissingFrames(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Open(dir); err == nil {
+		t.Error("Expected an error for a fixture without frames")
+	}
+
+	writeFile(t, filepath.Join(dir, "0001", frameFile), "{}")
+	if _, err := Open(dir); err == nil {
+		t.Error("Expected an error for a fixture missing frame 0000")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"net"
+	"strconv"
 	"time"
 )
 
@@ -11,13 +13,30 @@ type CPUMetrics struct {
 	Frequency   map[string]uint64 `json:"frequency"`
 	Temperature float64           `json:"temperature"`
 	Times       CPUTimes          `json:"times"`
+	Breakdown   CPUBreakdown      `json:"breakdown"`
 	Timestamp   time.Time         `json:"timestamp"`
 }
 
 type CPUCoreMetrics struct {
-	ID    int      `json:"id"`
-	Usage float64  `json:"usage"`
-	Times CPUTimes `json:"times"`
+	ID        int          `json:"id"`
+	Usage     float64      `json:"usage"`
+	Times     CPUTimes     `json:"times"`
+	Breakdown CPUBreakdown `json:"breakdown"`
+}
+
+// CPUBreakdown is the share of CPU time spent in each state since the
+// previous collection, in percent, adding up to 100. The kernel counts guest
+// time in user and nice time as well; here it is only counted in Guest.
+type CPUBreakdown struct {
+	User    float64 `json:"user"`
+	Nice    float64 `json:"nice"`
+	System  float64 `json:"system"`
+	Idle    float64 `json:"idle"`
+	IOWait  float64 `json:"iowait"`
+	IRQ     float64 `json:"irq"`
+	SoftIRQ float64 `json:"softirq"`
+	Steal   float64 `json:"steal"`
+	Guest   float64 `json:"guest"`
 }
 
 type CPUTimes struct {
@@ -133,6 +152,9 @@ type ProcessMetrics struct {
 	Stopped   int       `json:"stopped"`
 	Zombie    int       `json:"zombie"`
 	Timestamp time.Time `json:"timestamp"`
+	// Smaps reports whether the smaps_rollup fields of every process were
+	// collected.
+	Smaps bool `json:"smaps"`
 }
 
 type Process struct {
@@ -155,13 +177,126 @@ type Process struct {
 	Group         string         `json:"group"`
 	IOStats       ProcessIOStats `json:"io_stats"`
 	Children      []int          `json:"children"`
+	// The smaps_rollup breakdown, only collected when enabled. MemoryUSS
+	// is private memory; MemoryPSS adds a proportional share of the
+	// shared memory.
+	MemoryPSS    uint64 `json:"memory_pss,omitempty"`
+	MemoryUSS    uint64 `json:"memory_uss,omitempty"`
+	MemoryShared uint64 `json:"memory_shared,omitempty"`
+	MemorySwap   uint64 `json:"memory_swap,omitempty"`
 }
 
+// ProcessIOStats are the counters of /proc/[pid]/io. ReadBytes and
+// WriteBytes count storage I/O only; the rates are over the last collection
+// interval.
 type ProcessIOStats struct {
-	ReadBytes  uint64 `json:"read_bytes"`
-	WriteBytes uint64 `json:"write_bytes"`
-	ReadCount  uint64 `json:"read_count"`
-	WriteCount uint64 `json:"write_count"`
+	ReadBytes        uint64  `json:"read_bytes"`
+	WriteBytes       uint64  `json:"write_bytes"`
+	ReadCount        uint64  `json:"read_count"`
+	WriteCount       uint64  `json:"write_count"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+}
+
+// BytesPerSec is the combined read and write rate.
+func (s ProcessIOStats) BytesPerSec() float64 {
+	return s.ReadBytesPerSec + s.WriteBytesPerSec
+}
+
+// Thread is one task of a process, from /proc/[pid]/task/[tid]. Processor
+// is the CPU the thread last ran on.
+type Thread struct {
+	TID                    int           `json:"tid"`
+	Name                   string        `json:"name"`
+	State                  string        `json:"state"`
+	CPUPercent             float64       `json:"cpu_percent"`
+	CPUTime                time.Duration `json:"cpu_time"`
+	Processor              int           `json:"processor"`
+	VoluntaryCtxSwitches   uint64        `json:"voluntary_ctxt_switches"`
+	InvoluntaryCtxSwitches uint64        `json:"nonvoluntary_ctxt_switches"`
+}
+
+// ProcessDetail is everything /proc shows about one process. Lists keep the
+// order of the files they come from. Unavailable maps a section, such as
+// "environ", to why it could not be read, usually missing permission.
+type ProcessDetail struct {
+	PID         int               `json:"pid"`
+	Exe         string            `json:"exe"`
+	Cwd         string            `json:"cwd"`
+	Args        []string          `json:"args"`
+	Environ     []string          `json:"environ"`
+	Limits      []ProcessLimit    `json:"limits"`
+	Cgroups     []string          `json:"cgroups"`
+	Namespaces  []Field           `json:"namespaces"`
+	Status      []Field           `json:"status"`
+	WChan       string            `json:"wchan"`
+	SmapsRollup []Field           `json:"smaps_rollup"`
+	Unavailable map[string]string `json:"unavailable,omitempty"`
+}
+
+// Field is a named value as listed in a /proc file.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ProcessLimit struct {
+	Name  string `json:"name"`
+	Soft  string `json:"soft"`
+	Hard  string `json:"hard"`
+	Units string `json:"units"`
+}
+
+// ProcessFiles are the open file descriptors of a process. FDLimit is the
+// soft limit on open files, zero when unlimited or unknown.
+type ProcessFiles struct {
+	PID     int        `json:"pid"`
+	Files   []OpenFile `json:"files"`
+	FDLimit uint64     `json:"fd_limit"`
+}
+
+// OpenFile is one entry of /proc/[pid]/fd. Type is file, device, pipe,
+// socket or anon_inode; Socket is set for sockets found in the socket
+// tables of the process's network namespace.
+type OpenFile struct {
+	FD     int     `json:"fd"`
+	Type   string  `json:"type"`
+	Target string  `json:"target"`
+	Socket *Socket `json:"socket,omitempty"`
+}
+
+// Socket is one entry of /proc/net/{tcp,tcp6,udp,udp6,unix}. Unix sockets
+// have a Path, if bound, instead of addresses. PID and Process name the
+// owner when it is known.
+type Socket struct {
+	Protocol   string `json:"protocol"`
+	State      string `json:"state"`
+	LocalAddr  string `json:"local_addr,omitempty"`
+	LocalPort  int    `json:"local_port,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	RemotePort int    `json:"remote_port,omitempty"`
+	Path       string `json:"path,omitempty"`
+	TxQueue    uint64 `json:"tx_queue"`
+	RxQueue    uint64 `json:"rx_queue"`
+	Inode      uint64 `json:"inode"`
+	PID        int    `json:"pid,omitempty"`
+	Process    string `json:"process,omitempty"`
+}
+
+// Local and Remote format the endpoints as host:port, or the path of a unix
+// socket.
+func (s Socket) Local() string {
+	if s.Protocol == "unix" {
+		return s.Path
+	}
+	return net.JoinHostPort(s.LocalAddr, strconv.Itoa(s.LocalPort))
+}
+
+func (s Socket) Remote() string {
+	if s.Protocol == "unix" {
+		return ""
+	}
+	return net.JoinHostPort(s.RemoteAddr, strconv.Itoa(s.RemotePort))
 }
 
 type LogEntry struct {
@@ -205,4 +340,49 @@ type MetricsSnapshot struct {
 	Processes ProcessMetrics `json:"processes"`
 	Logs      LogMetrics     `json:"logs"`
 	Timestamp time.Time      `json:"timestamp"`
+	// Extensions holds results of collectors registered outside ltop,
+	// keyed by collector name.
+	Extensions map[string]any `json:"extensions,omitempty"`
+	// Collectors reports how fresh each collector's part of the snapshot is.
+	Collectors map[string]CollectorStatus `json:"collectors,omitempty"`
+}
+
+// CollectorStatus describes the last pass of a collector. A stale collector
+// failed or timed out and its previous result was reused.
+type CollectorStatus struct {
+	LastUpdate time.Time     `json:"last_update"`
+	Duration   time.Duration `json:"duration"`
+	Stale      bool          `json:"stale"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func (s *MetricsSnapshot) SetExtension(name string, value any) {
+	if s.Extensions == nil {
+		s.Extensions = make(map[string]any)
+	}
+	s.Extensions[name] = value
+}
+
+func (m *CPUMetrics) Apply(s *MetricsSnapshot) {
+	s.CPU = *m
+}
+
+func (m *MemoryMetrics) Apply(s *MetricsSnapshot) {
+	s.Memory = *m
+}
+
+func (m *StorageMetrics) Apply(s *MetricsSnapshot) {
+	s.Storage = *m
+}
+
+func (m *NetworkMetrics) Apply(s *MetricsSnapshot) {
+	s.Network = *m
+}
+
+func (m *ProcessMetrics) Apply(s *MetricsSnapshot) {
+	s.Processes = *m
+}
+
+func (m *LogMetrics) Apply(s *MetricsSnapshot) {
+	s.Logs = *m
 }
@@ -15,6 +15,47 @@ type SystemConfig struct {
 	SortBy            string        `json:"sort_by"`
 	SortOrder         string        `json:"sort_order"`
 	ViewMode          string        `json:"view_mode"`
+	ProcRoot          string        `json:"proc_root"`
+	SysRoot           string        `json:"sys_root"`
+	HostRoot          string        `json:"host_root"`
+	// HistoryDuration and HistoryResolution size the in-memory history
+	// every graph is drawn from.
+	HistoryDuration   time.Duration `json:"history_duration"`
+	HistoryResolution time.Duration `json:"history_resolution"`
+	// CollectorTimeout bounds a single collection pass of any collector.
+	CollectorTimeout time.Duration `json:"collector_timeout"`
+	// Collectors toggles collectors by name. Collectors without an entry
+	// are enabled and follow RefreshInterval and CollectorTimeout.
+	Collectors map[string]CollectorConfig `json:"collectors,omitempty"`
+	// ProcessSmaps collects PSS, USS, shared and swapped memory of every
+	// process from /proc/[pid]/smaps_rollup, which is costly for the
+	// kernel to produce.
+	ProcessSmaps bool `json:"process_smaps"`
+	// ProcessFilters are the named process view filters saved with S.
+	ProcessFilters map[string]string `json:"process_filters,omitempty"`
+	// ProcessColumns lays out the process list; empty means the default
+	// columns.
+	ProcessColumns []ColumnConfig `json:"process_columns,omitempty"`
+	// AuditLog is the file every process action is appended to, as JSON
+	// lines. Empty keeps the action history for the session only.
+	AuditLog string `json:"audit_log,omitempty"`
+	// ReadOnly disables every process action, such as kill and renice.
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+// ColumnConfig is a column of the process list. A zero Width sizes the
+// column to its content.
+type ColumnConfig struct {
+	Name  string `json:"name"`
+	Width int    `json:"width,omitempty"`
+}
+
+// CollectorConfig tunes a collector. Collectors are enabled unless
+// Disabled is set, so an entry may set just an interval.
+type CollectorConfig struct {
+	Disabled bool          `json:"disabled,omitempty"`
+	Interval time.Duration `json:"interval,omitempty"`
+	Timeout  time.Duration `json:"timeout,omitempty"`
 }
 
 func DefaultSystemConfig() SystemConfig {
@@ -29,6 +70,15 @@ func DefaultSystemConfig() SystemConfig {
 		SortBy:            "cpu",
 		SortOrder:         "desc",
 		ViewMode:          "overview",
+		ProcRoot:          "/proc",
+		SysRoot:           "/sys",
+		HostRoot:          "/",
+		HistoryDuration:   15 * time.Minute,
+		HistoryResolution: time.Second,
+		CollectorTimeout:  2 * time.Second,
+		Collectors: map[string]CollectorConfig{
+			"logs": {Interval: 5 * time.Second},
+		},
 	}
 }
 
@@ -57,13 +107,14 @@ func (e AppError) Error() string {
 type ViewType string
 
 const (
-	ViewOverview  ViewType = "overview"
-	ViewCPU       ViewType = "cpu"
-	ViewMemory    ViewType = "memory"
-	ViewStorage   ViewType = "storage"
-	ViewNetwork   ViewType = "network"
-	ViewProcesses ViewType = "processes"
-	ViewLogs      ViewType = "logs"
+	ViewOverview    ViewType = "overview"
+	ViewCPU         ViewType = "cpu"
+	ViewMemory      ViewType = "memory"
+	ViewStorage     ViewType = "storage"
+	ViewNetwork     ViewType = "network"
+	ViewProcesses   ViewType = "processes"
+	ViewLogs        ViewType = "logs"
+	ViewConnections ViewType = "connections"
 )
 
 type SortField string
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -121,3 +122,16 @@ func TestSystemConfigRefreshInterval(t *testing.T) {
 		}
 	}
 }
+
+func TestCollectorConfigEnabledByDefault(t *testing.T) {
+	var collectors map[string]CollectorConfig
+	if err := json.Unmarshal([]byte(`{"logs":{"interval":5000000000},"cpu":{"disabled":true}}`), &collectors); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if logs := collectors["logs"]; logs.Disabled || logs.Interval != 5*time.Second {
+		t.Errorf("Expected logs enabled with a 5s interval, got %+v", logs)
+	}
+	if !collectors["cpu"].Disabled {
+		t.Error("Expected cpu disabled")
+	}
+}
@@ -0,0 +1,485 @@
+// Package query implements the filter expressions of the process view, such
+// as
+//
+//	cpu>5 mem>200M user=postgres name~^worker state=D
+//
+// Terms compare a field with a value using =, !=, ~ (regular expression),
+// !~, <, <=, > or >=. Terms next to each other must all match; they combine
+// with "or" (||), "and" (&&), "not" (!) and parentheses. A word without an
+// operator matches the name, command, user, PID or state like the plain
+// search did.
+package query
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+// SyntaxError is a query that cannot be parsed. Pos is the byte offset of
+// the problem in the query.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Pos+1)
+}
+
+// Query is a parsed filter. A nil Query matches every process.
+type Query struct {
+	text string
+	root node
+}
+
+// Parse parses a filter expression. An empty expression gives a nil Query.
+func Parse(text string) (*Query, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	p := &parser{input: text}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:p.pos+1])
+	}
+	return &Query{text: text, root: root}, nil
+}
+
+func (q *Query) Match(proc models.Process) bool {
+	if q == nil {
+		return true
+	}
+	return q.root.match(&proc)
+}
+
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.text
+}
+
+type kind int
+
+const (
+	kindText kind = iota
+	kindNumber
+	kindPercent
+	kindBytes
+	kindRate
+	kindDuration
+)
+
+type field struct {
+	kind   kind
+	texts  func(p *models.Process) []string
+	number func(p *models.Process) float64
+}
+
+func textField(get func(p *models.Process) string) field {
+	return field{kind: kindText, texts: func(p *models.Process) []string { return []string{get(p)} }}
+}
+
+func numberField(k kind, get func(p *models.Process) float64) field {
+	return field{kind: k, number: get}
+}
+
+var fields = map[string]field{
+	"name":  textField(func(p *models.Process) string { return p.Name }),
+	"cmd":   textField(func(p *models.Process) string { return p.Command }),
+	"user":  textField(func(p *models.Process) string { return p.User }),
+	"group": textField(func(p *models.Process) string { return p.Group }),
+	// A state matches both its letter and its name, so state=Z and
+	// state=zombie both work.
+	"state": {kind: kindText, texts: func(p *models.Process) []string {
+		return []string{p.State, utils.FormatProcessState(p.State)}
+	}},
+
+	"pid":     numberField(kindNumber, func(p *models.Process) float64 { return float64(p.PID) }),
+	"ppid":    numberField(kindNumber, func(p *models.Process) float64 { return float64(p.PPID) }),
+	"threads": numberField(kindNumber, func(p *models.Process) float64 { return float64(p.NumThreads) }),
+	"fds":     numberField(kindNumber, func(p *models.Process) float64 { return float64(p.NumFDs) }),
+	"nice":    numberField(kindNumber, func(p *models.Process) float64 { return float64(p.Nice) }),
+	"prio":    numberField(kindNumber, func(p *models.Process) float64 { return float64(p.Priority) }),
+	"cpu":     numberField(kindPercent, func(p *models.Process) float64 { return p.CPUPercent }),
+	"mem%":    numberField(kindPercent, func(p *models.Process) float64 { return p.MemoryPercent }),
+	"mem":     numberField(kindBytes, func(p *models.Process) float64 { return float64(p.MemoryRSS) }),
+	"vms":     numberField(kindBytes, func(p *models.Process) float64 { return float64(p.MemoryVMS) }),
+	"pss":     numberField(kindBytes, func(p *models.Process) float64 { return float64(p.MemoryPSS) }),
+	"uss":     numberField(kindBytes, func(p *models.Process) float64 { return float64(p.MemoryUSS) }),
+	"shared":  numberField(kindBytes, func(p *models.Process) float64 { return float64(p.MemoryShared) }),
+	"swap":    numberField(kindBytes, func(p *models.Process) float64 { return float64(p.MemorySwap) }),
+	"read":    numberField(kindRate, func(p *models.Process) float64 { return p.IOStats.ReadBytesPerSec }),
+	"write":   numberField(kindRate, func(p *models.Process) float64 { return p.IOStats.WriteBytesPerSec }),
+	"io":      numberField(kindRate, func(p *models.Process) float64 { return p.IOStats.BytesPerSec() }),
+	"time":    numberField(kindDuration, func(p *models.Process) float64 { return p.CPUTime.Seconds() }),
+}
+
+var fieldAliases = map[string]string{
+	"command": "cmd",
+	"rss":     "mem",
+}
+
+type node interface {
+	match(p *models.Process) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(p *models.Process) bool { return n.left.match(p) && n.right.match(p) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(p *models.Process) bool { return n.left.match(p) || n.right.match(p) }
+
+type notNode struct{ node node }
+
+func (n notNode) match(p *models.Process) bool { return !n.node.match(p) }
+
+// containsNode is a bare word.
+type containsNode struct{ text string }
+
+func (n containsNode) match(p *models.Process) bool {
+	for _, value := range []string{p.Name, p.Command, p.User, strconv.Itoa(p.PID), utils.FormatProcessState(p.State)} {
+		if strings.Contains(strings.ToLower(value), n.text) {
+			return true
+		}
+	}
+	return false
+}
+
+type textNode struct {
+	field field
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (n textNode) match(p *models.Process) bool {
+	matched := false
+	for _, text := range n.field.texts(p) {
+		if n.re != nil {
+			matched = n.re.MatchString(text)
+		} else {
+			matched = strings.EqualFold(text, n.value)
+		}
+		if matched {
+			break
+		}
+	}
+	if n.op == "!=" || n.op == "!~" {
+		return !matched
+	}
+	return matched
+}
+
+type numberNode struct {
+	field field
+	op    string
+	value float64
+}
+
+func (n numberNode) match(p *models.Process) bool {
+	v := n.field.number(p)
+	switch n.op {
+	case "=", "==":
+		return v == n.value
+	case "!=":
+		return v != n.value
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case ">":
+		return v > n.value
+	default:
+		return v >= n.value
+	}
+}
+
+// operators is ordered so that longer operators are tried first.
+var operators = []string{">=", "<=", "!=", "!~", "==", "=", "~", ">", "<"}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.input[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// keyword consumes word if it is the next whole word, in any case.
+func (p *parser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.input) && isWordChar(p.input[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func isWordChar(c byte) bool {
+	return c != ' ' && c != '\t' && !strings.ContainsRune(`()!=<>~"&|`, rune(c))
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("||") && !p.keyword("or") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos == len(p.input) || p.input[p.pos] == ')' || strings.HasPrefix(p.input[p.pos:], "||") {
+			return left, nil
+		}
+		start := p.pos
+		if p.keyword("or") {
+			p.pos = start
+			return left, nil
+		}
+		if !p.consume("&&") {
+			p.keyword("and")
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return nil, p.errorf("expected a filter")
+	}
+	if p.consume("!") || p.keyword("not") {
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	}
+	if p.consume("(") {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return n, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (node, error) {
+	start := p.pos
+	if p.input[p.pos] == '"' {
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return containsNode{strings.ToLower(text)}, nil
+	}
+
+	for p.pos < len(p.input) && isWordChar(p.input[p.pos]) {
+		p.pos++
+	}
+	word := p.input[start:p.pos]
+	if word == "" {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:p.pos+1])
+	}
+	if strings.EqualFold(word, "and") || strings.EqualFold(word, "or") {
+		return nil, &SyntaxError{Pos: start, Msg: "expected a filter before " + strings.ToLower(word)}
+	}
+
+	afterWord := p.pos
+	p.skipSpace()
+	opPos := p.pos
+	op := ""
+	for _, candidate := range operators {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		p.pos = afterWord
+		return containsNode{strings.ToLower(word)}, nil
+	}
+
+	name := strings.ToLower(word)
+	if alias, ok := fieldAliases[name]; ok {
+		name = alias
+	}
+	f, ok := fields[name]
+	if !ok {
+		return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unknown field %q", word)}
+	}
+
+	p.skipSpace()
+	valuePos := p.pos
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, p.errorf("expected a value after %s", op)
+	}
+
+	if f.kind == kindText {
+		switch op {
+		case "=", "==", "!=":
+			return textNode{field: f, op: op, value: value}, nil
+		case "~", "!~":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, &SyntaxError{Pos: valuePos, Msg: "invalid regular expression"}
+			}
+			return textNode{field: f, op: op, re: re}, nil
+		}
+		return nil, &SyntaxError{Pos: opPos, Msg: fmt.Sprintf("%s compares text, %s needs a number", name, op)}
+	}
+
+	if op == "~" || op == "!~" {
+		return nil, &SyntaxError{Pos: opPos, Msg: fmt.Sprintf("%s is a number, %s needs text", name, op)}
+	}
+	number, err := parseNumber(f.kind, value)
+	if err != nil {
+		return nil, &SyntaxError{Pos: valuePos, Msg: err.Error()}
+	}
+	return numberNode{field: f, op: op, value: number}, nil
+}
+
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+	end := strings.IndexByte(p.input[p.pos:], '"')
+	if end < 0 {
+		p.pos = start
+		return "", p.errorf("unterminated quote")
+	}
+	text := p.input[p.pos : p.pos+end]
+	p.pos += end + 1
+	return text, nil
+}
+
+// parseValue reads a quoted value or one that ends at a space or at a
+// closing parenthesis without an opening one, so that regular expressions
+// can contain groups.
+func (p *parser) parseValue() (string, error) {
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		return p.parseQuoted()
+	}
+	start, depth := p.pos, 0
+	for ; p.pos < len(p.input); p.pos++ {
+		c := p.input[p.pos]
+		if c == ' ' || c == '\t' || (c == ')' && depth == 0) {
+			break
+		}
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+		}
+	}
+	return p.input[start:p.pos], nil
+}
+
+var byteUnits = map[string]float64{
+	"":  1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+	"p": 1 << 50,
+}
+
+// parseNumber reads a value in the unit of kind: bytes and rates take K, M,
+// G, T or P suffixes in powers of 1024 like the rest of ltop, rates may end
+// in /s, percentages in % and durations are either Go durations such as 1h30m
+// or seconds.
+func parseNumber(k kind, value string) (float64, error) {
+	text := strings.ToLower(value)
+	switch k {
+	case kindPercent:
+		text = strings.TrimSuffix(text, "%")
+	case kindRate, kindBytes:
+		if k == kindRate {
+			text = strings.TrimSuffix(text, "/s")
+		}
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "b"), "i")
+		i := len(text)
+		for i > 0 && text[i-1] >= 'a' && text[i-1] <= 'z' {
+			i--
+		}
+		multiplier, ok := byteUnits[text[i:]]
+		if !ok {
+			return 0, fmt.Errorf("unknown size unit in %q", value)
+		}
+		n, err := strconv.ParseFloat(text[:i], 64)
+		if err != nil || math.IsNaN(n) {
+			return 0, fmt.Errorf("invalid size %q", value)
+		}
+		return n * multiplier, nil
+	case kindDuration:
+		if d, err := time.ParseDuration(text); err == nil {
+			return d.Seconds(), nil
+		}
+	}
+
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, nil
+}
@@ -0,0 +1,126 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+var testProcesses = []models.Process{
+	{PID: 1, Name: "systemd", Command: "/sbin/init", User: "root", State: "S", CPUPercent: 0.1, MemoryRSS: 12 << 20, CPUTime: 90 * time.Second},
+	{PID: 200, Name: "postgres", Command: "postgres -D /var/lib/pgsql", User: "postgres", State: "S", CPUPercent: 7.5, MemoryRSS: 300 << 20},
+	{PID: 201, Name: "worker-1", Command: "postgres: worker", User: "postgres", State: "D", CPUPercent: 12, MemoryRSS: 150 << 20,
+		IOStats: models.ProcessIOStats{ReadBytesPerSec: 2 << 20}},
+	{PID: 300, Name: "worker-2", Command: "python worker.py", User: "alice", State: "Z", CPUTime: 2 * time.Hour},
+}
+
+func matchingPIDs(t *testing.T, expr string) []int {
+	t.Helper()
+	q, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", expr, err)
+	}
+	var pids []int
+	for _, proc := range testProcesses {
+		if q.Match(proc) {
+			pids = append(pids, proc.PID)
+		}
+	}
+	return pids
+}
+
+func TestQueryMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		pids []int
+	}{
+		{"", []int{1, 200, 201, 300}},
+		{"worker", []int{201, 300}},
+		{"cpu>5 mem>200M user=postgres", []int{200}},
+		{"cpu>5 and mem>200M", []int{200}},
+		{"name~^worker state=D", []int{201}},
+		{"user=POSTGRES", []int{200, 201}},
+		{"state=zombie or pid=1", []int{1, 300}},
+		{"not user=postgres", []int{1, 300}},
+		{"!(user=postgres || user=root)", []int{300}},
+		{"name!~^worker user!=root", []int{200}},
+		{"name~^(worker|post)gres", []int{200}},
+		{"(name~^(worker|post) cpu>=10)", []int{201}},
+		{"read>1MiB/s", []int{201}},
+		{"mem<=12M", []int{1, 300}},
+		{"time>1h", []int{300}},
+		{"time>60", []int{1, 300}},
+		{"cpu>7.5%", []int{201}},
+		{`cmd~"worker\.py$"`, []int{300}},
+		{`"/var/lib"`, []int{200}},
+		{"pid = 200", []int{200}},
+	}
+	for _, test := range tests {
+		pids := matchingPIDs(t, test.expr)
+		if len(pids) != len(test.pids) {
+			t.Errorf("%q matched %v, want %v", test.expr, pids, test.pids)
+			continue
+		}
+		for i := range pids {
+			if pids[i] != test.pids[i] {
+				t.Errorf("%q matched %v, want %v", test.expr, pids, test.pids)
+				break
+			}
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"cpu>", 4},
+		{"bogus=1", 0},
+		{"mem>200X", 4},
+		{"name~(", 5},
+		{"name>5", 4},
+		{"cpu~5", 3},
+		{"(cpu>5", 6},
+		{"cpu>5)", 5},
+		{"and cpu>5", 0},
+		{"cpu>5 or", 8},
+		{`"open`, 0},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.expr)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) = %v, want a syntax error", test.expr, err)
+			continue
+		}
+		if syntaxErr.Pos != test.pos {
+			t.Errorf("Parse(%q) error %q at %d, want %d", test.expr, syntaxErr.Msg, syntaxErr.Pos, test.pos)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		kind  kind
+		value string
+		want  float64
+	}{
+		{kindBytes, "200M", 200 << 20},
+		{kindBytes, "1.5g", 1.5 * (1 << 30)},
+		{kindBytes, "64KiB", 64 << 10},
+		{kindBytes, "512", 512},
+		{kindRate, "10MB/s", 10 << 20},
+		{kindPercent, "5%", 5},
+		{kindDuration, "1h30m", 5400},
+		{kindDuration, "30", 30},
+	}
+	for _, test := range tests {
+		got, err := parseNumber(test.kind, test.value)
+		if err != nil || got != test.want {
+			t.Errorf("parseNumber(%q) = %v, %v; want %v", test.value, got, err, test.want)
+		}
+	}
+}
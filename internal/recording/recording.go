@@ -0,0 +1,161 @@
+package recording
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+// A recording is gzip compressed JSON lines. Every gzip member starts with a
+// header line and is followed by one complete MetricsSnapshot per line, so
+// every snapshot can be replayed on its own:
+//
+//	{"format":"ltop-recording","version":1,"started":"..."}
+//	{"overview":{...},"cpu":{...},...,"timestamp":"..."}
+//	...
+//
+// Appending to an existing recording adds another gzip member, which gzip
+// readers treat as a continuation of the same stream.
+const (
+	Format  = "ltop-recording"
+	Version = 1
+)
+
+type Header struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Started time.Time `json:"started"`
+}
+
+// Writer appends snapshots to a recording. Each snapshot is flushed to the
+// file as it is written, so a recording that is cut short, e.g. by a crash,
+// is readable up to its last complete snapshot.
+type Writer struct {
+	file   *os.File
+	gz     *gzip.Writer
+	enc    *json.Encoder
+	frames int
+}
+
+// Create opens path for appending, creating it if needed, and starts a new
+// section of the recording.
+func Create(path string) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{file: file, gz: gzip.NewWriter(file)}
+	w.enc = json.NewEncoder(w.gz)
+
+	header := Header{Format: Format, Version: Version, Started: time.Now()}
+	if err := w.encode(header); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) encode(v any) error {
+	if err := w.enc.Encode(v); err != nil {
+		return err
+	}
+	return w.gz.Flush()
+}
+
+func (w *Writer) Write(snapshot *models.MetricsSnapshot) error {
+	if err := w.encode(snapshot); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	w.frames++
+	return nil
+}
+
+// Frames returns how many snapshots were written since Create.
+func (w *Writer) Frames() int {
+	return w.frames
+}
+
+func (w *Writer) Close() error {
+	err := w.gz.Close()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Recording holds every snapshot of a recording file in timestamp order.
+type Recording struct {
+	Snapshots []*models.MetricsSnapshot
+	// Truncated is set when the file ended in the middle of a snapshot.
+	Truncated bool
+}
+
+// line is either a header or a snapshot; headers are told apart by their
+// format field, which snapshots do not have.
+type line struct {
+	Header
+	models.MetricsSnapshot
+}
+
+// Open reads a whole recording.
+func Open(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return Read(file)
+}
+
+func Read(r io.Reader) (*Recording, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a recording: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	rec := &Recording{}
+	reader := bufio.NewReader(gz)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(data) == 0 {
+				break
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				rec.Truncated = true
+				break
+			}
+			return nil, err
+		}
+
+		var l line
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("invalid recording entry %d: %w", len(rec.Snapshots)+1, err)
+		}
+		if l.Format != "" {
+			if l.Format != Format || l.Version > Version {
+				return nil, fmt.Errorf("unsupported recording format %s v%d", l.Format, l.Version)
+			}
+			continue
+		}
+
+		snapshot := l.MetricsSnapshot
+		rec.Snapshots = append(rec.Snapshots, &snapshot)
+	}
+
+	sort.SliceStable(rec.Snapshots, func(i, j int) bool {
+		return rec.Snapshots[i].Timestamp.Before(rec.Snapshots[j].Timestamp)
+	})
+	return rec, nil
+}
@@ -0,0 +1,109 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func snapshotAt(ts time.Time, cpu float64) *models.MetricsSnapshot {
+	return &models.MetricsSnapshot{
+		Timestamp: ts,
+		CPU:       models.CPUMetrics{Usage: cpu},
+		Processes: models.ProcessMetrics{
+			Processes: []models.Process{{PID: 42, Name: "worker"}},
+		},
+	}
+}
+
+func TestWriteAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ltop.gz")
+	start := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.Write(snapshotAt(start.Add(time.Duration(i)*time.Second), float64(i))); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if w.Frames() != 3 {
+		t.Errorf("Expected 3 frames, got %d", w.Frames())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// A second session appends to the same file.
+	w, err = Create(path)
+	if err != nil {
+		t.Fatalf("Create for append failed: %v", err)
+	}
+	if err := w.Write(snapshotAt(start.Add(time.Minute), 9)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rec, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if rec.Truncated {
+		t.Error("Expected a complete recording")
+	}
+	if len(rec.Snapshots) != 4 {
+		t.Fatalf("Expected 4 snapshots, got %d", len(rec.Snapshots))
+	}
+	last := rec.Snapshots[3]
+	if last.CPU.Usage != 9 || !last.Timestamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("Unexpected last snapshot: %v at %v", last.CPU.Usage, last.Timestamp)
+	}
+	if len(last.Processes.Processes) != 1 || last.Processes.Processes[0].Name != "worker" {
+		t.Errorf("Expected processes to round-trip, got %v", last.Processes.Processes)
+	}
+}
+
+func TestOpenTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ltop.gz")
+	start := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.Write(snapshotAt(start.Add(time.Duration(i)*time.Second), float64(i))); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	// Simulate a crash: the gzip stream is never closed.
+	_ = w.file.Close()
+
+	rec, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !rec.Truncated {
+		t.Error("Expected the recording to be reported as truncated")
+	}
+	if len(rec.Snapshots) != 2 {
+		t.Errorf("Expected both flushed snapshots, got %d", len(rec.Snapshots))
+	}
+}
+
+func TestOpenRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plain.json")
+	if err := os.WriteFile(path, []byte(`{"timestamp":"2025-01-01T00:00:00Z"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("Expected an uncompressed file to be rejected")
+	}
+}
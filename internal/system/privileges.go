@@ -0,0 +1,155 @@
+package system
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Capability numbers of linux/capability.h.
+const (
+	capKill      = 5
+	capSysPtrace = 19
+	capSysNice   = 23
+)
+
+// Privileges is what ltop may see of and do to the processes of other
+// users.
+type Privileges struct {
+	// User is the user ltop runs as.
+	User string
+	// CapKill allows signaling any process.
+	CapKill bool
+	// CapSysNice allows raising priority and changing the scheduling of
+	// any process.
+	CapSysNice bool
+	// CapSysPtrace allows reading the open files, environment and memory
+	// maps of any process.
+	CapSysPtrace bool
+	// HidePID is the hidepid option of the proc mount: "", "noaccess",
+	// "invisible" or "ptraceable".
+	HidePID string
+}
+
+// DetectPrivileges reads the effective capabilities of ltop and the hidepid
+// option of the proc filesystem mounted at procRoot.
+func DetectPrivileges(procRoot string) Privileges {
+	p := Privileges{User: strconv.Itoa(os.Geteuid())}
+	if u, err := user.LookupId(p.User); err == nil {
+		p.User = u.Username
+	}
+
+	if file, err := os.Open("/proc/self/status"); err == nil {
+		if caps, ok := parseCapEff(file); ok {
+			p.setCapabilities(caps)
+		}
+		_ = file.Close()
+	}
+	if file, err := os.Open("/proc/self/mounts"); err == nil {
+		p.HidePID = parseHidePID(file, procRoot)
+		_ = file.Close()
+	}
+	return p
+}
+
+// setCapabilities sets the capabilities of a CapEff mask.
+func (p *Privileges) setCapabilities(caps uint64) {
+	p.CapKill = caps&(1<<capKill) != 0
+	p.CapSysPtrace = caps&(1<<capSysPtrace) != 0
+	p.CapSysNice = caps&(1<<capSysNice) != 0
+}
+
+// parseCapEff reads the effective capability mask of a
+// /proc/[pid]/status file.
+func parseCapEff(status io.Reader) (uint64, bool) {
+	scanner := bufio.NewScanner(status)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "CapEff:"); ok {
+			caps, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+			return caps, err == nil
+		}
+	}
+	return 0, false
+}
+
+var hidePIDNames = map[string]string{
+	"0": "",
+	"1": "noaccess",
+	"2": "invisible",
+	"4": "ptraceable",
+}
+
+// parseHidePID reads the hidepid option of the proc filesystem mounted at
+// procRoot from a mounts file.
+func parseHidePID(mounts io.Reader, procRoot string) string {
+	root := filepath.Clean(procRoot)
+	hidePID := ""
+	scanner := bufio.NewScanner(mounts)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[2] != "proc" || fields[1] != root {
+			continue
+		}
+		// The last mount of procRoot hides the ones below it.
+		hidePID = ""
+		for _, option := range strings.Split(fields[3], ",") {
+			if value, ok := strings.CutPrefix(option, "hidepid="); ok {
+				hidePID = value
+				if name, ok := hidePIDNames[value]; ok {
+					hidePID = name
+				}
+			}
+		}
+	}
+	if hidePID == "off" {
+		return ""
+	}
+	return hidePID
+}
+
+// IsOwner reports whether a process of owner is ltop's own.
+func (p Privileges) IsOwner(owner string) bool {
+	return owner == p.User
+}
+
+// CanSignal reports whether a process of owner can be signaled.
+func (p Privileges) CanSignal(owner string) bool {
+	return p.CapKill || p.IsOwner(owner)
+}
+
+// CanSchedule reports whether the priority, affinity and scheduling of a
+// process of owner can be changed. Raising the priority of ltop's own
+// processes needs CAP_SYS_NICE as well.
+func (p Privileges) CanSchedule(owner string) bool {
+	return p.CapSysNice || p.IsOwner(owner)
+}
+
+// Limits explains what is unavailable with these privileges.
+func (p Privileges) Limits() []string {
+	var limits []string
+	if !p.CapKill {
+		limits = append(limits, "no CAP_KILL: only processes of "+p.User+" can be signaled")
+	}
+	if !p.CapSysNice {
+		limits = append(limits, "no CAP_SYS_NICE: priority can only be lowered, and only for processes of "+p.User)
+	}
+	if !p.CapSysPtrace {
+		limits = append(limits, "no CAP_SYS_PTRACE: open files, environment and memory details of other users' processes are unreadable")
+	}
+	switch p.HidePID {
+	case "":
+	case "noaccess":
+		limits = append(limits, "/proc is mounted with hidepid=noaccess: details of other users' processes are hidden")
+	case "invisible":
+		limits = append(limits, "/proc is mounted with hidepid=invisible: other users' processes are not listed")
+	case "ptraceable":
+		limits = append(limits, "/proc is mounted with hidepid=ptraceable: processes ltop cannot trace are not listed")
+	default:
+		limits = append(limits, "/proc is mounted with hidepid="+p.HidePID+": other users' processes may be hidden")
+	}
+	return limits
+}
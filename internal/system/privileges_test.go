@@ -0,0 +1,100 @@
+package system
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCapEff(t *testing.T) {
+	tests := []struct {
+		status string
+		want   Privileges
+		ok     bool
+	}{
+		{"Name:\tltop\nCapEff:\t0000000000000000\n", Privileges{}, true},
+		{"CapEff:\t000001ffffffffff\n", Privileges{CapKill: true, CapSysPtrace: true, CapSysNice: true}, true},
+		{"CapEff:\t0000000000000020\n", Privileges{CapKill: true}, true},
+		{"CapEff:\t0000000000080000\n", Privileges{CapSysPtrace: true}, true},
+		{"CapEff:\t0000000000800000\n", Privileges{CapSysNice: true}, true},
+		{"CapPrm:\t000001ffffffffff\n", Privileges{}, false},
+		{"CapEff:\tnot hex\n", Privileges{}, false},
+	}
+	for _, test := range tests {
+		caps, ok := parseCapEff(strings.NewReader(test.status))
+		if ok != test.ok {
+			t.Errorf("parseCapEff(%q) ok = %v, want %v", test.status, ok, test.ok)
+			continue
+		}
+		var p Privileges
+		p.setCapabilities(caps)
+		if p != test.want {
+			t.Errorf("parseCapEff(%q) gives %+v, want %+v", test.status, p, test.want)
+		}
+	}
+}
+
+func TestParseHidePID(t *testing.T) {
+	tests := []struct {
+		name   string
+		mounts string
+		root   string
+		want   string
+	}{
+		{"none", "proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0\n", "/proc", ""},
+		{"numeric", "proc /proc proc rw,relatime,hidepid=2 0 0\n", "/proc", "invisible"},
+		{"numeric noaccess", "proc /proc proc rw,hidepid=1 0 0\n", "/proc", "noaccess"},
+		{"named", "proc /proc proc rw,hidepid=ptraceable 0 0\n", "/proc", "ptraceable"},
+		{"off", "proc /proc proc rw,hidepid=off 0 0\n", "/proc", ""},
+		{"zero", "proc /proc proc rw,hidepid=0 0 0\n", "/proc", ""},
+		{"other root", "proc /proc proc rw,hidepid=2 0 0\n", "/host/proc", ""},
+		{"unclean root", "proc /host/proc proc rw,hidepid=2 0 0\n", "/host/proc/", "invisible"},
+		{"not proc", "tmpfs /proc tmpfs rw,hidepid=2 0 0\n", "/proc", ""},
+		{"last mount wins",
+			"proc /proc proc rw,hidepid=2 0 0\nproc /proc proc rw,relatime 0 0\n", "/proc", ""},
+		{"last mount wins hidden",
+			"proc /proc proc rw 0 0\nproc /proc proc rw,hidepid=invisible 0 0\n", "/proc", "invisible"},
+	}
+	for _, test := range tests {
+		if got := parseHidePID(strings.NewReader(test.mounts), test.root); got != test.want {
+			t.Errorf("%s: parseHidePID = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPrivilegesLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		privileges Privileges
+		want       []string
+	}{
+		{"root", Privileges{User: "root", CapKill: true, CapSysNice: true, CapSysPtrace: true}, nil},
+		{"user", Privileges{User: "alice"}, []string{
+			"no CAP_KILL: only processes of alice can be signaled",
+			"no CAP_SYS_NICE: priority can only be lowered, and only for processes of alice",
+			"no CAP_SYS_PTRACE: open files, environment and memory details of other users' processes are unreadable",
+		}},
+		{"hidepid", Privileges{User: "root", CapKill: true, CapSysNice: true, CapSysPtrace: true, HidePID: "invisible"}, []string{
+			"/proc is mounted with hidepid=invisible: other users' processes are not listed",
+		}},
+		{"unknown hidepid", Privileges{User: "root", CapKill: true, CapSysNice: true, CapSysPtrace: true, HidePID: "7"}, []string{
+			"/proc is mounted with hidepid=7: other users' processes may be hidden",
+		}},
+	}
+	for _, test := range tests {
+		if got := test.privileges.Limits(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Limits() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPrivilegesOwnership(t *testing.T) {
+	p := Privileges{User: "alice"}
+	if !p.CanSignal("alice") || p.CanSignal("bob") || !p.CanSchedule("alice") || p.CanSchedule("bob") {
+		t.Errorf("Expected only alice's processes to be managed: %+v", p)
+	}
+	p.CapKill = true
+	if !p.CanSignal("bob") || p.CanSchedule("bob") {
+		t.Errorf("Expected CAP_KILL to allow signaling only: %+v", p)
+	}
+}
@@ -2,38 +2,86 @@ package system
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 )
 
+const DefaultProcRoot = "/proc"
+
+// UserHZ is USER_HZ, the unit of the CPU times in /proc. It is 100 on every
+// Linux architecture.
+const UserHZ = 100
+
 type ProcReader struct {
+	mu       sync.RWMutex
 	basePath string
+	recorder Recorder
 }
 
 func NewProcReader() *ProcReader {
+	return NewProcReaderAt(DefaultProcRoot)
+}
+
+// NewProcReaderAt returns a reader rooted at basePath instead of the live
+// /proc, e.g. a host procfs mounted into a container or a captured tree.
+func NewProcReaderAt(basePath string) *ProcReader {
+	if basePath == "" {
+		basePath = DefaultProcRoot
+	}
 	return &ProcReader{
-		basePath: "/proc",
+		basePath: basePath,
 	}
 }
 
+func (p *ProcReader) BasePath() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.basePath
+}
+
+// SetBasePath re-roots the reader, e.g. to step through captured frames.
+func (p *ProcReader) SetBasePath(basePath string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.basePath = basePath
+}
+
+// SetRecorder registers r to receive every file and directory listing the
+// reader serves from now on. A nil recorder disables recording.
+func (p *ProcReader) SetRecorder(r Recorder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recorder = r
+}
+
+func (p *ProcReader) fullPath(path string) (string, Recorder) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return fmt.Sprintf("%s/%s", p.basePath, path), p.recorder
+}
+
 func (p *ProcReader) ReadFile(path string) ([]byte, error) {
-	fullPath := fmt.Sprintf("%s/%s", p.basePath, path)
-	return os.ReadFile(fullPath)
+	fullPath, recorder := p.fullPath(path)
+	data, err := os.ReadFile(fullPath)
+	if err == nil && recorder != nil {
+		recorder.RecordFile(KindProc, path, data)
+	}
+	return data, err
 }
 
 func (p *ProcReader) ReadLines(path string) ([]string, error) {
-	fullPath := fmt.Sprintf("%s/%s", p.basePath, path)
-	file, err := os.Open(fullPath)
+	data, err := p.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = file.Close() }()
 
 	var lines []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		lines = append(lines, scanner.Text())
 	}
@@ -41,14 +89,12 @@ func (p *ProcReader) ReadLines(path string) ([]string, error) {
 }
 
 func (p *ProcReader) ReadFirstLine(path string) (string, error) {
-	fullPath := fmt.Sprintf("%s/%s", p.basePath, path)
-	file, err := os.Open(fullPath)
+	data, err := p.ReadFile(path)
 	if err != nil {
 		return "", err
 	}
-	defer func() { _ = file.Close() }()
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	if scanner.Scan() {
 		return scanner.Text(), nil
 	}
@@ -89,21 +135,47 @@ func (p *ProcReader) ReadUptime() (string, error) {
 	return p.ReadFirstLine("uptime")
 }
 
+func (p *ProcReader) ReadHostname() (string, error) {
+	return p.ReadFirstLine("sys/kernel/hostname")
+}
+
 func (p *ProcReader) ReadProcesses() ([]string, error) {
-	entries, err := os.ReadDir(p.basePath)
+	return p.readIDs("", true)
+}
+
+// ReadProcessThreads lists the thread IDs in /proc/[pid]/task.
+func (p *ProcReader) ReadProcessThreads(pid string) ([]string, error) {
+	return p.readIDs(fmt.Sprintf("%s/task", pid), true)
+}
+
+// ReadProcessFDs lists the open file descriptors in /proc/[pid]/fd, which
+// only the owner of a process and root may read.
+func (p *ProcReader) ReadProcessFDs(pid string) ([]string, error) {
+	return p.readIDs(fmt.Sprintf("%s/fd", pid), false)
+}
+
+// readIDs lists the numeric entries of path, such as one directory per
+// process or one symlink per file descriptor.
+func (p *ProcReader) readIDs(path string, dirsOnly bool) ([]string, error) {
+	dirPath, recorder := p.fullPath(path)
+	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var pids []string
+	var ids []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || !dirsOnly {
 			if _, err := strconv.Atoi(entry.Name()); err == nil {
-				pids = append(pids, entry.Name())
+				ids = append(ids, entry.Name())
 			}
 		}
 	}
-	return pids, nil
+
+	if recorder != nil {
+		recorder.RecordDir(KindProc, path, ids)
+	}
+	return ids, nil
 }
 
 func (p *ProcReader) ReadProcessStat(pid string) (string, error) {
@@ -114,6 +186,26 @@ func (p *ProcReader) ReadProcessStatus(pid string) (map[string]string, error) {
 	return p.ReadKeyValuePairs(fmt.Sprintf("%s/status", pid))
 }
 
+// ReadProcessIO reads /proc/[pid]/io, which only the owner of a process and
+// root may read.
+func (p *ProcReader) ReadProcessIO(pid string) (map[string]string, error) {
+	return p.ReadKeyValuePairs(fmt.Sprintf("%s/io", pid))
+}
+
+func (p *ProcReader) ReadThreadStat(pid, tid string) (string, error) {
+	return p.ReadFirstLine(fmt.Sprintf("%s/task/%s/stat", pid, tid))
+}
+
+func (p *ProcReader) ReadThreadStatus(pid, tid string) (map[string]string, error) {
+	return p.ReadKeyValuePairs(fmt.Sprintf("%s/task/%s/status", pid, tid))
+}
+
+// ReadProcessSmapsRollup reads /proc/[pid]/smaps_rollup, which the kernel
+// computes by walking every mapping of the process.
+func (p *ProcReader) ReadProcessSmapsRollup(pid string) (map[string]string, error) {
+	return p.ReadKeyValuePairs(fmt.Sprintf("%s/smaps_rollup", pid))
+}
+
 func (p *ProcReader) ReadProcessCmdline(pid string) (string, error) {
 	data, err := p.ReadFile(fmt.Sprintf("%s/cmdline", pid))
 	if err != nil {
@@ -137,13 +229,32 @@ func (p *ProcReader) ReadMounts() ([]string, error) {
 	return p.ReadLines("mounts")
 }
 
+func (p *ProcReader) ReadProcessMounts(pid string) ([]string, error) {
+	return p.ReadLines(fmt.Sprintf("%s/mounts", pid))
+}
+
 func (p *ProcReader) OpenFile(path string) (io.ReadCloser, error) {
-	fullPath := fmt.Sprintf("%s/%s", p.basePath, path)
-	return os.Open(fullPath)
+	fullPath, recorder := p.fullPath(path)
+	if recorder == nil {
+		return os.Open(fullPath)
+	}
+
+	data, err := p.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// ReadLink resolves a symlink such as /proc/[pid]/exe. Links are not
+// passed to the recorder.
+func (p *ProcReader) ReadLink(path string) (string, error) {
+	fullPath, _ := p.fullPath(path)
+	return os.Readlink(fullPath)
 }
 
 func (p *ProcReader) FileExists(path string) bool {
-	fullPath := fmt.Sprintf("%s/%s", p.basePath, path)
+	fullPath, _ := p.fullPath(path)
 	_, err := os.Stat(fullPath)
 	return err == nil
 }
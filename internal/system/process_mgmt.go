@@ -1,18 +1,43 @@
 package system
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
-type ProcessManager struct{}
+// ErrReadOnly is returned by every change to a process in read-only mode.
+var ErrReadOnly = errors.New("process actions are disabled in read-only mode")
+
+type ProcessManager struct {
+	readOnly   bool
+	procReader *ProcReader
+}
 
 func NewProcessManager() *ProcessManager {
-	return &ProcessManager{}
+	// Signals reach live processes, so their state is read from the live
+	// /proc too.
+	return &ProcessManager{procReader: NewProcReader()}
+}
+
+// SetReadOnly refuses every signal and change to a process with
+// ErrReadOnly.
+func (pm *ProcessManager) SetReadOnly(readOnly bool) {
+	pm.readOnly = readOnly
+}
+
+func (pm *ProcessManager) IsReadOnly() bool {
+	return pm.readOnly
 }
 
 func (pm *ProcessManager) KillProcess(pid int, signal syscall.Signal) error {
+	if pm.readOnly {
+		return ErrReadOnly
+	}
 	process, err := os.FindProcess(pid)
 	if err != nil {
 		return fmt.Errorf("failed to find process %d: %w", pid, err)
@@ -43,6 +68,9 @@ func (pm *ProcessManager) ContinueProcess(pid int) error {
 }
 
 func (pm *ProcessManager) SetProcessPriority(pid int, priority int) error {
+	if pm.readOnly {
+		return ErrReadOnly
+	}
 	err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, priority)
 	if err != nil {
 		return fmt.Errorf("failed to set priority for process %d: %w", pid, err)
@@ -77,3 +105,119 @@ func (pm *ProcessManager) IsProcessRunning(pid int) bool {
 	err = process.Signal(syscall.Signal(0))
 	return err == nil
 }
+
+// KillResult is what KillTree did to one process. Err is set when it could
+// not be signaled; otherwise Exited tells whether it was gone before the
+// timeout.
+type KillResult struct {
+	PID    int
+	Err    error
+	Exited bool
+}
+
+// CheckKillTree refuses a tree KillTree must not signal: one with ltop in
+// it, which would be stopped halfway and leave the processes stopped before
+// it frozen, or one with init.
+func CheckKillTree(tree []int) error {
+	for _, pid := range tree {
+		switch pid {
+		case os.Getpid():
+			return fmt.Errorf("it contains ltop itself (PID %d), which would be stopped halfway and leave the processes stopped before it frozen", pid)
+		case 1:
+			return fmt.Errorf("it contains init (PID 1)")
+		}
+	}
+	return nil
+}
+
+// KillTree signals a process tree. tree lists the root first and every
+// parent before its children. All processes are stopped top down, so that
+// none can fork or restart its children, then signaled bottom up and
+// continued so that they act on the signal. It then waits up to timeout
+// for them to exit. Trees refused by CheckKillTree are not signaled.
+func (pm *ProcessManager) KillTree(tree []int, signal syscall.Signal, timeout time.Duration) []KillResult {
+	results := make([]KillResult, len(tree))
+	if err := CheckKillTree(tree); err != nil {
+		for i, pid := range tree {
+			results[i] = KillResult{PID: pid, Err: err}
+		}
+		return results
+	}
+	index := make(map[int]int, len(tree))
+	for i, pid := range tree {
+		results[i].PID = pid
+		index[pid] = i
+	}
+	// A process that could not be stopped or signaled is left alone, and
+	// one that could not be continued stays stopped, so its error is kept.
+	for _, step := range killTreeSteps(tree, signal) {
+		result := &results[index[step.pid]]
+		if result.Err == nil {
+			result.Err = pm.KillProcess(step.pid, step.signal)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		waiting := false
+		for i := range results {
+			if results[i].Err == nil && !results[i].Exited {
+				results[i].Exited = pm.processExited(results[i].PID)
+				waiting = waiting || !results[i].Exited
+			}
+		}
+		if !waiting || time.Now().After(deadline) {
+			return results
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// killStep is a signal KillTree sends to one process.
+type killStep struct {
+	pid    int
+	signal syscall.Signal
+}
+
+// killTreeSteps orders the signals KillTree sends: SIGSTOP top down, signal
+// bottom up and then SIGCONT top down, unless signal is SIGKILL or SIGSTOP,
+// which need no SIGCONT.
+func killTreeSteps(tree []int, signal syscall.Signal) []killStep {
+	steps := make([]killStep, 0, 3*len(tree))
+	for _, pid := range tree {
+		steps = append(steps, killStep{pid, syscall.SIGSTOP})
+	}
+	for i := len(tree) - 1; i >= 0; i-- {
+		steps = append(steps, killStep{tree[i], signal})
+	}
+	if signal != syscall.SIGKILL && signal != syscall.SIGSTOP {
+		for _, pid := range tree {
+			steps = append(steps, killStep{pid, syscall.SIGCONT})
+		}
+	}
+	return steps
+}
+
+// processExited reports whether pid is gone. A zombie has exited even
+// though it can still be signaled until its parent reaps it.
+func (pm *ProcessManager) processExited(pid int) bool {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return true
+	}
+	stat, err := pm.procReader.ReadProcessStat(strconv.Itoa(pid))
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	return exitedState(stat)
+}
+
+// exitedState reports whether a /proc/[pid]/stat line is of a zombie or
+// dead process. The state follows the command, which may contain ")".
+func exitedState(stat string) bool {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 || end+2 >= len(stat) {
+		return false
+	}
+	state := stat[end+2]
+	return state == 'Z' || state == 'X'
+}
@@ -0,0 +1,83 @@
+package system
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCheckKillTree(t *testing.T) {
+	tests := []struct {
+		tree []int
+		ok   bool
+	}{
+		{[]int{100, 101}, true},
+		{[]int{100, os.Getpid()}, false},
+		{[]int{1, 100}, false},
+	}
+	for _, test := range tests {
+		if err := CheckKillTree(test.tree); (err == nil) != test.ok {
+			t.Errorf("CheckKillTree(%v) = %v", test.tree, err)
+		}
+	}
+
+	pm := NewProcessManager()
+	for _, result := range pm.KillTree([]int{os.Getpid()}, syscall.SIGTERM, time.Second) {
+		if result.Err == nil {
+			t.Error("Expected KillTree to refuse a tree with ltop in it")
+		}
+	}
+}
+
+func TestKillTreeSteps(t *testing.T) {
+	const (
+		stop = syscall.SIGSTOP
+		cont = syscall.SIGCONT
+		term = syscall.SIGTERM
+		kill = syscall.SIGKILL
+	)
+	tests := []struct {
+		signal syscall.Signal
+		want   []killStep
+	}{
+		{term, []killStep{
+			{10, stop}, {11, stop}, {12, stop},
+			{12, term}, {11, term}, {10, term},
+			{10, cont}, {11, cont}, {12, cont},
+		}},
+		{kill, []killStep{
+			{10, stop}, {11, stop}, {12, stop},
+			{12, kill}, {11, kill}, {10, kill},
+		}},
+		{stop, []killStep{
+			{10, stop}, {11, stop}, {12, stop},
+			{12, stop}, {11, stop}, {10, stop},
+		}},
+	}
+	for _, test := range tests {
+		if got := killTreeSteps([]int{10, 11, 12}, test.signal); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("killTreeSteps(%v) = %v, want %v", test.signal, got, test.want)
+		}
+	}
+}
+
+func TestExitedState(t *testing.T) {
+	tests := []struct {
+		stat   string
+		exited bool
+	}{
+		{"42 (sleep) S 1 42 42 0 -1", false},
+		{"42 (sleep) Z 1 42 42 0 -1", true},
+		{"42 (sleep) X 1 42 42 0 -1", true},
+		{"42 (odd) Z (name) R 1 42 42 0 -1", false},
+		{"42 (truncated)", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := exitedState(test.stat); got != test.exited {
+			t.Errorf("exitedState(%q) = %v, want %v", test.stat, got, test.exited)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// Scheduling policies of sched_setscheduler(2).
+const (
+	SchedOther = 0
+	SchedFIFO  = 1
+	SchedRR    = 2
+	SchedBatch = 3
+	SchedIdle  = 5
+)
+
+var schedPolicyNames = map[int]string{
+	SchedOther: "other",
+	SchedFIFO:  "fifo",
+	SchedRR:    "rr",
+	SchedBatch: "batch",
+	SchedIdle:  "idle",
+}
+
+// SchedPolicy is the scheduling policy of a process. Priority is the
+// real-time priority, 1 to 99, and only used by SCHED_FIFO and SCHED_RR.
+type SchedPolicy struct {
+	Policy   int
+	Priority int
+}
+
+func (p SchedPolicy) IsRealtime() bool {
+	return p.Policy == SchedFIFO || p.Policy == SchedRR
+}
+
+// String formats the policy like ParseSchedPolicy accepts it, such as
+// "fifo 10".
+func (p SchedPolicy) String() string {
+	name, ok := schedPolicyNames[p.Policy]
+	if !ok {
+		name = fmt.Sprintf("policy %d", p.Policy)
+	}
+	if p.IsRealtime() {
+		return fmt.Sprintf("%s %d", name, p.Priority)
+	}
+	return name
+}
+
+// ParseSchedPolicy parses a policy name, optionally prefixed with SCHED_,
+// followed by a priority for the real-time policies: "batch", "rr 50".
+func ParseSchedPolicy(text string) (SchedPolicy, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 {
+		return SchedPolicy{}, fmt.Errorf("missing policy")
+	}
+	name := strings.TrimPrefix(fields[0], "sched_")
+	policy := -1
+	for p, n := range schedPolicyNames {
+		if n == name {
+			policy = p
+		}
+	}
+	if policy < 0 {
+		return SchedPolicy{}, fmt.Errorf("unknown policy %q, expected other, batch, idle, fifo or rr", fields[0])
+	}
+
+	sp := SchedPolicy{Policy: policy}
+	if !sp.IsRealtime() {
+		if len(fields) > 1 {
+			return SchedPolicy{}, fmt.Errorf("policy %s takes no priority", name)
+		}
+		return sp, nil
+	}
+	if len(fields) != 2 {
+		return SchedPolicy{}, fmt.Errorf("policy %s needs a priority from 1 to 99", name)
+	}
+	priority, err := strconv.Atoi(fields[1])
+	if err != nil || priority < 1 || priority > 99 {
+		return SchedPolicy{}, fmt.Errorf("priority must be between 1 and 99")
+	}
+	sp.Priority = priority
+	return sp, nil
+}
+
+// I/O scheduling classes of ioprio_set(2).
+const (
+	IOPrioClassNone = 0
+	IOPrioClassRT   = 1
+	IOPrioClassBE   = 2
+	IOPrioClassIdle = 3
+)
+
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioLevelMask  = 1<<ioprioClassShift - 1
+)
+
+var ioprioClassNames = map[int]string{
+	IOPrioClassNone: "none",
+	IOPrioClassRT:   "realtime",
+	IOPrioClassBE:   "best-effort",
+	IOPrioClassIdle: "idle",
+}
+
+// IOPriority is the I/O scheduling class of a process and its level, 0
+// (highest) to 7, within the real-time and best-effort classes. Processes
+// of class none are served as best-effort with a level derived from their
+// nice value.
+type IOPriority struct {
+	Class int
+	Level int
+}
+
+// ioprioValue encodes p as ioprio_set(2) takes it.
+func (p IOPriority) ioprioValue() int {
+	return p.Class<<ioprioClassShift | p.Level
+}
+
+// ioPriorityOf decodes a value returned by ioprio_get(2).
+func ioPriorityOf(value int) IOPriority {
+	return IOPriority{Class: value >> ioprioClassShift, Level: value & ioprioLevelMask}
+}
+
+// String formats the priority like ParseIOPriority accepts it, such as
+// "best-effort 4".
+func (p IOPriority) String() string {
+	name, ok := ioprioClassNames[p.Class]
+	if !ok {
+		name = fmt.Sprintf("class %d", p.Class)
+	}
+	if p.Class == IOPrioClassRT || p.Class == IOPrioClassBE {
+		return fmt.Sprintf("%s %d", name, p.Level)
+	}
+	return name
+}
+
+// ParseIOPriority parses a class followed by a level for the real-time and
+// best-effort classes, like ionice: "be 4", "realtime 0", "idle".
+func ParseIOPriority(text string) (IOPriority, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 {
+		return IOPriority{}, fmt.Errorf("missing class")
+	}
+	var class int
+	switch fields[0] {
+	case "none", "0":
+		class = IOPrioClassNone
+	case "realtime", "rt", "1":
+		class = IOPrioClassRT
+	case "best-effort", "be", "2":
+		class = IOPrioClassBE
+	case "idle", "3":
+		class = IOPrioClassIdle
+	default:
+		return IOPriority{}, fmt.Errorf("unknown class %q, expected none, rt, be or idle", fields[0])
+	}
+
+	prio := IOPriority{Class: class}
+	if class == IOPrioClassNone || class == IOPrioClassIdle {
+		if len(fields) > 1 {
+			return IOPriority{}, fmt.Errorf("class %s takes no level", ioprioClassNames[class])
+		}
+		return prio, nil
+	}
+	if len(fields) == 1 {
+		prio.Level = 4
+		return prio, nil
+	}
+	level, err := strconv.Atoi(fields[1])
+	if err != nil || level < 0 || level > 7 || len(fields) > 2 {
+		return IOPriority{}, fmt.Errorf("level must be between 0 and 7")
+	}
+	prio.Level = level
+	return prio, nil
+}
+
+// FormatCPUList formats CPUs as a list of ranges, such as "0-3,6", like
+// taskset -c and /sys/devices/system/cpu/online.
+func FormatCPUList(cpus []int) string {
+	cpus = append([]int(nil), cpus...)
+	sort.Ints(cpus)
+	var ranges []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(cpus[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
+
+// ParseCPUList parses a list of CPUs and CPU ranges as FormatCPUList formats
+// them. CPUs beyond what an affinity mask can hold are refused.
+func ParseCPUList(text string) ([]int, error) {
+	seen := make(map[int]bool)
+	var cpus []int
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(last))
+		}
+		if err != nil || start < 0 || end < start {
+			return nil, fmt.Errorf("invalid CPU range %q", part)
+		}
+		if end >= maxCPUs {
+			return nil, fmt.Errorf("CPU %d is out of range, the highest is %d", end, maxCPUs-1)
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			if !seen[cpu] {
+				seen[cpu] = true
+				cpus = append(cpus, cpu)
+			}
+		}
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("no CPUs given")
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// maxCPUMaskBytes bounds the CPU mask GetAffinity grows into, enough for
+// maxCPUs CPUs.
+const (
+	maxCPUMaskBytes = 8192
+	maxCPUs         = maxCPUMaskBytes * 8
+)
+
+func (pm *ProcessManager) GetAffinity(pid int) ([]int, error) {
+	// The kernel refuses masks smaller than its own, so grow the mask from
+	// the 1024 CPUs of glibc's cpu_set_t until it fits.
+	for size := 128; size <= maxCPUMaskBytes; size *= 2 {
+		mask := make([]byte, size)
+		n, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY,
+			uintptr(pid), uintptr(len(mask)), uintptr(unsafe.Pointer(&mask[0])))
+		if errno == syscall.EINVAL {
+			continue
+		}
+		if errno != 0 {
+			return nil, fmt.Errorf("failed to get CPU affinity for process %d: %w", pid, errno)
+		}
+		var cpus []int
+		for i, b := range mask[:n] {
+			for bit := 0; bit < 8; bit++ {
+				if b&(1<<bit) != 0 {
+					cpus = append(cpus, i*8+bit)
+				}
+			}
+		}
+		return cpus, nil
+	}
+	return nil, fmt.Errorf("failed to get CPU affinity for process %d: %w", pid, syscall.EINVAL)
+}
+
+func (pm *ProcessManager) SetAffinity(pid int, cpus []int) error {
+	if pm.readOnly {
+		return ErrReadOnly
+	}
+	size := 128
+	for _, cpu := range cpus {
+		for cpu/8 >= size {
+			size *= 2
+		}
+	}
+	mask := make([]byte, size)
+	for _, cpu := range cpus {
+		mask[cpu/8] |= 1 << (cpu % 8)
+	}
+	err := pm.forEachThread(pid, func(tid int) error {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY,
+			uintptr(tid), uintptr(len(mask)), uintptr(unsafe.Pointer(&mask[0])))
+		return errnoErr(errno)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set CPU affinity for process %d: %w", pid, err)
+	}
+	return nil
+}
+
+// forEachThread applies set to every thread of pid, like taskset -a, since
+// the scheduling syscalls only change the thread they are given. Threads
+// that exit meanwhile are skipped.
+func (pm *ProcessManager) forEachThread(pid int, set func(tid int) error) error {
+	tids, err := pm.procReader.ReadProcessThreads(strconv.Itoa(pid))
+	if err != nil || len(tids) == 0 {
+		return set(pid)
+	}
+	for _, id := range tids {
+		tid, _ := strconv.Atoi(id)
+		err := set(tid)
+		switch {
+		case err == nil:
+		case tid == pid:
+			return err
+		case !errors.Is(err, syscall.ESRCH):
+			return fmt.Errorf("thread %d: %w", tid, err)
+		}
+	}
+	return nil
+}
+
+// errnoErr returns errno as an error, or nil if it is zero.
+func errnoErr(errno syscall.Errno) error {
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// schedParam is struct sched_param.
+type schedParam struct {
+	priority int32
+}
+
+func (pm *ProcessManager) GetScheduler(pid int) (SchedPolicy, error) {
+	policy, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETSCHEDULER, uintptr(pid), 0, 0)
+	if errno != 0 {
+		return SchedPolicy{}, fmt.Errorf("failed to get scheduling policy for process %d: %w", pid, errno)
+	}
+	var param schedParam
+	_, _, errno = syscall.RawSyscall(syscall.SYS_SCHED_GETPARAM, uintptr(pid), uintptr(unsafe.Pointer(&param)), 0)
+	if errno != 0 {
+		return SchedPolicy{}, fmt.Errorf("failed to get scheduling priority for process %d: %w", pid, errno)
+	}
+	// Children of SCHED_RESET_ON_FORK processes report it in the policy.
+	const resetOnFork = 0x40000000
+	return SchedPolicy{Policy: int(policy &^ resetOnFork), Priority: int(param.priority)}, nil
+}
+
+func (pm *ProcessManager) SetScheduler(pid int, policy SchedPolicy) error {
+	if pm.readOnly {
+		return ErrReadOnly
+	}
+	param := schedParam{priority: int32(policy.Priority)}
+	err := pm.forEachThread(pid, func(tid int) error {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETSCHEDULER,
+			uintptr(tid), uintptr(policy.Policy), uintptr(unsafe.Pointer(&param)))
+		return errnoErr(errno)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set scheduling policy for process %d: %w", pid, err)
+	}
+	return nil
+}
+
+func (pm *ProcessManager) GetIOPriority(pid int) (IOPriority, error) {
+	prio, _, errno := syscall.RawSyscall(syscall.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno != 0 {
+		return IOPriority{}, fmt.Errorf("failed to get I/O priority for process %d: %w", pid, errno)
+	}
+	return ioPriorityOf(int(prio)), nil
+}
+
+func (pm *ProcessManager) SetIOPriority(pid int, prio IOPriority) error {
+	if pm.readOnly {
+		return ErrReadOnly
+	}
+	value := prio.ioprioValue()
+	err := pm.forEachThread(pid, func(tid int) error {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(value))
+		return errnoErr(errno)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set I/O priority for process %d: %w", pid, err)
+	}
+	return nil
+}
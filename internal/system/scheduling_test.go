@@ -0,0 +1,194 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestForEachThread(t *testing.T) {
+	root := t.TempDir()
+	for _, tid := range []string{"100", "101", "102"} {
+		if err := os.MkdirAll(filepath.Join(root, "100", "task", tid), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pm := &ProcessManager{procReader: NewProcReaderAt(root)}
+
+	var set []int
+	err := pm.forEachThread(100, func(tid int) error {
+		set = append(set, tid)
+		if tid == 101 {
+			return syscall.ESRCH
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected an exited thread to be skipped, got %v", err)
+	}
+	if !reflect.DeepEqual(set, []int{100, 101, 102}) {
+		t.Errorf("Expected every thread set, got %v", set)
+	}
+
+	err = pm.forEachThread(100, func(tid int) error {
+		if tid == 102 {
+			return syscall.EPERM
+		}
+		return nil
+	})
+	if !errors.Is(err, syscall.EPERM) {
+		t.Errorf("Expected the thread's error, got %v", err)
+	}
+
+	// Without a task directory only the process itself is set.
+	set = nil
+	_ = pm.forEachThread(200, func(tid int) error {
+		set = append(set, tid)
+		return nil
+	})
+	if !reflect.DeepEqual(set, []int{200}) {
+		t.Errorf("Expected only the process set, got %v", set)
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		text string
+		cpus []int
+		ok   bool
+	}{
+		{"0", []int{0}, true},
+		{"0-3,6", []int{0, 1, 2, 3, 6}, true},
+		{" 6 , 1-2 ,", []int{1, 2, 6}, true},
+		{"2,1-3", []int{1, 2, 3}, true},
+		{"65535", []int{65535}, true},
+		{"", nil, false},
+		{",", nil, false},
+		{"a", nil, false},
+		{"-1", nil, false},
+		{"3-1", nil, false},
+		{"1-", nil, false},
+		{"65536", nil, false},
+		{"0-2000000000", nil, false},
+	}
+	for _, test := range tests {
+		cpus, err := ParseCPUList(test.text)
+		if (err == nil) != test.ok {
+			t.Errorf("ParseCPUList(%q) error = %v", test.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(cpus, test.cpus) {
+			t.Errorf("ParseCPUList(%q) = %v, want %v", test.text, cpus, test.cpus)
+		}
+	}
+}
+
+func TestFormatCPUList(t *testing.T) {
+	tests := []struct {
+		cpus []int
+		text string
+	}{
+		{nil, ""},
+		{[]int{0}, "0"},
+		{[]int{0, 1, 2, 3, 6}, "0-3,6"},
+		{[]int{6, 0, 2, 1}, "0-2,6"},
+		{[]int{1, 3, 5}, "1,3,5"},
+	}
+	for _, test := range tests {
+		if got := FormatCPUList(test.cpus); got != test.text {
+			t.Errorf("FormatCPUList(%v) = %q, want %q", test.cpus, got, test.text)
+		}
+	}
+}
+
+func TestParseSchedPolicy(t *testing.T) {
+	tests := []struct {
+		text   string
+		policy SchedPolicy
+		ok     bool
+	}{
+		{"other", SchedPolicy{Policy: SchedOther}, true},
+		{"SCHED_BATCH", SchedPolicy{Policy: SchedBatch}, true},
+		{"idle", SchedPolicy{Policy: SchedIdle}, true},
+		{"fifo 10", SchedPolicy{Policy: SchedFIFO, Priority: 10}, true},
+		{"rr 99", SchedPolicy{Policy: SchedRR, Priority: 99}, true},
+		{"", SchedPolicy{}, false},
+		{"deadline", SchedPolicy{}, false},
+		{"batch 5", SchedPolicy{}, false},
+		{"fifo", SchedPolicy{}, false},
+		{"rr 0", SchedPolicy{}, false},
+		{"rr 100", SchedPolicy{}, false},
+		{"rr 5 6", SchedPolicy{}, false},
+	}
+	for _, test := range tests {
+		policy, err := ParseSchedPolicy(test.text)
+		if (err == nil) != test.ok {
+			t.Errorf("ParseSchedPolicy(%q) error = %v", test.text, err)
+			continue
+		}
+		if policy != test.policy {
+			t.Errorf("ParseSchedPolicy(%q) = %+v, want %+v", test.text, policy, test.policy)
+		}
+		if again, err := ParseSchedPolicy(policy.String()); test.ok && (err != nil || again != policy) {
+			t.Errorf("%+v.String() = %q does not parse back", policy, policy.String())
+		}
+	}
+}
+
+func TestParseIOPriority(t *testing.T) {
+	tests := []struct {
+		text string
+		prio IOPriority
+		ok   bool
+	}{
+		{"none", IOPriority{Class: IOPrioClassNone}, true},
+		{"idle", IOPriority{Class: IOPrioClassIdle}, true},
+		{"be", IOPriority{Class: IOPrioClassBE, Level: 4}, true},
+		{"best-effort 7", IOPriority{Class: IOPrioClassBE, Level: 7}, true},
+		{"rt 0", IOPriority{Class: IOPrioClassRT}, true},
+		{"1 2", IOPriority{Class: IOPrioClassRT, Level: 2}, true},
+		{"", IOPriority{}, false},
+		{"fast", IOPriority{}, false},
+		{"idle 3", IOPriority{}, false},
+		{"be 8", IOPriority{}, false},
+		{"be -1", IOPriority{}, false},
+		{"be 1 2", IOPriority{}, false},
+	}
+	for _, test := range tests {
+		prio, err := ParseIOPriority(test.text)
+		if (err == nil) != test.ok {
+			t.Errorf("ParseIOPriority(%q) error = %v", test.text, err)
+			continue
+		}
+		if prio != test.prio {
+			t.Errorf("ParseIOPriority(%q) = %+v, want %+v", test.text, prio, test.prio)
+		}
+	}
+}
+
+func TestIOPriorityValue(t *testing.T) {
+	tests := []struct {
+		prio  IOPriority
+		value int
+		text  string
+	}{
+		{IOPriority{Class: IOPrioClassNone}, 0, "none"},
+		{IOPriority{Class: IOPrioClassRT, Level: 3}, 1<<13 | 3, "realtime 3"},
+		{IOPriority{Class: IOPrioClassBE, Level: 7}, 2<<13 | 7, "best-effort 7"},
+		{IOPriority{Class: IOPrioClassIdle}, 3 << 13, "idle"},
+	}
+	for _, test := range tests {
+		if got := test.prio.ioprioValue(); got != test.value {
+			t.Errorf("%+v.ioprioValue() = %d, want %d", test.prio, got, test.value)
+		}
+		if got := ioPriorityOf(test.value); got != test.prio {
+			t.Errorf("ioPriorityOf(%d) = %+v, want %+v", test.value, got, test.prio)
+		}
+		if got := test.prio.String(); got != test.text {
+			t.Errorf("%+v.String() = %q, want %q", test.prio, got, test.text)
+		}
+	}
+}
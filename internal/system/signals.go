@@ -0,0 +1,85 @@
+package system
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// SignalInfo is a signal as listed in the signal menu.
+type SignalInfo struct {
+	Name   string
+	Signal syscall.Signal
+}
+
+// linuxSignals are the standard signals in number order.
+var linuxSignals = []SignalInfo{
+	{"SIGHUP", syscall.SIGHUP},
+	{"SIGINT", syscall.SIGINT},
+	{"SIGQUIT", syscall.SIGQUIT},
+	{"SIGILL", syscall.SIGILL},
+	{"SIGTRAP", syscall.SIGTRAP},
+	{"SIGABRT", syscall.SIGABRT},
+	{"SIGBUS", syscall.SIGBUS},
+	{"SIGFPE", syscall.SIGFPE},
+	{"SIGKILL", syscall.SIGKILL},
+	{"SIGUSR1", syscall.SIGUSR1},
+	{"SIGSEGV", syscall.SIGSEGV},
+	{"SIGUSR2", syscall.SIGUSR2},
+	{"SIGPIPE", syscall.SIGPIPE},
+	{"SIGALRM", syscall.SIGALRM},
+	{"SIGTERM", syscall.SIGTERM},
+	{"SIGSTKFLT", syscall.SIGSTKFLT},
+	{"SIGCHLD", syscall.SIGCHLD},
+	{"SIGCONT", syscall.SIGCONT},
+	{"SIGSTOP", syscall.SIGSTOP},
+	{"SIGTSTP", syscall.SIGTSTP},
+	{"SIGTTIN", syscall.SIGTTIN},
+	{"SIGTTOU", syscall.SIGTTOU},
+	{"SIGURG", syscall.SIGURG},
+	{"SIGXCPU", syscall.SIGXCPU},
+	{"SIGXFSZ", syscall.SIGXFSZ},
+	{"SIGVTALRM", syscall.SIGVTALRM},
+	{"SIGPROF", syscall.SIGPROF},
+	{"SIGWINCH", syscall.SIGWINCH},
+	{"SIGIO", syscall.SIGIO},
+	{"SIGPWR", syscall.SIGPWR},
+	{"SIGSYS", syscall.SIGSYS},
+}
+
+// The real-time signals. The C library keeps 32 and 33 for itself, so
+// SIGRTMIN is 34 as seen by other processes.
+const (
+	sigRTMin = 34
+	sigRTMax = 64
+)
+
+// Signals lists every Linux signal by number: the standard ones followed by
+// the real-time signals, named like kill -l does.
+func Signals() []SignalInfo {
+	signals := append([]SignalInfo(nil), linuxSignals...)
+	for n := sigRTMin; n <= sigRTMax; n++ {
+		var name string
+		switch {
+		case n == sigRTMin:
+			name = "SIGRTMIN"
+		case n == sigRTMax:
+			name = "SIGRTMAX"
+		case n-sigRTMin <= (sigRTMax-sigRTMin)/2:
+			name = fmt.Sprintf("SIGRTMIN+%d", n-sigRTMin)
+		default:
+			name = fmt.Sprintf("SIGRTMAX-%d", sigRTMax-n)
+		}
+		signals = append(signals, SignalInfo{Name: name, Signal: syscall.Signal(n)})
+	}
+	return signals
+}
+
+// SignalName names a signal like the signal menu, such as SIGTERM.
+func SignalName(signal syscall.Signal) string {
+	for _, info := range Signals() {
+		if info.Signal == signal {
+			return info.Name
+		}
+	}
+	return fmt.Sprintf("signal %d", int(signal))
+}
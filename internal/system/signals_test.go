@@ -0,0 +1,42 @@
+package system
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestSignals(t *testing.T) {
+	signals := Signals()
+	if len(signals) != 62 {
+		t.Fatalf("Expected 62 signals, got %d", len(signals))
+	}
+	for i := 1; i < len(signals); i++ {
+		if signals[i].Signal <= signals[i-1].Signal {
+			t.Errorf("Expected signals in number order, %s follows %s", signals[i].Name, signals[i-1].Name)
+		}
+	}
+
+	// Named like kill -l: SIGRTMIN+n up to the middle of the range, then
+	// SIGRTMAX-n.
+	tests := []struct {
+		signal syscall.Signal
+		name   string
+	}{
+		{syscall.SIGHUP, "SIGHUP"},
+		{syscall.SIGTERM, "SIGTERM"},
+		{syscall.SIGSYS, "SIGSYS"},
+		{34, "SIGRTMIN"},
+		{35, "SIGRTMIN+1"},
+		{49, "SIGRTMIN+15"},
+		{50, "SIGRTMAX-14"},
+		{63, "SIGRTMAX-1"},
+		{64, "SIGRTMAX"},
+		{32, "signal 32"},
+		{65, "signal 65"},
+	}
+	for _, test := range tests {
+		if got := SignalName(test.signal); got != test.name {
+			t.Errorf("SignalName(%d) = %q, want %q", int(test.signal), got, test.name)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package system
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const DefaultHostRoot = "/"
+
+// Paths describes where the monitored system's state is found. The defaults
+// describe the machine ltop runs on; pointing them at a host mount (or a
+// captured directory tree) makes every collector describe that system instead.
+type Paths struct {
+	ProcRoot string `json:"proc_root"`
+	SysRoot  string `json:"sys_root"`
+	HostRoot string `json:"host_root"`
+}
+
+func DefaultPaths() Paths {
+	return Paths{
+		ProcRoot: DefaultProcRoot,
+		SysRoot:  DefaultSysRoot,
+		HostRoot: DefaultHostRoot,
+	}
+}
+
+// WithDefaults fills any empty root with its default.
+func (p Paths) WithDefaults() Paths {
+	if p.ProcRoot == "" {
+		p.ProcRoot = DefaultProcRoot
+	}
+	if p.SysRoot == "" {
+		p.SysRoot = DefaultSysRoot
+	}
+	if p.HostRoot == "" {
+		p.HostRoot = DefaultHostRoot
+	}
+	return p
+}
+
+// Kind identifies which tree a recorded path belongs to.
+type Kind string
+
+const (
+	KindProc Kind = "proc"
+	KindSys  Kind = "sys"
+)
+
+// Recorder receives everything a Source serves to collectors, which is
+// enough to replay the same collection pass later without the live kernel.
+type Recorder interface {
+	RecordFile(kind Kind, path string, data []byte)
+	RecordDir(kind Kind, path string, names []string)
+	RecordDiskUsage(mountpoint string, usage *DiskUsage)
+}
+
+// DiskUsageFunc reports filesystem usage for a mountpoint of the monitored
+// system.
+type DiskUsageFunc func(mountpoint string) (*DiskUsage, error)
+
+// Source bundles the readers collectors use, so that a single set of roots
+// is shared by every collector of an App. The clock and disk usage lookup
+// can be replaced to replay captured data deterministically.
+type Source struct {
+	Proc     *ProcReader
+	Sys      *SysReader
+	hostRoot string
+
+	mu        sync.RWMutex
+	clock     func() time.Time
+	diskUsage DiskUsageFunc
+	recorder  Recorder
+}
+
+func NewSource(paths Paths) *Source {
+	paths = paths.WithDefaults()
+	return &Source{
+		Proc:     NewProcReaderAt(paths.ProcRoot),
+		Sys:      NewSysReaderAt(paths.SysRoot),
+		hostRoot: paths.HostRoot,
+		clock:    time.Now,
+	}
+}
+
+func DefaultSource() *Source {
+	return NewSource(DefaultPaths())
+}
+
+func (s *Source) Paths() Paths {
+	return Paths{
+		ProcRoot: s.Proc.BasePath(),
+		SysRoot:  s.Sys.BasePath(),
+		HostRoot: s.hostRoot,
+	}
+}
+
+// HostPath maps an absolute path on the monitored system (such as a
+// mountpoint listed in its mounts file) to the path visible to ltop.
+func (s *Source) HostPath(path string) string {
+	if s.hostRoot == "" || s.hostRoot == DefaultHostRoot {
+		return path
+	}
+	return filepath.Join(s.hostRoot, path)
+}
+
+// IsHostRooted reports whether the monitored root filesystem differs from
+// the one ltop runs in.
+func (s *Source) IsHostRooted() bool {
+	return s.hostRoot != "" && s.hostRoot != DefaultHostRoot
+}
+
+func (s *Source) DiskUsage(mountpoint string) (*DiskUsage, error) {
+	s.mu.RLock()
+	diskUsage, recorder := s.diskUsage, s.recorder
+	s.mu.RUnlock()
+
+	var usage *DiskUsage
+	var err error
+	if diskUsage != nil {
+		usage, err = diskUsage(mountpoint)
+	} else {
+		usage, err = GetDiskUsage(s.HostPath(mountpoint))
+	}
+
+	if err == nil && recorder != nil {
+		recorder.RecordDiskUsage(mountpoint, usage)
+	}
+	return usage, err
+}
+
+// SetDiskUsageFunc replaces the statfs lookup; nil restores the default.
+func (s *Source) SetDiskUsageFunc(fn DiskUsageFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diskUsage = fn
+}
+
+// Now is the time collectors stamp metrics with and compute rates against.
+func (s *Source) Now() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clock()
+}
+
+// SetClock replaces the time source; nil restores time.Now.
+func (s *Source) SetClock(clock func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if clock == nil {
+		clock = time.Now
+	}
+	s.clock = clock
+}
+
+// SetRecorder records everything served by the source's readers and disk
+// usage lookups. A nil recorder disables recording.
+func (s *Source) SetRecorder(r Recorder) {
+	s.mu.Lock()
+	s.recorder = r
+	s.mu.Unlock()
+
+	s.Proc.SetRecorder(r)
+	s.Sys.SetRecorder(r)
+}
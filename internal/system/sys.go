@@ -5,21 +5,65 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
+const DefaultSysRoot = "/sys"
+
 type SysReader struct {
+	mu       sync.RWMutex
 	basePath string
+	recorder Recorder
 }
 
 func NewSysReader() *SysReader {
+	return NewSysReaderAt(DefaultSysRoot)
+}
+
+// NewSysReaderAt returns a reader rooted at basePath instead of the live /sys.
+func NewSysReaderAt(basePath string) *SysReader {
+	if basePath == "" {
+		basePath = DefaultSysRoot
+	}
 	return &SysReader{
-		basePath: "/sys",
+		basePath: basePath,
 	}
 }
 
+func (s *SysReader) BasePath() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.basePath
+}
+
+// SetBasePath re-roots the reader, e.g. to step through captured frames.
+func (s *SysReader) SetBasePath(basePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.basePath = basePath
+}
+
+// SetRecorder registers r to receive every file and directory listing the
+// reader serves from now on. A nil recorder disables recording.
+func (s *SysReader) SetRecorder(r Recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = r
+}
+
+func (s *SysReader) fullPath(path string) (string, Recorder) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return filepath.Join(s.basePath, path), s.recorder
+}
+
 func (s *SysReader) ReadFile(path string) ([]byte, error) {
-	fullPath := filepath.Join(s.basePath, path)
-	return os.ReadFile(fullPath)
+	fullPath, recorder := s.fullPath(path)
+	data, err := os.ReadFile(fullPath)
+	if err == nil && recorder != nil {
+		recorder.RecordFile(KindSys, path, data)
+	}
+	return data, err
 }
 
 func (s *SysReader) ReadString(path string) (string, error) {
@@ -31,13 +75,13 @@ func (s *SysReader) ReadString(path string) (string, error) {
 }
 
 func (s *SysReader) FileExists(path string) bool {
-	fullPath := filepath.Join(s.basePath, path)
+	fullPath, _ := s.fullPath(path)
 	_, err := os.Stat(fullPath)
 	return err == nil
 }
 
 func (s *SysReader) ListDir(path string) ([]string, error) {
-	fullPath := filepath.Join(s.basePath, path)
+	fullPath, recorder := s.fullPath(path)
 	entries, err := os.ReadDir(fullPath)
 	if err != nil {
 		return nil, err
@@ -47,6 +91,10 @@ func (s *SysReader) ListDir(path string) ([]string, error) {
 	for _, entry := range entries {
 		names = append(names, entry.Name())
 	}
+
+	if recorder != nil {
+		recorder.RecordDir(KindSys, path, names)
+	}
 	return names, nil
 }
 
@@ -19,9 +19,9 @@ type SystemInfo struct {
 }
 
 type DiskUsage struct {
-	Total uint64
-	Free  uint64
-	Used  uint64
+	Total uint64 `json:"total"`
+	Free  uint64 `json:"free"`
+	Used  uint64 `json:"used"`
 }
 
 func GetSystemInfo() (*SystemInfo, error) {
@@ -85,7 +85,17 @@ func (cd *ConfirmDialog) Render() string {
 		Render(dialogContent)
 }
 
+// wrapText wraps every line of text on its own, so that messages can list
+// things one per line.
 func (cd *ConfirmDialog) wrapText(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, cd.wrapLine(line, width)...)
+	}
+	return lines
+}
+
+func (cd *ConfirmDialog) wrapLine(text string, width int) []string {
 	if len(text) <= width {
 		return []string{text}
 	}
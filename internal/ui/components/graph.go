@@ -0,0 +1,368 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/admiller/ltop/internal/ui/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type GraphMode int
+
+const (
+	// GraphBraille plots points as braille dots, 2x4 per cell, joined into
+	// lines.
+	GraphBraille GraphMode = iota
+	// GraphBlock fills each column up to its value with block characters.
+	GraphBlock
+)
+
+var blockLevels = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// Braille dot bits, indexed by [row][column] within a cell.
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+type GraphSeries struct {
+	Label  string
+	Values []float64
+	Style  lipgloss.Style
+}
+
+// Graph draws one or more series over time, newest sample on the right.
+// Width and Height include the axis labels and legend.
+type Graph struct {
+	Width      int
+	Height     int
+	Mode       GraphMode
+	Min        float64
+	Max        float64
+	AutoScale  bool
+	ShowAxis   bool
+	ShowLegend bool
+	// FormatValue formats the axis labels; defaults to %.0f.
+	FormatValue func(float64) string
+	// StartLabel and EndLabel are drawn under the left and right end of
+	// the plot, e.g. "-60s" and "now".
+	StartLabel string
+	EndLabel   string
+	Series     []GraphSeries
+}
+
+func NewGraph(width, height int) *Graph {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return &Graph{
+		Width:      width,
+		Height:     height,
+		Mode:       GraphBraille,
+		Max:        100,
+		AutoScale:  true,
+		ShowAxis:   true,
+		ShowLegend: true,
+	}
+}
+
+// AddSeries adds a series; a zero style picks the next color of the theme.
+func (g *Graph) AddSeries(label string, values []float64, style lipgloss.Style) {
+	if style.GetForeground() == (lipgloss.NoColor{}) {
+		style = styles.GraphSeries(len(g.Series))
+	}
+	g.Series = append(g.Series, GraphSeries{
+		Label:  label,
+		Values: values,
+		Style:  style,
+	})
+}
+
+func (g *Graph) Clear() {
+	g.Series = g.Series[:0]
+}
+
+// Bounds returns the value range the graph is drawn with.
+func (g *Graph) Bounds() (float64, float64) {
+	lo, hi := g.Min, g.Max
+	if g.AutoScale {
+		hi = lo
+		for _, s := range g.Series {
+			for _, v := range s.Values {
+				if v > hi {
+					hi = v
+				}
+			}
+		}
+		hi = niceCeil(hi)
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of ten so that axis
+// labels stay readable and the scale does not jitter with every sample.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if v <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func (g *Graph) format(v float64) string {
+	if g.FormatValue != nil {
+		return g.FormatValue(v)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+// axisWidth returns the width of the value axis for the current series.
+func (g *Graph) axisWidth() (int, string, string) {
+	if !g.ShowAxis {
+		return 0, "", ""
+	}
+	lo, hi := g.Bounds()
+	top, bottom := g.format(hi), g.format(lo)
+	width := lipgloss.Width(top)
+	if w := lipgloss.Width(bottom); w > width {
+		width = w
+	}
+	return width + 1, top, bottom // +1 for the tick
+}
+
+func (g *Graph) plotWidth() int {
+	axisWidth, _, _ := g.axisWidth()
+	if w := g.Width - axisWidth; w > 0 {
+		return w
+	}
+	return 1
+}
+
+// Samples returns how many of the most recent values of each series the
+// graph shows with its current series and settings.
+func (g *Graph) Samples() int {
+	if g.Mode == GraphBraille {
+		return g.plotWidth() * 2
+	}
+	return g.plotWidth()
+}
+
+func (g *Graph) Render() string {
+	lo, hi := g.Bounds()
+
+	plotHeight := g.Height
+	legend := ""
+	if g.ShowLegend && len(g.Series) > 0 {
+		legend = g.renderLegend()
+		plotHeight--
+	}
+	timeAxis := g.StartLabel != "" || g.EndLabel != ""
+	if timeAxis {
+		plotHeight--
+	}
+	if plotHeight < 1 {
+		plotHeight = 1
+	}
+
+	axisWidth, topLabel, bottomLabel := g.axisWidth()
+	plotWidth := g.plotWidth()
+
+	var rows []string
+	if g.Mode == GraphBlock {
+		rows = g.renderBlock(plotWidth, plotHeight, lo, hi)
+	} else {
+		rows = g.renderBraille(plotWidth, plotHeight, lo, hi)
+	}
+
+	if g.ShowAxis {
+		axis := styles.GraphAxis()
+		for i := range rows {
+			label := ""
+			tick := "│"
+			switch i {
+			case 0:
+				label, tick = topLabel, "┤"
+			case len(rows) - 1:
+				label, tick = bottomLabel, "┤"
+			}
+			pad := strings.Repeat(" ", axisWidth-1-lipgloss.Width(label))
+			rows[i] = axis.Render(pad+label+tick) + rows[i]
+		}
+	}
+
+	if timeAxis {
+		gap := plotWidth - lipgloss.Width(g.StartLabel) - lipgloss.Width(g.EndLabel)
+		if gap < 1 {
+			gap = 1
+		}
+		line := strings.Repeat(" ", axisWidth) + g.StartLabel + strings.Repeat(" ", gap) + g.EndLabel
+		rows = append(rows, styles.GraphAxis().Render(line))
+	}
+
+	if legend != "" {
+		rows = append(rows, legend)
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func (g *Graph) renderLegend() string {
+	var parts []string
+	for _, s := range g.Series {
+		if s.Label == "" {
+			continue
+		}
+		parts = append(parts, s.Style.Render("■")+" "+s.Label)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// tail returns the last n values, left-padded with NaN so that the newest
+// value lands in the last column.
+func tail(values []float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	if len(values) > n {
+		values = values[len(values)-n:]
+	}
+	copy(out[n-len(values):], values)
+	return out
+}
+
+func scale(v, lo, hi float64, steps int) int {
+	level := int(math.Round((v - lo) / (hi - lo) * float64(steps)))
+	if level < 0 {
+		level = 0
+	}
+	if level > steps {
+		level = steps
+	}
+	return level
+}
+
+func (g *Graph) renderBraille(width, height int, lo, hi float64) []string {
+	dotsX, dotsY := width*2, height*4
+	cells := make([][]rune, height)
+	owners := make([][]int, height)
+	for y := range cells {
+		cells[y] = make([]rune, width)
+		owners[y] = make([]int, width)
+		for x := range owners[y] {
+			owners[y][x] = -1
+		}
+	}
+
+	set := func(x, y, series int) {
+		// y counts dots from the bottom.
+		row := dotsY - 1 - y
+		cy, cx := row/4, x/2
+		cells[cy][cx] |= brailleDots[row%4][x%2]
+		owners[cy][cx] = series
+	}
+
+	for si, s := range g.Series {
+		values := tail(s.Values, dotsX)
+		prev := -1
+		for x, v := range values {
+			if math.IsNaN(v) {
+				prev = -1
+				continue
+			}
+			y := scale(v, lo, hi, dotsY-1)
+			from, to := y, y
+			if prev >= 0 {
+				// Join to the previous point so steep changes stay visible.
+				if prev < from {
+					from = prev + 1
+				} else if prev > to {
+					to = prev - 1
+				}
+			}
+			for dy := from; dy <= to; dy++ {
+				set(x, dy, si)
+			}
+			prev = y
+		}
+	}
+
+	rows := make([]string, height)
+	for y := range cells {
+		var b strings.Builder
+		for x, dots := range cells[y] {
+			if owners[y][x] < 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			b.WriteString(g.Series[owners[y][x]].Style.Render(string(0x2800 + dots)))
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+func (g *Graph) renderBlock(width, height int, lo, hi float64) []string {
+	steps := len(blockLevels) - 1
+	levels := make([][]int, height)
+	owners := make([][]int, height)
+	for y := range levels {
+		levels[y] = make([]int, width)
+		owners[y] = make([]int, width)
+		for x := range owners[y] {
+			owners[y][x] = -1
+		}
+	}
+
+	// Later series are drawn in front of earlier ones wherever they reach
+	// higher, like overlapping area charts.
+	for si, s := range g.Series {
+		for x, v := range tail(s.Values, width) {
+			if math.IsNaN(v) {
+				continue
+			}
+			filled := scale(v, lo, hi, height*steps)
+			for y := 0; y < height && filled > 0; y++ {
+				level := filled
+				if level > steps {
+					level = steps
+				}
+				filled -= level
+				row := height - 1 - y
+				if level >= levels[row][x] {
+					levels[row][x] = level
+					owners[row][x] = si
+				}
+			}
+		}
+	}
+
+	rows := make([]string, height)
+	for y := range levels {
+		var b strings.Builder
+		for x, level := range levels[y] {
+			if owners[y][x] < 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			b.WriteString(g.Series[owners[y][x]].Style.Render(string(blockLevels[level])))
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
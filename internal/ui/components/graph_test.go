@@ -0,0 +1,139 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestGraphBounds(t *testing.T) {
+	graph := NewGraph(20, 5)
+
+	testCases := []struct {
+		values   []float64
+		expected float64
+	}{
+		{[]float64{73}, 100},
+		{[]float64{3.2, 1}, 5},
+		{[]float64{1500}, 2000},
+		{[]float64{0}, 1},
+		{nil, 1},
+	}
+
+	for _, tc := range testCases {
+		graph.Clear()
+		graph.AddSeries("s", tc.values, lipgloss.NewStyle())
+		if _, hi := graph.Bounds(); hi != tc.expected {
+			t.Errorf("Values %v: expected max %.0f, got %.0f", tc.values, tc.expected, hi)
+		}
+	}
+
+	graph.AutoScale = false
+	graph.Max = 100
+	graph.Clear()
+	graph.AddSeries("s", []float64{250}, lipgloss.NewStyle())
+	if lo, hi := graph.Bounds(); lo != 0 || hi != 100 {
+		t.Errorf("Expected fixed bounds 0-100, got %.0f-%.0f", lo, hi)
+	}
+}
+
+func TestGraphRenderDimensions(t *testing.T) {
+	for _, mode := range []GraphMode{GraphBraille, GraphBlock} {
+		graph := NewGraph(30, 6)
+		graph.Mode = mode
+		graph.StartLabel = "-60s"
+		graph.EndLabel = "now"
+		graph.AddSeries("rx", []float64{1, 5, 10, 20, 5}, lipgloss.NewStyle())
+		graph.AddSeries("tx", []float64{2, 2, 2}, lipgloss.NewStyle())
+
+		lines := strings.Split(graph.Render(), "\n")
+		if len(lines) != 6 {
+			t.Fatalf("Mode %d: expected 6 lines, got %d", mode, len(lines))
+		}
+		for i, line := range lines[:4] {
+			if w := lipgloss.Width(line); w != 30 {
+				t.Errorf("Mode %d: line %d is %d wide, expected 30", mode, i, w)
+			}
+		}
+		if !strings.Contains(lines[0], "20") || !strings.Contains(lines[3], "0") {
+			t.Errorf("Mode %d: expected axis labels, got %q / %q", mode, lines[0], lines[3])
+		}
+		if !strings.Contains(lines[4], "-60s") || !strings.Contains(lines[4], "now") {
+			t.Errorf("Mode %d: expected time labels, got %q", mode, lines[4])
+		}
+		if !strings.Contains(lines[5], "rx") || !strings.Contains(lines[5], "tx") {
+			t.Errorf("Mode %d: expected legend, got %q", mode, lines[5])
+		}
+	}
+}
+
+func TestGraphBlockLevels(t *testing.T) {
+	graph := NewGraph(4, 2)
+	graph.Mode = GraphBlock
+	graph.ShowAxis = false
+	graph.ShowLegend = false
+	graph.AutoScale = false
+	graph.Max = 100
+	graph.AddSeries("", []float64{0, 50, 100}, lipgloss.NewStyle())
+
+	lines := strings.Split(graph.Render(), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	// Oldest column is empty padding; values are right-aligned.
+	if lines[0] != "   █" {
+		t.Errorf("Unexpected top row %q", lines[0])
+	}
+	if lines[1] != "  ██" {
+		t.Errorf("Unexpected bottom row %q", lines[1])
+	}
+}
+
+func TestGraphBrailleLine(t *testing.T) {
+	graph := NewGraph(2, 1)
+	graph.ShowAxis = false
+	graph.ShowLegend = false
+	graph.AutoScale = false
+	graph.Max = 3
+	graph.AddSeries("", []float64{0, 3, 3, 0}, lipgloss.NewStyle())
+
+	// Left cell: bottom-left dot, then the right column filled up to the
+	// top to join it. Right cell: top-left dot, then the right column
+	// filled from just below it down to the bottom.
+	expected := string(rune(0x2800|0x40|0x20|0x10|0x08)) +
+		string(rune(0x2800|0x01|0x10|0x20|0x80))
+	if out := graph.Render(); out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+}
+
+func TestGraphRenderNoPanic(t *testing.T) {
+	for _, mode := range []GraphMode{GraphBraille, GraphBlock} {
+		for _, size := range [][2]int{{0, 0}, {1, 1}, {3, 1}, {-5, -5}} {
+			graph := NewGraph(size[0], size[1])
+			graph.Mode = mode
+			graph.AddSeries("a", []float64{-10, 1e9, 5}, lipgloss.NewStyle())
+			_ = graph.Render()
+		}
+
+		graph := NewGraph(10, 3)
+		graph.Mode = mode
+		_ = graph.Render()
+	}
+}
+
+func TestGraphSamples(t *testing.T) {
+	graph := NewGraph(20, 4)
+	graph.AddSeries("s", []float64{50}, lipgloss.NewStyle())
+
+	// "50" and the tick take 3 columns, leaving 17 cells of 2 dots each.
+	if samples := graph.Samples(); samples != 34 {
+		t.Errorf("Expected 34 braille samples, got %d", samples)
+	}
+
+	graph.Mode = GraphBlock
+	if samples := graph.Samples(); samples != 17 {
+		t.Errorf("Expected 17 block samples, got %d", samples)
+	}
+}
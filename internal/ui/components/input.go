@@ -3,6 +3,8 @@ package components
 import (
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+
 	"github.com/admiller/ltop/internal/ui/styles"
 )
 
@@ -12,6 +14,8 @@ type TextInput struct {
 	Width       int
 	Focused     bool
 	CursorPos   int
+	// Error is shown after the value, for input that does not parse.
+	Error string
 }
 
 func NewTextInput(placeholder string, width int) *TextInput {
@@ -75,6 +79,11 @@ func (ti *TextInput) MoveCursorToEnd() {
 func (ti *TextInput) Clear() {
 	ti.Value = ""
 	ti.CursorPos = 0
+	ti.Error = ""
+}
+
+func (ti *TextInput) SetError(msg string) {
+	ti.Error = msg
 }
 
 func (ti *TextInput) Render() string {
@@ -113,6 +122,12 @@ func (ti *TextInput) Render() string {
 	if ti.Focused {
 		style = styles.Border()
 	}
+	if ti.Error != "" {
+		text += " " + styles.Error().Render(ti.Error)
+		if ti.Focused {
+			style = style.BorderForeground(lipgloss.Color(styles.DefaultTheme.Error))
+		}
+	}
 
 	return style.Render(text)
 }
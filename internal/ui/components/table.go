@@ -3,6 +3,7 @@ package components
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/admiller/ltop/internal/ui/styles"
 	"github.com/admiller/ltop/pkg/utils"
@@ -17,6 +18,11 @@ type Table struct {
 	Height     int
 	Scrollable bool
 	ScrollTop  int
+	// Widths fixes the width of columns; columns without one, or with a
+	// zero width, are sized to their content.
+	Widths []int
+	// Tagged rows, by index, are highlighted.
+	Tagged map[int]bool
 }
 
 func NewTable(headers []string) *Table {
@@ -110,6 +116,8 @@ func (t *Table) Render() string {
 		style := styles.TableRow()
 		if actualIndex == t.Selected {
 			style = styles.TableRowSelected()
+		} else if t.Tagged[actualIndex] {
+			style = styles.TableRowTagged()
 		}
 
 		renderedRow := t.renderRow(row, colWidths, style)
@@ -130,10 +138,15 @@ func (t *Table) calculateColumnWidths() []int {
 
 	totalContentWidth := 0
 	for i, header := range t.Headers {
-		maxWidth := len(header)
+		if i < len(t.Widths) && t.Widths[i] > 0 {
+			colWidths[i] = t.Widths[i]
+			availableWidth -= t.Widths[i]
+			continue
+		}
+		maxWidth := utf8.RuneCountInString(header)
 		for _, row := range t.Rows {
-			if i < len(row) && len(row[i]) > maxWidth {
-				maxWidth = len(row[i])
+			if i < len(row) && utf8.RuneCountInString(row[i]) > maxWidth {
+				maxWidth = utf8.RuneCountInString(row[i])
 			}
 		}
 		colWidths[i] = maxWidth
@@ -146,6 +159,9 @@ func (t *Table) calculateColumnWidths() []int {
 
 	ratio := float64(availableWidth) / float64(totalContentWidth)
 	for i := range colWidths {
+		if i < len(t.Widths) && t.Widths[i] > 0 {
+			continue
+		}
 		colWidths[i] = utils.Max(5, int(float64(colWidths[i])*ratio))
 	}
 
@@ -60,6 +60,13 @@ func TableRowSelected() lipgloss.Style {
 		Padding(0, 1)
 }
 
+func TableRowTagged() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(DefaultTheme.Warning)).
+		Bold(true).
+		Padding(0, 1)
+}
+
 func Gauge() lipgloss.Style {
 	return lipgloss.NewStyle().
 		Foreground(lipgloss.Color(DefaultTheme.Primary))
@@ -134,6 +141,25 @@ func NavigationTabActive() lipgloss.Style {
 		Bold(true)
 }
 
+func GraphAxis() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(DefaultTheme.Muted))
+}
+
+// GraphSeries returns the color of the i-th series of a graph.
+func GraphSeries(i int) lipgloss.Style {
+	palette := []string{
+		DefaultTheme.Primary,
+		DefaultTheme.Success,
+		DefaultTheme.Warning,
+		DefaultTheme.Info,
+		DefaultTheme.Error,
+		DefaultTheme.Secondary,
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(palette[i%len(palette)]))
+}
+
 func PercentageColor(percentage float64) lipgloss.Style {
 	if percentage < 50 {
 		return Success()
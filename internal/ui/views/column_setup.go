@@ -0,0 +1,229 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/ui/styles"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+// ColumnSetup edits the columns of the flat process list, like the setup
+// screen of htop. Shown columns are listed first in their order, followed
+// by the hidden ones.
+type ColumnSetup struct {
+	open    bool
+	columns []models.ColumnConfig
+	cursor  int
+	offset  int
+}
+
+func NewColumnSetup() *ColumnSetup {
+	return &ColumnSetup{}
+}
+
+func (cs *ColumnSetup) Open(columns []models.ColumnConfig) {
+	cs.open = true
+	cs.columns = normalizeColumns(columns)
+	cs.cursor = 0
+	cs.offset = 0
+}
+
+func (cs *ColumnSetup) Close() {
+	cs.open = false
+}
+
+func (cs *ColumnSetup) IsOpen() bool {
+	return cs.open
+}
+
+// Columns returns the edited layout.
+func (cs *ColumnSetup) Columns() []models.ColumnConfig {
+	return append([]models.ColumnConfig(nil), cs.columns...)
+}
+
+// entries lists every column, with the shown ones first.
+func (cs *ColumnSetup) entries() []models.ColumnConfig {
+	entries := append([]models.ColumnConfig(nil), cs.columns...)
+	for _, column := range processColumns {
+		if cs.shownIndex(column.name) < 0 {
+			entries = append(entries, models.ColumnConfig{Name: column.name})
+		}
+	}
+	return entries
+}
+
+func (cs *ColumnSetup) shownIndex(name string) int {
+	for i, column := range cs.columns {
+		if column.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (cs *ColumnSetup) MoveUp() {
+	cs.cursor = utils.Max(0, cs.cursor-1)
+}
+
+func (cs *ColumnSetup) MoveDown() {
+	cs.cursor = utils.Min(len(processColumns)-1, cs.cursor+1)
+}
+
+// Toggle shows or hides the column under the cursor. A column that is shown
+// is added last. The PID column is always shown.
+func (cs *ColumnSetup) Toggle() {
+	name := cs.entries()[cs.cursor].Name
+	if name == "pid" {
+		return
+	}
+	if i := cs.shownIndex(name); i >= 0 {
+		cs.columns = append(cs.columns[:i], cs.columns[i+1:]...)
+	} else {
+		cs.columns = append(cs.columns, models.ColumnConfig{Name: name})
+	}
+	for i, entry := range cs.entries() {
+		if entry.Name == name {
+			cs.cursor = i
+		}
+	}
+}
+
+// Shift moves the shown column under the cursor left (-1) or right (1) in
+// the process list.
+func (cs *ColumnSetup) Shift(step int) {
+	i := cs.cursor
+	j := i + step
+	if i >= len(cs.columns) || j < 1 || j >= len(cs.columns) || i == 0 {
+		return
+	}
+	cs.columns[i], cs.columns[j] = cs.columns[j], cs.columns[i]
+	cs.cursor = j
+}
+
+// Resize widens or narrows the shown column under the cursor. A column
+// sized to its content starts from the width of its header.
+func (cs *ColumnSetup) Resize(delta int) {
+	if cs.cursor >= len(cs.columns) {
+		return
+	}
+	column := &cs.columns[cs.cursor]
+	info, _ := processColumnByName(column.Name)
+	minWidth := utf8.RuneCountInString(info.header) + 1
+	width := column.Width
+	if width == 0 {
+		width = minWidth
+	}
+	column.Width = utils.Max(minWidth, width+delta)
+}
+
+// AutoWidth sizes the column under the cursor to its content again.
+func (cs *ColumnSetup) AutoWidth() {
+	if cs.cursor < len(cs.columns) {
+		cs.columns[cs.cursor].Width = 0
+	}
+}
+
+// Reset restores the default columns.
+func (cs *ColumnSetup) Reset() {
+	cs.columns = normalizeColumns(nil)
+	cs.cursor = 0
+}
+
+func (cs *ColumnSetup) Render(width, height int) string {
+	lines := []string{
+		styles.Title().Render("Process Columns"),
+		styles.Muted().Render("Space=show/hide, J/K=move down/up, +/-=width, a=auto width, D=defaults, Esc=save and close"),
+		"",
+	}
+
+	entries := cs.entries()
+	visible := utils.Max(1, height-len(lines))
+	if cs.cursor < cs.offset {
+		cs.offset = cs.cursor
+	} else if cs.cursor >= cs.offset+visible {
+		cs.offset = cs.cursor - visible + 1
+	}
+
+	for i := cs.offset; i < len(entries) && i < cs.offset+visible; i++ {
+		entry := entries[i]
+		info, _ := processColumnByName(entry.Name)
+		mark, size := "[ ]", ""
+		if i < len(cs.columns) {
+			mark, size = "[x]", "auto"
+			if entry.Width > 0 {
+				size = fmt.Sprintf("%d", entry.Width)
+			}
+		}
+		line := fmt.Sprintf(" %s %-10s %-8s %s", mark, info.header, entry.Name, size)
+		if entry.Name == "pid" {
+			line += "  (always first)"
+		}
+		line = utils.PadString(line, utils.Max(0, width-6), ' ')
+		style := styles.TableRow()
+		if i == cs.cursor {
+			style = styles.TableRowSelected()
+		}
+		line = style.Render(line)
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ShowColumnSetup opens the column setup screen in place of the list.
+func (pv *ProcessView) ShowColumnSetup() {
+	pv.CloseDrillDown()
+	pv.columnSetup.Open(pv.columns)
+}
+
+func (pv *ProcessView) IsSettingUpColumns() bool {
+	return pv.columnSetup.IsOpen()
+}
+
+func (pv *ProcessView) ColumnSetup() *ColumnSetup {
+	return pv.columnSetup
+}
+
+// CloseColumnSetup applies the edited columns and returns them for saving.
+func (pv *ProcessView) CloseColumnSetup() []models.ColumnConfig {
+	pv.columnSetup.Close()
+	pv.SetColumns(pv.columnSetup.Columns())
+	return pv.Columns()
+}
+
+func (m Model) updateColumnSetup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	setup := m.processView.ColumnSetup()
+	switch msg.String() {
+	case "up", "k":
+		setup.MoveUp()
+	case "down", "j":
+		setup.MoveDown()
+	case " ", "enter":
+		setup.Toggle()
+	case "K", "shift+up":
+		setup.Shift(-1)
+	case "J", "shift+down":
+		setup.Shift(1)
+	case "+", "right":
+		setup.Resize(1)
+	case "-", "left":
+		setup.Resize(-1)
+	case "a":
+		setup.AutoWidth()
+	case "D":
+		setup.Reset()
+	case "esc", "C":
+		columns := m.processView.CloseColumnSetup()
+		err := m.app.SaveConfigChange(func(config *models.SystemConfig) {
+			config.ProcessColumns = columns
+		})
+		if err != nil {
+			m.err = fmt.Errorf("failed to save the columns: %w", err)
+		}
+	}
+	return m, nil
+}
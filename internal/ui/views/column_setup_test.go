@@ -0,0 +1,93 @@
+package views
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func TestColumnSetupToggle(t *testing.T) {
+	cs := NewColumnSetup()
+	cs.Open([]models.ColumnConfig{{Name: "pid"}, {Name: "name"}, {Name: "cpu"}})
+
+	// The PID column cannot be hidden.
+	cs.Toggle()
+	if got := columnNames(cs.Columns()); !reflect.DeepEqual(got, []string{"pid", "name", "cpu"}) {
+		t.Errorf("Expected pid to stay shown, got %v", got)
+	}
+
+	// Hiding a column keeps the cursor on it among the hidden ones.
+	cs.cursor = 1
+	cs.Toggle()
+	if got := columnNames(cs.Columns()); !reflect.DeepEqual(got, []string{"pid", "cpu"}) {
+		t.Errorf("Expected name hidden, got %v", got)
+	}
+	if entry := cs.entries()[cs.cursor]; entry.Name != "name" {
+		t.Errorf("Expected the cursor on name, got %s", entry.Name)
+	}
+
+	// Showing it again adds it last.
+	cs.Toggle()
+	if got := columnNames(cs.Columns()); !reflect.DeepEqual(got, []string{"pid", "cpu", "name"}) {
+		t.Errorf("Expected name shown last, got %v", got)
+	}
+	if cs.cursor != 2 {
+		t.Errorf("Expected the cursor to follow name, got %d", cs.cursor)
+	}
+}
+
+func TestColumnSetupShift(t *testing.T) {
+	tests := []struct {
+		cursor int
+		step   int
+		want   []string
+	}{
+		{1, 1, []string{"pid", "cpu", "name", "time"}},
+		{2, -1, []string{"pid", "cpu", "name", "time"}},
+		{3, 1, []string{"pid", "name", "cpu", "time"}},
+		// pid cannot be moved, nor can another column move before it.
+		{0, 1, []string{"pid", "name", "cpu", "time"}},
+		{1, -1, []string{"pid", "name", "cpu", "time"}},
+		// Hidden columns are not moved.
+		{5, -1, []string{"pid", "name", "cpu", "time"}},
+	}
+	for _, test := range tests {
+		cs := NewColumnSetup()
+		cs.Open([]models.ColumnConfig{{Name: "pid"}, {Name: "name"}, {Name: "cpu"}, {Name: "time"}})
+		cs.cursor = test.cursor
+		cs.Shift(test.step)
+		if got := columnNames(cs.Columns()); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Shift(%d) at %d: got %v, want %v", test.step, test.cursor, got, test.want)
+		}
+	}
+}
+
+func TestColumnSetupResize(t *testing.T) {
+	cs := NewColumnSetup()
+	cs.Open([]models.ColumnConfig{{Name: "pid"}, {Name: "name"}})
+
+	// An automatic column starts from its header, NAME, and a space.
+	cs.cursor = 1
+	cs.Resize(2)
+	if width := cs.Columns()[1].Width; width != 7 {
+		t.Errorf("Expected width 7, got %d", width)
+	}
+	cs.Resize(-10)
+	if width := cs.Columns()[1].Width; width != 5 {
+		t.Errorf("Expected the width clamped to 5, got %d", width)
+	}
+	cs.AutoWidth()
+	if width := cs.Columns()[1].Width; width != 0 {
+		t.Errorf("Expected an automatic width, got %d", width)
+	}
+
+	// Hidden columns have no width.
+	cs.cursor = 3
+	cs.Resize(1)
+	for _, column := range cs.Columns() {
+		if column.Width != 0 {
+			t.Errorf("Expected no column resized, got %v", cs.Columns())
+		}
+	}
+}
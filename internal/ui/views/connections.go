@@ -0,0 +1,205 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/internal/ui/styles"
+	"github.com/admiller/ltop/pkg/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var connectionHeaders = []string{"PROTO", "STATE", "LOCAL", "REMOTE", "SEND-Q", "RECV-Q", "PID", "PROCESS"}
+
+// ConnectionsView is a socket table like ss -tuxp. Sockets are not part of
+// snapshots, so they are read while the view is shown and only from a live
+// system.
+type ConnectionsView struct {
+	table       *components.Table
+	filterInput *components.TextInput
+	filtering   bool
+	collector   *collectors.ConnectionCollector
+	sockets     []models.Socket
+	shown       []models.Socket
+	err         error
+	// refreshing is set while a RefreshCmd runs.
+	refreshing bool
+}
+
+// ConnectionsMsg carries the sockets read by RefreshCmd.
+type ConnectionsMsg struct {
+	Sockets []models.Socket
+	Err     error
+}
+
+func NewConnectionsView() *ConnectionsView {
+	return &ConnectionsView{
+		table:       components.NewTable(connectionHeaders),
+		filterInput: components.NewTextInput("Filter connections...", 40),
+	}
+}
+
+// SetSource sets where sockets are read from. Without a source, as during
+// replay, the view only explains why it is empty.
+func (cv *ConnectionsView) SetSource(src *system.Source) {
+	cv.collector = nil
+	if src != nil {
+		cv.collector = collectors.NewConnectionCollector(src)
+	}
+}
+
+// RefreshCmd rereads the sockets in the background, since that reads the
+// fd links of every process. It returns nil while a refresh is running.
+func (cv *ConnectionsView) RefreshCmd() tea.Cmd {
+	if cv.collector == nil || cv.refreshing {
+		return nil
+	}
+	cv.refreshing = true
+	collector := cv.collector
+	return func() tea.Msg {
+		sockets, err := collector.Collect()
+		return ConnectionsMsg{Sockets: sockets, Err: err}
+	}
+}
+
+// SetSockets shows the sockets read by RefreshCmd.
+func (cv *ConnectionsView) SetSockets(msg ConnectionsMsg) {
+	cv.refreshing = false
+	cv.sockets, cv.err = msg.Sockets, msg.Err
+	cv.updateRows()
+}
+
+func (cv *ConnectionsView) updateRows() {
+	cv.shown = cv.filterSockets(cv.sockets)
+
+	rows := make([][]string, 0, len(cv.shown))
+	for _, s := range cv.shown {
+		pid := ""
+		if s.PID > 0 {
+			pid = strconv.Itoa(s.PID)
+		}
+		rows = append(rows, []string{
+			s.Protocol, s.State, s.Local(), s.Remote(),
+			strconv.FormatUint(s.TxQueue, 10), strconv.FormatUint(s.RxQueue, 10),
+			pid, s.Process,
+		})
+	}
+	cv.table.Rows = rows
+	if cv.table.Selected >= len(rows) {
+		cv.table.SetSelected(utils.Max(0, len(rows)-1))
+	}
+}
+
+// filterSockets keeps the sockets matching every word of the filter. A
+// number, optionally after a colon, matches the local or remote port; any
+// other word matches the protocol, state, addresses or process.
+func (cv *ConnectionsView) filterSockets(sockets []models.Socket) []models.Socket {
+	words := strings.Fields(strings.ToLower(cv.filterInput.GetValue()))
+	if len(words) == 0 {
+		return sockets
+	}
+
+	var filtered []models.Socket
+	for _, s := range sockets {
+		text := strings.ToLower(strings.Join([]string{s.Protocol, s.State, s.Local(), s.Remote(), s.Process}, " "))
+		matches := true
+		for _, word := range words {
+			if port, err := strconv.Atoi(strings.TrimPrefix(word, ":")); err == nil {
+				matches = s.Protocol != "unix" && (s.LocalPort == port || s.RemotePort == port)
+			} else {
+				matches = strings.Contains(text, word)
+			}
+			if !matches {
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+func (cv *ConnectionsView) Render(snapshot *models.MetricsSnapshot, width, height int) string {
+	var content string
+	switch {
+	case cv.collector == nil:
+		content = styles.Muted().Render("Connections are read live from /proc and are not part of recordings")
+	case cv.err != nil:
+		content = styles.Error().Render(cv.err.Error())
+	default:
+		content = cv.renderTable(width, height)
+	}
+	return styles.Panel().Width(width).Height(height).Render(content)
+}
+
+func (cv *ConnectionsView) renderTable(width, height int) string {
+	summary := fmt.Sprintf("%d of %d sockets", len(cv.shown), len(cv.sockets))
+	if cv.filtering || !cv.filterInput.IsEmpty() {
+		summary += " | filter: " + cv.filterInput.GetValue()
+		if cv.filtering {
+			summary += "_"
+		}
+	}
+	cv.table.SetSize(width, height-1)
+	return styles.Info().Render(summary) + "\n" + cv.table.Render()
+}
+
+func (cv *ConnectionsView) MoveUp() {
+	cv.table.MoveUp()
+}
+
+func (cv *ConnectionsView) MoveDown() {
+	cv.table.MoveDown()
+}
+
+func (cv *ConnectionsView) PageUp() {
+	cv.table.PageUp()
+}
+
+func (cv *ConnectionsView) PageDown() {
+	cv.table.PageDown()
+}
+
+func (cv *ConnectionsView) StartFilter() {
+	cv.filtering = true
+	cv.filterInput.Focus()
+}
+
+func (cv *ConnectionsView) StopFilter() {
+	cv.filtering = false
+	cv.filterInput.Blur()
+}
+
+func (cv *ConnectionsView) IsFiltering() bool {
+	return cv.filtering
+}
+
+func (cv *ConnectionsView) HandleFilterInput(ch rune) {
+	cv.filterInput.InsertChar(ch)
+	cv.updateRows()
+}
+
+func (cv *ConnectionsView) HandleFilterBackspace() {
+	cv.filterInput.DeleteChar()
+	cv.updateRows()
+}
+
+func (cv *ConnectionsView) ClearFilter() {
+	cv.filterInput.Clear()
+	cv.updateRows()
+}
+
+// SelectedPID is the owner of the selected socket, or 0 if unknown.
+func (cv *ConnectionsView) SelectedPID() int {
+	index := cv.table.GetSelectedIndex()
+	if index < 0 || index >= len(cv.shown) {
+		return 0
+	}
+	return cv.shown[index].PID
+}
@@ -4,24 +4,78 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/admiller/ltop/internal/app"
 	"github.com/admiller/ltop/internal/models"
 	"github.com/admiller/ltop/internal/ui/components"
 	"github.com/admiller/ltop/internal/ui/styles"
 	"github.com/admiller/ltop/pkg/utils"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type CPUView struct {
-	overallGauge *components.Gauge
-	coreGauges   []*components.Gauge
+	overallGauge *components.MultiGauge
+	coreGauges   []*components.MultiGauge
+	history      *app.History
 }
 
 func NewCPUView() *CPUView {
 	return &CPUView{
-		overallGauge: components.NewGauge(40),
-		coreGauges:   make([]*components.Gauge, 0),
+		overallGauge: components.NewMultiGauge(40),
+		coreGauges:   make([]*components.MultiGauge, 0),
 	}
 }
 
+// cpuStates are the CPU time states stacked in the usage bars, in the order
+// htop draws them. Idle time is left empty.
+var cpuStates = []struct {
+	name  string
+	value func(models.CPUBreakdown) float64
+	style func() lipgloss.Style
+}{
+	{"nice", func(b models.CPUBreakdown) float64 { return b.Nice }, styles.Info},
+	{"user", func(b models.CPUBreakdown) float64 { return b.User }, styles.Success},
+	{"system", func(b models.CPUBreakdown) float64 { return b.System }, styles.Error},
+	{"irq", func(b models.CPUBreakdown) float64 { return b.IRQ }, styles.Warning},
+	{"softirq", func(b models.CPUBreakdown) float64 { return b.SoftIRQ }, func() lipgloss.Style { return styles.GraphSeries(5) }},
+	{"steal", func(b models.CPUBreakdown) float64 { return b.Steal }, func() lipgloss.Style { return styles.GraphSeries(0) }},
+	{"guest", func(b models.CPUBreakdown) float64 { return b.Guest }, styles.Base},
+	{"iowait", func(b models.CPUBreakdown) float64 { return b.IOWait }, styles.Muted},
+}
+
+// renderBreakdown renders a usage bar with a segment per CPU time state,
+// followed by the usage.
+func renderBreakdown(gauge *components.MultiGauge, label string, usage float64, breakdown models.CPUBreakdown) string {
+	gauge.Clear()
+	for _, state := range cpuStates {
+		// Hundredths of a percent keep the segments accurate to a cell.
+		gauge.AddSegment(uint64(state.value(breakdown)*100), state.name, state.style())
+	}
+	return fmt.Sprintf("%s: %s %5.1f%%", label, gauge.Render(100*100), usage)
+}
+
+// renderBreakdownLegend lists the share of each CPU time state in the colors
+// of the bars, wrapped to width.
+func renderBreakdownLegend(breakdown models.CPUBreakdown, width int) string {
+	var lines []string
+	line := ""
+	for _, state := range cpuStates {
+		entry := state.style().Render(state.name) + " " + utils.FormatPercent(state.value(breakdown))
+		if line != "" && lipgloss.Width(line)+2+lipgloss.Width(entry) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += "  "
+		}
+		line += entry
+	}
+	return strings.Join(append(lines, line), "\n")
+}
+
+func (cv *CPUView) SetHistory(history *app.History) {
+	cv.history = history
+}
+
 func (cv *CPUView) Render(snapshot *models.MetricsSnapshot, width, height int) string {
 	if snapshot == nil {
 		return "No data available"
@@ -30,7 +84,7 @@ func (cv *CPUView) Render(snapshot *models.MetricsSnapshot, width, height int) s
 	var sections []string
 
 	// Always include overall CPU section (takes ~6 lines)
-	overallSection := cv.renderOverallCPU(snapshot)
+	overallSection := cv.renderOverallCPU(snapshot, width)
 	sections = append(sections, overallSection)
 
 	// Calculate remaining height for cores and info sections
@@ -38,6 +92,12 @@ func (cv *CPUView) Render(snapshot *models.MetricsSnapshot, width, height int) s
 	usedHeight := strings.Count(overallSection, "\n") + 1 + 4 // +4 for padding and separators
 	remainingHeight := height - usedHeight
 
+	if cv.history != nil && remainingHeight >= graphHeight+6 {
+		historySection := cv.renderHistory(width)
+		sections = append(sections, historySection)
+		remainingHeight -= strings.Count(historySection, "\n") + 1 + 2
+	}
+
 	// Include CPU cores section if there's enough space (needs at least 4 lines)
 	if remainingHeight >= 4 {
 		coresSection := cv.renderCPUCores(snapshot, width)
@@ -56,12 +116,13 @@ func (cv *CPUView) Render(snapshot *models.MetricsSnapshot, width, height int) s
 	return styles.Panel().Width(width).Height(height).Render(content)
 }
 
-func (cv *CPUView) renderOverallCPU(snapshot *models.MetricsSnapshot) string {
+func (cv *CPUView) renderOverallCPU(snapshot *models.MetricsSnapshot, width int) string {
 	var info []string
 	info = append(info, styles.Title().Render("CPU Usage"))
 
-	overallGauge := cv.overallGauge.Render(snapshot.CPU.Usage, "Overall")
+	overallGauge := renderBreakdown(cv.overallGauge, "Overall", snapshot.CPU.Usage, snapshot.CPU.Breakdown)
 	info = append(info, overallGauge)
+	info = append(info, renderBreakdownLegend(snapshot.CPU.Breakdown, width-4))
 
 	loadAvg := utils.FormatLoadAverage(snapshot.CPU.LoadAverage)
 	info = append(info, fmt.Sprintf("Load Average: %s", styles.Info().Render(loadAvg)))
@@ -80,6 +141,17 @@ func (cv *CPUView) renderOverallCPU(snapshot *models.MetricsSnapshot) string {
 	return strings.Join(info, "\n")
 }
 
+func (cv *CPUView) renderHistory(width int) string {
+	graph, samples := newHistoryGraph(width, components.GraphBraille)
+	graph.AutoScale = false
+	graph.Max = 100
+	graph.ShowLegend = false
+	graph.FormatValue = func(v float64) string { return fmt.Sprintf("%.0f%%", v) }
+	graph.AddSeries("total", historyValues(cv.history, samples, app.SeriesCPUTotal), styles.Gauge())
+
+	return styles.Title().Render("CPU History") + "\n" + renderHistoryGraph(cv.history, graph)
+}
+
 func (cv *CPUView) renderCPUCores(snapshot *models.MetricsSnapshot, width int) string {
 	var cores []string
 	cores = append(cores, styles.Title().Render("Per-Core Usage"))
@@ -111,7 +183,7 @@ func (cv *CPUView) renderCPUCores(snapshot *models.MetricsSnapshot, width int) s
 
 	// Ensure we have enough gauges for all cores
 	for len(cv.coreGauges) < len(snapshot.CPU.Cores) {
-		cv.coreGauges = append(cv.coreGauges, components.NewGauge(30))
+		cv.coreGauges = append(cv.coreGauges, components.NewMultiGauge(28))
 	}
 
 	// Prepare core data organized by rows and columns
@@ -127,7 +199,7 @@ func (cv *CPUView) renderCPUCores(snapshot *models.MetricsSnapshot, width int) s
 		
 		// Create gauge
 		label := fmt.Sprintf("Core %d", core.ID)
-		gaugeStr := cv.coreGauges[i].Render(core.Usage, label)
+		gaugeStr := renderBreakdown(cv.coreGauges[i], label, core.Usage, core.Breakdown)
 		
 		// Create frequency string
 		freqValue := ""
@@ -157,7 +229,11 @@ func (cv *CPUView) renderCPUCores(snapshot *models.MetricsSnapshot, width int) s
 			if coreIndex < len(coreEntries) {
 				// Split the core entry back into gauge and freq lines
 				lines := strings.Split(coreEntries[coreIndex], "\n")
-				gaugeStr := utils.PadString(utils.TruncateString(lines[0], actualColumnWidth), actualColumnWidth, ' ')
+				// The bar is styled, so cut and pad it by its visible width.
+				gaugeStr := lipgloss.NewStyle().MaxWidth(actualColumnWidth).Render(lines[0])
+				if w := lipgloss.Width(gaugeStr); w < actualColumnWidth {
+					gaugeStr += strings.Repeat(" ", actualColumnWidth-w)
+				}
 				freqStr := utils.PadString(utils.TruncateString(lines[1], actualColumnWidth), actualColumnWidth, ' ')
 				
 				gaugeRow = append(gaugeRow, gaugeStr)
@@ -0,0 +1,108 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+func newFilterDialog() *components.InputDialog {
+	dialog := components.NewInputDialog("", "", "name")
+	dialog.Width = 70
+	return dialog
+}
+
+// showSaveFilter asks for the name to save the applied process filter
+// under.
+func (m Model) showSaveFilter() Model {
+	filter := m.processView.Filter()
+	if filter == "" {
+		m.err = fmt.Errorf("there is no filter to save, enter one with /")
+		return m
+	}
+	m.filterDialog.Title = "Save Filter"
+	m.filterDialog.Message = "Save " + filter + "\nunder a new or existing name"
+	m.savingFilter = true
+	m.filterDialog.Show()
+	return m
+}
+
+// showLoadFilter asks which saved filter to apply.
+func (m Model) showLoadFilter() Model {
+	filters := m.app.GetConfig().ProcessFilters
+	if len(filters) == 0 {
+		m.err = fmt.Errorf("there are no saved filters, save one with S")
+		return m
+	}
+
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := []string{"Saved filters:"}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %-16s %s", utils.TruncateString(name, 16), utils.TruncateString(filters[name], 48)))
+	}
+
+	m.filterDialog.Title = "Load Filter"
+	m.filterDialog.Message = strings.Join(lines, "\n")
+	m.savingFilter = false
+	m.filterDialog.Show()
+	return m
+}
+
+func (m Model) updateFilterDialog(msg tea.KeyMsg) Model {
+	switch msg.String() {
+	case "enter":
+		m.filterDialog.Hide()
+		name := strings.TrimSpace(m.filterDialog.GetValue())
+		if m.savingFilter {
+			m.err = m.saveFilter(name)
+		} else {
+			m.err = m.loadFilter(name)
+		}
+	case "esc":
+		m.filterDialog.Hide()
+	case "backspace":
+		m.filterDialog.HandleBackspace()
+	default:
+		if len(msg.Runes) == 1 {
+			m.filterDialog.HandleInput(msg.Runes[0])
+		}
+	}
+	return m
+}
+
+func (m Model) saveFilter(name string) error {
+	if name == "" {
+		return fmt.Errorf("a saved filter needs a name")
+	}
+	filter := m.processView.Filter()
+	return m.app.SaveConfigChange(func(config *models.SystemConfig) {
+		// The map is shared with the app's copy of the config.
+		filters := make(map[string]string, len(config.ProcessFilters)+1)
+		for k, v := range config.ProcessFilters {
+			filters[k] = v
+		}
+		filters[name] = filter
+		config.ProcessFilters = filters
+	})
+}
+
+func (m Model) loadFilter(name string) error {
+	filter, ok := m.app.GetConfig().ProcessFilters[name]
+	if !ok {
+		return fmt.Errorf("no saved filter named %q", name)
+	}
+	if err := m.processView.SetFilter(filter); err != nil {
+		return fmt.Errorf("saved filter %q: %w", name, err)
+	}
+	return nil
+}
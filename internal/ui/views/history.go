@@ -0,0 +1,60 @@
+package views
+
+import (
+	"math"
+	"time"
+
+	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+const graphHeight = 8
+
+// historyValues returns up to n of the most recent values of each series,
+// summed sample by sample, aligned on the newest sample.
+func historyValues(history *app.History, n int, names ...string) []float64 {
+	if history == nil || n <= 0 {
+		return nil
+	}
+
+	window := time.Duration(n) * history.Resolution()
+	var sum []float64
+	for _, name := range names {
+		points := history.Window(name, window)
+		if len(points) > n {
+			points = points[len(points)-n:]
+		}
+		if len(points) > len(sum) {
+			grown := make([]float64, len(points))
+			copy(grown[len(points)-len(sum):], sum)
+			sum = grown
+		}
+		offset := len(sum) - len(points)
+		for i, p := range points {
+			sum[offset+i] += p.Value
+		}
+	}
+	return sum
+}
+
+// newHistoryGraph sizes a graph for a panel of the given width. It also
+// returns how many samples to fetch, which is at least as many as the graph
+// can show.
+func newHistoryGraph(width int, mode components.GraphMode) (*components.Graph, int) {
+	graph := components.NewGraph(width-6, graphHeight)
+	graph.Mode = mode
+	graph.EndLabel = "now"
+	return graph, graph.Width * 2
+}
+
+// renderHistoryGraph labels the time axis with the span actually shown.
+func renderHistoryGraph(history *app.History, graph *components.Graph) string {
+	span := time.Duration(graph.Samples()) * history.Resolution()
+	graph.StartLabel = "-" + utils.FormatDuration(span)
+	return graph.Render()
+}
+
+func formatRate(v float64) string {
+	return utils.FormatBytesPerSecond(math.Max(v, 0))
+}
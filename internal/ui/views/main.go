@@ -9,7 +9,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/audit"
 	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
 	"github.com/admiller/ltop/internal/ui/styles"
 	"github.com/admiller/ltop/pkg/utils"
 )
@@ -26,40 +29,84 @@ type Model struct {
 	networkView  *NetworkView
 	processView  *ProcessView
 	logView      *LogView
+	connView     *ConnectionsView
+	snapshots    <-chan *models.MetricsSnapshot
+	snapshot     *models.MetricsSnapshot
+	seekDialog   *components.InputDialog
+	filterDialog *components.InputDialog
 	lastUpdate   time.Time
 	showHelp     bool
 	err          error
+	// savingFilter tells whether filterDialog saves or loads a filter.
+	savingFilter bool
 }
 
-type TickMsg time.Time
+// SnapshotMsg delivers a snapshot published on the app's bus.
+type SnapshotMsg *models.MetricsSnapshot
 
+// NewModel subscribes to the app's bus for the lifetime of the program; the
+// subscription ends when the bus is closed.
 func NewModel(ltopApp *app.App) Model {
+	snapshots, _ := ltopApp.Bus().Subscribe()
+
+	cpuView := NewCPUView()
+	cpuView.SetHistory(ltopApp.History())
+	storageView := NewStorageView()
+	storageView.SetHistory(ltopApp.History())
+	networkView := NewNetworkView()
+	networkView.SetHistory(ltopApp.History())
+	processView := NewProcessView()
+	processView.SetSource(ltopApp.Source())
+	processView.SetColumns(ltopApp.GetConfig().ProcessColumns)
+	processView.SetPrivileges(system.DetectPrivileges(ltopApp.Source().Paths().ProcRoot))
+	processView.SetReadOnly(ltopApp.GetConfig().ReadOnly)
+	auditLog, err := audit.Open(ltopApp.GetConfig().AuditLog, maxActionHistory)
+	processView.SetAuditLog(auditLog)
+	connView := NewConnectionsView()
+	if ltopApp.Player() == nil {
+		connView.SetSource(ltopApp.Source())
+	}
+
 	return Model{
 		app:          ltopApp,
+		snapshots:    snapshots,
 		currentView:  models.ViewOverview,
 		overviewView: NewOverviewView(),
-		cpuView:      NewCPUView(),
+		cpuView:      cpuView,
 		memoryView:   NewMemoryView(),
-		storageView:  NewStorageView(),
-		networkView:  NewNetworkView(),
-		processView:  NewProcessView(),
+		storageView:  storageView,
+		networkView:  networkView,
+		processView:  processView,
 		logView:      NewLogView(),
+		connView:     connView,
+		seekDialog:   newSeekDialog(),
+		filterDialog: newFilterDialog(),
 		lastUpdate:   time.Now(),
+		err:          err,
 		showHelp:     false,
 	}
 }
 
+// SetReadOnly disables process actions without changing the config.
+func (m Model) SetReadOnly(readOnly bool) {
+	m.processView.SetReadOnly(readOnly)
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
-		tickCmd(),
+		waitForSnapshot(m.snapshots),
 		tea.EnterAltScreen,
 	)
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return TickMsg(t)
-	})
+func waitForSnapshot(snapshots <-chan *models.MetricsSnapshot) tea.Cmd {
+	return func() tea.Msg {
+		snapshot, ok := <-snapshots
+		if !ok {
+			return nil
+		}
+		return SnapshotMsg(snapshot)
+	}
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -70,6 +117,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.filterDialog.IsVisible() {
+			return m.updateFilterDialog(msg), nil
+		}
+		if m.capturesKeys() {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m.updateView(msg)
+		}
+		if player := m.app.Player(); player != nil {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			var handled bool
+			if m, handled = m.updateReplay(player, msg); handled {
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
@@ -77,16 +143,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showHelp = !m.showHelp
 			return m, nil
 		case "p":
-			m.app.TogglePause()
+			m.app.Bus().TogglePause()
 			return m, nil
 		case "r":
-			config := m.app.GetConfig()
-			if config.RefreshInterval == time.Second {
-				config.RefreshInterval = 5 * time.Second
+			// The process view uses r to resume processes.
+			if m.currentView == models.ViewProcesses {
+				break
+			}
+			bus := m.app.Bus()
+			if bus.Interval() == time.Second {
+				bus.SetInterval(5 * time.Second)
 			} else {
-				config.RefreshInterval = time.Second
+				bus.SetInterval(time.Second)
 			}
-			m.app.SetConfig(config)
 			return m, nil
 		case "T":
 			config := m.app.GetConfig()
@@ -118,21 +187,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "7":
 			m.currentView = models.ViewLogs
 			return m, nil
+		case "8":
+			m.currentView = models.ViewConnections
+			return m, m.connView.RefreshCmd()
 		}
 
-		switch m.currentView {
-		case models.ViewProcesses:
-			return m.updateProcessView(msg)
-		case models.ViewLogs:
-			return m.updateLogView(msg)
-		}
+		return m.updateView(msg)
 
-	case TickMsg:
-		if err := m.app.CollectMetrics(); err != nil {
-			m.err = err
+	case KillTreeMsg:
+		m.err = m.processView.ShowKillTreeResults(msg)
+		return m, nil
+
+	case ConnectionsMsg:
+		m.connView.SetSockets(msg)
+		return m, nil
+
+	case SnapshotMsg:
+		m.snapshot = msg
+		m.lastUpdate = msg.Timestamp
+		m.processView.Refresh()
+		if m.currentView == models.ViewConnections {
+			return m, tea.Batch(waitForSnapshot(m.snapshots), m.connView.RefreshCmd())
 		}
-		m.lastUpdate = time.Time(msg)
-		return m, tickCmd()
+		return m, waitForSnapshot(m.snapshots)
 
 	case error:
 		m.err = msg
@@ -142,11 +219,57 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// capturesKeys reports whether a text input or dialog of the current view
+// has the keyboard, in which case keys go to it before the global shortcuts.
+func (m Model) capturesKeys() bool {
+	switch m.currentView {
+	case models.ViewProcesses:
+		return m.processView.IsDialogActive() || m.processView.IsSearching()
+	case models.ViewConnections:
+		return m.connView.IsFiltering()
+	}
+	return false
+}
+
+// updateView passes a key to the current view.
+func (m Model) updateView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.currentView {
+	case models.ViewProcesses:
+		return m.updateProcessView(msg)
+	case models.ViewLogs:
+		return m.updateLogView(msg)
+	case models.ViewConnections:
+		return m.updateConnectionsView(msg)
+	}
+	return m, nil
+}
+
+// processActionsError explains why process actions are unavailable, if they
+// are.
+func (m Model) processActionsError() error {
+	switch {
+	case m.app.Player() != nil:
+		return fmt.Errorf("process actions are not available during replay")
+	case m.app.Fixture() != nil:
+		return fmt.Errorf("process actions are not available on a fixture")
+	case m.app.Source().Paths().ProcRoot != system.DefaultProcRoot:
+		// Signals and syscalls only reach the processes of the machine
+		// ltop runs on, not those listed under another proc root.
+		return fmt.Errorf("process actions are not available for another proc root")
+	case m.processView.IsReadOnly():
+		return system.ErrReadOnly
+	}
+	return nil
+}
+
 func (m Model) updateProcessView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.processView.IsDialogActive() {
 		if err := m.processView.HandleDialogInput(msg.String()); err != nil {
 			m.err = err
 		}
+		if tree := m.processView.TakeKillTree(); tree != nil {
+			return m, killTreeCmd(m.processView.processMgr, tree)
+		}
 		return m, nil
 	}
 
@@ -166,6 +289,103 @@ func (m Model) updateProcessView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.processView.IsSettingUpColumns() {
+		return m.updateColumnSetup(msg)
+	}
+
+	if m.processView.IsShowingSignalMenu() {
+		switch msg.String() {
+		case "up", "k":
+			m.processView.signalMenu.MoveUp()
+		case "down", "j":
+			m.processView.signalMenu.MoveDown()
+		case "pgup":
+			m.processView.signalMenu.PageUp()
+		case "pgdown":
+			m.processView.signalMenu.PageDown()
+		case "enter":
+			m.processView.ChooseSignal()
+		case "esc", "backspace":
+			m.processView.CloseSignalMenu()
+		}
+		return m, nil
+	}
+
+	if m.processView.IsShowingResults() {
+		switch msg.String() {
+		case "up", "k":
+			m.processView.MoveUp()
+		case "down", "j":
+			m.processView.MoveDown()
+		case "pgup":
+			m.processView.PageUp()
+		case "pgdown":
+			m.processView.PageDown()
+		case "esc", "enter", "backspace":
+			m.processView.CloseResults()
+		}
+		return m, nil
+	}
+
+	if m.processView.IsShowingHistory() {
+		switch msg.String() {
+		case "up", "k":
+			m.processView.MoveUp()
+		case "down", "j":
+			m.processView.MoveDown()
+		case "pgup":
+			m.processView.PageUp()
+		case "pgdown":
+			m.processView.PageDown()
+		case "esc", "enter", "backspace", "L":
+			m.processView.CloseHistory()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "delete", "d", "f", "z", "r", "P", "K", "D", "a", "O", "Y":
+		if err := m.processActionsError(); err != nil {
+			m.err = err
+			return m, nil
+		}
+	}
+	if m.app.Player() != nil {
+		switch msg.String() {
+		case "H", "enter", "l":
+			m.err = fmt.Errorf("threads, open files and process details are not recorded and cannot be replayed")
+			return m, nil
+		}
+	}
+
+	if m.processView.IsDrilledDown() {
+		switch msg.String() {
+		case "up", "k":
+			m.processView.MoveUp()
+		case "down", "j":
+			m.processView.MoveDown()
+		case "pgup":
+			m.processView.PageUp()
+		case "pgdown":
+			m.processView.PageDown()
+		case "esc", "backspace":
+			m.processView.CloseDrillDown()
+		case "H":
+			if m.processView.IsShowingThreads() {
+				m.processView.CloseDrillDown()
+			}
+		case "enter":
+			if m.processView.IsShowingDetails() {
+				m.processView.CloseDrillDown()
+			}
+		case "l":
+			if m.processView.IsShowingFiles() {
+				m.processView.CloseDrillDown()
+			}
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "up", "k":
 		m.processView.MoveUp()
@@ -185,6 +405,57 @@ func (m Model) updateProcessView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.processView.SetSortField("name")
 	case "t":
 		m.processView.SetSortField("time")
+	case "<":
+		m.processView.CycleSortColumn(-1)
+	case ">":
+		m.processView.CycleSortColumn(1)
+	case "C":
+		m.processView.ShowColumnSetup()
+	case "i":
+		m.processView.ToggleIOMode()
+		m = m.syncSmaps()
+	case "M":
+		m.processView.ToggleMemoryMode()
+		m = m.syncSmaps()
+	case "o":
+		if m.processView.IsMemoryMode() {
+			m.processView.CycleMemorySort()
+		} else {
+			m.processView.CycleIOSort()
+		}
+	case "v", "f5":
+		m.processView.ToggleTreeMode()
+		m = m.syncSmaps()
+	case "H":
+		if err := m.processView.ShowThreads(); err != nil {
+			m.err = err
+		}
+	case "enter":
+		if err := m.processView.ShowDetails(); err != nil {
+			m.err = err
+		}
+	case "l":
+		if err := m.processView.ShowFiles(); err != nil {
+			m.err = err
+		}
+	case "S":
+		m = m.showSaveFilter()
+	case "F":
+		m = m.showLoadFilter()
+	case " ":
+		m.processView.ToggleTag()
+	case "A":
+		m.processView.TagShown()
+	case "X":
+		m.processView.TagSubtree()
+	case "U":
+		m.processView.UntagAll()
+	case "+":
+		m.processView.Expand()
+	case "-":
+		m.processView.Collapse()
+	case "*":
+		m.processView.ExpandAll()
 	case "s":
 		m.processView.ToggleSortOrder()
 	case "delete", "d":
@@ -197,10 +468,28 @@ func (m Model) updateProcessView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.processView.ShowContinueDialog()
 	case "P":
 		m.processView.ShowNiceDialog()
+	case "K":
+		m.processView.ShowSignalMenu()
+	case "D":
+		m.processView.ShowKillTreeDialog()
+	case "a":
+		m.processView.ShowAffinityDialog()
+	case "O":
+		m.processView.ShowSchedulerDialog()
+	case "Y":
+		m.processView.ShowIOPriorityDialog()
+	case "L":
+		m.processView.ShowHistory()
 	}
 	return m, nil
 }
 
+// syncSmaps collects smaps_rollup while memory mode is shown.
+func (m Model) syncSmaps() Model {
+	m.app.SetProcessSmaps(m.processView.IsMemoryMode())
+	return m
+}
+
 func (m Model) updateLogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
@@ -225,6 +514,46 @@ func (m Model) updateLogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) updateConnectionsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.connView.IsFiltering() {
+		switch msg.String() {
+		case "enter", "esc":
+			m.connView.StopFilter()
+		case "backspace":
+			m.connView.HandleFilterBackspace()
+		default:
+			if len(msg.String()) == 1 {
+				m.connView.HandleFilterInput(rune(msg.String()[0]))
+			}
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.connView.MoveUp()
+	case "down", "j":
+		m.connView.MoveDown()
+	case "pgup":
+		m.connView.PageUp()
+	case "pgdown":
+		m.connView.PageDown()
+	case "/":
+		m.connView.StartFilter()
+	case "c":
+		m.connView.ClearFilter()
+	case "enter":
+		pid := m.connView.SelectedPID()
+		if pid == 0 {
+			m.err = fmt.Errorf("the owner of this socket is unknown")
+			return m, nil
+		}
+		m.processView.SelectPID(pid)
+		m.currentView = models.ViewProcesses
+	}
+	return m, nil
+}
+
 func (m Model) View() string {
 	if m.width == 0 || m.height == 0 {
 		return "Initializing..."
@@ -234,7 +563,7 @@ func (m Model) View() string {
 		return m.renderHelp()
 	}
 
-	snapshot := m.app.GetLastSnapshot()
+	snapshot := m.snapshot
 	header := m.renderHeader(snapshot)
 	footer := m.renderFooter()
 
@@ -252,6 +581,10 @@ func (m Model) View() string {
 			Width(m.width).
 			Align(lipgloss.Center, lipgloss.Center).
 			Render("Collecting system metrics...")
+	} else if m.seekDialog.IsVisible() {
+		content = lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Center, m.seekDialog.Render())
+	} else if m.filterDialog.IsVisible() {
+		content = lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Center, m.filterDialog.Render())
 	} else {
 		viewContent := m.renderView(snapshot, contentHeight)
 		content = lipgloss.NewStyle().Height(contentHeight).MaxHeight(contentHeight).Render(viewContent)
@@ -274,7 +607,10 @@ func (m Model) renderHeader(snapshot *models.MetricsSnapshot) string {
 			utils.FormatTime(snapshot.Timestamp),
 			snapshot.Overview.Hostname)
 
-		if m.app.GetState().Paused {
+		if player := m.app.Player(); player != nil {
+			status += replayStatus(player)
+		}
+		if m.app.Bus().Paused() {
 			status += " [PAUSED]"
 		}
 	}
@@ -308,6 +644,7 @@ func (m Model) renderTabs() string {
 		{"5", "Network", models.ViewNetwork},
 		{"6", "Processes", models.ViewProcesses},
 		{"7", "Logs", models.ViewLogs},
+		{"8", "Connections", models.ViewConnections},
 	}
 
 	var tabStrings []string
@@ -339,23 +676,57 @@ func (m Model) renderView(snapshot *models.MetricsSnapshot, height int) string {
 		return m.processView.Render(snapshot, m.width, height)
 	case models.ViewLogs:
 		return m.logView.Render(snapshot, m.width, height)
+	case models.ViewConnections:
+		return m.connView.Render(snapshot, m.width, height)
 	default:
 		return "Unknown view"
 	}
 }
 
 func (m Model) renderFooter() string {
-	helpText := "Press 'h' for help, 'q' to quit, 'p' to pause, or 1-7 to switch views"
+	helpText := "Press 'h' for help, 'q' to quit, 'p' to pause, or 1-8 to switch views"
+	if m.app.Player() != nil {
+		helpText = "Replay: p=pause, [/]=step, {/}=speed, g=go to time, 1-8=switch views, q=quit"
+	}
 	switch m.currentView {
 	case models.ViewLogs:
 		helpText = "Logs: a=auto-scroll, c=clear filters, e/w/i=filter by error/warn/info"
+	case models.ViewConnections:
+		if m.connView.IsFiltering() {
+			helpText = "Filter: state, protocol, port or text; Enter/Esc to apply"
+		} else {
+			helpText = "Connections: /=filter (e.g. listen :22), c=clear filter, Enter=go to owning process"
+		}
 	case models.ViewProcesses:
 		if m.processView.IsDialogActive() {
 			helpText = "Dialog: Enter=confirm, Esc=cancel, ←→=navigate"
 		} else if m.processView.IsSearching() {
-			helpText = "Search mode: Type to filter, Enter to apply, Esc to cancel"
+			helpText = "Filter: e.g. cpu>5 mem>200M user=postgres name~^worker, or/not/( ), Enter/Esc to close"
+		} else if m.processView.IsShowingSignalMenu() {
+			helpText = "Signals: ↑/↓=choose, Enter=send, Esc=cancel"
+		} else if m.processView.IsShowingResults() {
+			helpText = "Results: ↑/↓=move, Esc/Enter=back to processes"
+		} else if m.processView.IsShowingHistory() {
+			helpText = "Action history: ↑/↓=move, Esc/L=back to processes"
+		} else if m.processView.IsSettingUpColumns() {
+			helpText = "Columns: Space=show/hide, J/K=move, +/-=width, a=auto width, D=defaults, Esc=save"
+		} else if m.processView.IsShowingThreads() {
+			helpText = "Threads: ↑/↓=move, Esc/H=back to processes"
+		} else if m.processView.IsShowingDetails() {
+			helpText = "Details: ↑/↓=scroll, PgUp/PgDn=page, Esc/Enter=back to processes"
+		} else if m.processView.IsShowingFiles() {
+			helpText = "Open files: ↑/↓=move, Esc/l=back to processes"
+		} else if m.processView.IsTreeMode() {
+			helpText = "Tree: v=flat list, +/-=expand/collapse, *=expand all, /=search, c/m/n/t=sort siblings"
+			if m.processActionsError() == nil {
+				helpText += ", d=kill"
+			}
 		} else {
-			helpText = "Processes: /=search, d=kill, f=force kill, z=stop, r=resume, P=priority"
+			helpText = "Processes: Space=tag, A/X/U=tag shown/subtree/untag, /=filter, S/F=save/load filter, </>=sort column, C=columns, Enter=details, v=tree, H=threads, l=open files, i=IO mode, M=memory mode, o=mode sort"
+			if m.processActionsError() == nil {
+				helpText += ", d=kill, f=force kill, z=stop, r=resume, P=priority, K=signal, D=kill tree, a=affinity, O=policy, Y=I/O priority"
+			}
+			helpText += ", L=action history"
 		}
 	}
 	if m.err != nil {
@@ -369,24 +740,56 @@ func (m Model) renderHelp() string {
 ltop - Linux System Monitor Help
 
 Navigation:
-  1-7          Switch between views (Overview, CPU, Memory, Storage, Network, Processes, Logs)
+  1-8          Switch between views (Overview, CPU, Memory, Storage, Network, Processes, Logs, Connections)
   h, ?         Toggle this help screen
   q, Ctrl+C    Quit the application
   p            Pause/Resume updates
-  r            Toggle refresh rate (1s/5s)
+  r            Toggle refresh rate (1s/5s), except in the process view
   T            Toggle theme (dark/light)
 
 Process View (View 6):
   ↑/↓, k/j     Move selection up/down
   Page Up/Down Navigate by pages
-  /            Start search/filter
+  /            Filter, by a word or a query such as
+               cpu>5 mem>200M user=postgres name~^worker state=D
+               (see Process Filters below)
+  S / F        Save the filter under a name / load a saved filter
   c/m/n/t      Sort by CPU/Memory/Name/Time
+  < / >        Sort by the previous/next column
   s            Toggle sort order (asc/desc)
-  d            Kill selected process (SIGTERM)
-  f            Force kill selected process (SIGKILL)
-  z            Stop selected process (SIGSTOP)
-  r            Resume selected process (SIGCONT)
-  P            Change process priority (nice)
+  C            Set up columns: show, hide, reorder and size them (saved
+               to the config file)
+  i            Toggle IO mode (rank by disk read/write rate)
+  M            Toggle memory mode (PSS/USS/shared/swap from smaps_rollup)
+  o            Cycle the sort of IO mode (total/read/write) or memory mode (PSS/USS/swap)
+  v, F5        Toggle tree view (ΣCPU%/ΣMEMORY include descendants)
+  + / -        Expand/collapse the selected process in tree view
+  *            Expand every process in tree view
+  Enter        Show /proc details of the selected process (Esc to go back)
+  H            Show the threads of the selected process (Esc to go back)
+  l            Show the open files and sockets of the selected process
+  Space        Tag or untag the selected process
+  A / X / U    Tag every shown process / the selected subtree / untag all
+               (with tags, d/f/z/r/P apply to every tagged process)
+` + m.processActionHelp() + `  L            Show the history of process actions, which are also
+               appended to the audit_log file of the config, if set
+
+Process Filters:
+  Terms are FIELD OP VALUE with OP one of = != ~ !~ (regex) < <= > >=.
+  Terms next to each other must all match; combine them with and (&&),
+  or (||), not (!) and parentheses. Quote values with spaces.
+  Text:     name cmd user group state (letter or name, e.g. D or zombie)
+  Numbers:  pid ppid threads fds nice prio
+  Percent:  cpu mem%
+  Bytes:    mem (RSS) vms pss uss shared swap, e.g. 200M 1.5G
+  Rates:    read write io, e.g. 10M/s
+  Time:     time (CPU time), e.g. 90s 1h30m
+
+Replay (ltop replay FILE):
+  p            Pause/Resume playback
+  [ / ]        Step back/forward one snapshot
+  { / }        Slower/faster playback
+  g            Go to a time or offset (e.g. 03:12:00, -5m)
 
 Log View (View 7):
   ↑/↓, k/j     Move selection up/down
@@ -397,6 +800,14 @@ Log View (View 7):
   w            Filter by warning level
   i            Filter by info level
 
+Connections View (View 8):
+  ↑/↓, k/j     Move selection up/down
+  Page Up/Down Navigate by pages
+  /            Filter by words that must all match: a port (22 or :22),
+               a state or protocol (listen, tcp6) or any other text
+  c            Clear the filter
+  Enter        Go to the process owning the selected socket
+
 Views:
   1. Overview  - System summary with key metrics
   2. CPU       - Detailed CPU usage, load average, and per-core stats
@@ -405,10 +816,15 @@ Views:
   5. Network   - Network interface statistics and bandwidth
   6. Processes - Process list with CPU, memory, and details
   7. Logs      - System logs with filtering and real-time monitoring
+  8. Connections - TCP, UDP and Unix sockets with their owning processes
 
 Configuration:
   Config file: ~/.config/ltop/config.json
-  
+  Set "read_only": true or run ltop --read-only to disable process actions.
+
+Privileges:
+` + m.privilegeHelp() + `
+
 Tips:
   - All metrics update every second by default
   - Use pause (p) to freeze the display for detailed inspection
@@ -423,3 +839,36 @@ Press 'h' again to return to the monitoring view.
 		Height(m.height - 4).
 		Render(help)
 }
+
+// processActionHelp lists the process action keys, or why there are none.
+func (m Model) processActionHelp() string {
+	if err := m.processActionsError(); err != nil {
+		return "  (" + err.Error() + ")\n"
+	}
+	return `  d            Kill selected process (SIGTERM)
+  f            Force kill selected process (SIGKILL)
+  z            Stop selected process (SIGSTOP)
+  r            Resume selected process (SIGCONT)
+  P            Change process priority (nice)
+  K            Choose any signal to send from a list of every signal
+  D            Kill the selected process and all of its descendants: stop
+               them, send SIGTERM and report those still alive after 5s
+  a            Set the CPU affinity, as a CPU list such as 0-3,6
+  O            Set the scheduling policy: other, batch, idle, fifo N, rr N
+  Y            Set the I/O priority: none, idle, rt N, be N (0-7)
+`
+}
+
+// privilegeHelp explains what ltop cannot see or do as the user it runs as.
+func (m Model) privilegeHelp() string {
+	privileges := m.processView.Privileges()
+	limits := privileges.Limits()
+	if len(limits) == 0 {
+		return "  Running as " + privileges.User + " with full access to every process."
+	}
+	lines := []string{"  Running as " + privileges.User + ":"}
+	for _, limit := range limits {
+		lines = append(lines, "  - "+limit)
+	}
+	return strings.Join(lines, "\n")
+}
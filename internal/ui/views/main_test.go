@@ -0,0 +1,87 @@
+package views
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/fixture"
+	"github.com/admiller/ltop/internal/models"
+)
+
+func newTestModel(t *testing.T) Model {
+	t.Setenv("HOME", t.TempDir())
+	return NewModel(app.New())
+}
+
+// typeKeys sends text to the model one key at a time.
+func typeKeys(m Model, text string) Model {
+	for _, r := range text {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+		if r == ' ' {
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}}
+		}
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	return m
+}
+
+func TestSearchInputTakesKeys(t *testing.T) {
+	m := newTestModel(t)
+	m.currentView = models.ViewProcesses
+
+	m = typeKeys(m, "/cpu>5 user=postgres")
+	if got := m.processView.GetSearchQuery(); got != "cpu>5 user=postgres" {
+		t.Errorf("Expected the full query, got %q", got)
+	}
+	if m.currentView != models.ViewProcesses {
+		t.Errorf("Expected to stay in the process view, got %v", m.currentView)
+	}
+	if !m.processView.IsSearching() {
+		t.Error("Expected the search to stay open")
+	}
+}
+
+func TestConnectionsFilterTakesKeys(t *testing.T) {
+	m := newTestModel(t)
+	m.currentView = models.ViewConnections
+
+	m = typeKeys(m, "/listen :22")
+	if got := m.connView.filterInput.GetValue(); got != "listen :22" {
+		t.Errorf("Expected the full filter, got %q", got)
+	}
+	if m.currentView != models.ViewConnections {
+		t.Errorf("Expected to stay in the connections view, got %v", m.currentView)
+	}
+	if !m.connView.IsFiltering() {
+		t.Error("Expected the filter to stay open")
+	}
+}
+
+func TestProcessActionsError(t *testing.T) {
+	m := newTestModel(t)
+	if err := m.processActionsError(); err != nil {
+		t.Errorf("Expected actions on the live system, got %v", err)
+	}
+
+	config := m.app.GetConfig()
+	config.ProcRoot = t.TempDir()
+	m.app.SetConfig(config)
+	if err := m.processActionsError(); err == nil {
+		t.Error("Expected no actions for another proc root")
+	}
+
+	m = newTestModel(t)
+	replay, err := fixture.Open(filepath.Join("..", "..", "collectors", "testdata", "fixture"))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer func() { _ = replay.Close() }()
+	m.app.UseFixture(replay)
+	if err := m.processActionsError(); err == nil {
+		t.Error("Expected no actions on a fixture")
+	}
+}
@@ -4,17 +4,25 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/admiller/ltop/internal/app"
 	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/ui/components"
 	"github.com/admiller/ltop/internal/ui/styles"
 	"github.com/admiller/ltop/pkg/utils"
 )
 
-type NetworkView struct{}
+type NetworkView struct {
+	history *app.History
+}
 
 func NewNetworkView() *NetworkView {
 	return &NetworkView{}
 }
 
+func (nv *NetworkView) SetHistory(history *app.History) {
+	nv.history = history
+}
+
 func (nv *NetworkView) Render(snapshot *models.MetricsSnapshot, width, height int) string {
 	if snapshot == nil {
 		return "No data available"
@@ -22,12 +30,31 @@ func (nv *NetworkView) Render(snapshot *models.MetricsSnapshot, width, height in
 
 	var sections []string
 
-	sections = append(sections, nv.renderNetworkInterfaces(snapshot))
+	interfaces := nv.renderNetworkInterfaces(snapshot)
+	if nv.history != nil && height-strings.Count(interfaces, "\n") >= graphHeight+8 {
+		sections = append(sections, nv.renderHistory(snapshot, width))
+	}
+	sections = append(sections, interfaces)
 
 	content := strings.Join(sections, "\n\n")
 	return styles.Panel().Width(width).Height(height).Render(content)
 }
 
+func (nv *NetworkView) renderHistory(snapshot *models.MetricsSnapshot, width int) string {
+	var rx, tx []string
+	for _, iface := range snapshot.Network.Interfaces {
+		rx = append(rx, app.SeriesNetRx(iface.Name))
+		tx = append(tx, app.SeriesNetTx(iface.Name))
+	}
+
+	graph, samples := newHistoryGraph(width, components.GraphBraille)
+	graph.FormatValue = formatRate
+	graph.AddSeries("RX", historyValues(nv.history, samples, rx...), styles.Success())
+	graph.AddSeries("TX", historyValues(nv.history, samples, tx...), styles.Info())
+
+	return styles.Title().Render("Network Throughput") + "\n" + renderHistoryGraph(nv.history, graph)
+}
+
 func (nv *NetworkView) renderNetworkInterfaces(snapshot *models.MetricsSnapshot) string {
 	var network []string
 	network = append(network, styles.Title().Render("Network Interfaces"))
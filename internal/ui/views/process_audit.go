@@ -0,0 +1,113 @@
+package views
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/admiller/ltop/internal/audit"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/internal/ui/styles"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+// maxActionHistory is how many actions the history panel lists.
+const maxActionHistory = 500
+
+// SetAuditLog sets where process actions are recorded.
+func (pv *ProcessView) SetAuditLog(log *audit.Log) {
+	pv.audit = log
+}
+
+// actionValue is the signal sent or the value set by the current action.
+func (pv *ProcessView) actionValue() string {
+	switch pv.actionType {
+	case "kill", "kill_tree":
+		return "SIGTERM"
+	case "force_kill":
+		return "SIGKILL"
+	case "stop":
+		return "SIGSTOP"
+	case "continue":
+		return "SIGCONT"
+	case "signal":
+		return system.SignalName(pv.signal)
+	}
+	return strings.TrimSpace(pv.inputDialog.GetValue())
+}
+
+// auditActions records the results of the current action, one record per
+// process, with the process as it was in the last snapshot.
+func (pv *ProcessView) auditActions(results []actionResult) error {
+	if pv.audit == nil {
+		return nil
+	}
+	records := make([]audit.Record, 0, len(results))
+	for _, result := range results {
+		proc := pv.byPID[result.pid]
+		record := pv.audit.NewRecord(pv.actionType, pv.actionValue())
+		record.PID = result.pid
+		record.Command = proc.Command
+		if record.Command == "" {
+			record.Command = proc.Name
+		}
+		record.Owner = proc.User
+		record.SetResult(result.err)
+		if result.err == nil && result.survived {
+			record.Result = "survived"
+		} else if result.err == nil && result.exited {
+			record.Result = "exited"
+		}
+		records = append(records, record)
+	}
+	return pv.audit.Append(records...)
+}
+
+// ShowHistory lists the recent process actions, newest first.
+func (pv *ProcessView) ShowHistory() {
+	table := components.NewTable([]string{"TIME", "USER", "PID", "OWNER", "ACTION", "VALUE", "RESULT", "COMMAND"})
+	if pv.audit != nil {
+		for _, record := range pv.audit.Recent() {
+			user := record.User
+			if record.SudoUser != "" {
+				user = record.SudoUser + " (" + record.User + ")"
+			}
+			result := record.Result
+			if record.Errno != "" {
+				result += " " + record.Errno
+			}
+			table.AddRow([]string{
+				utils.FormatDateTime(record.Time),
+				user,
+				strconv.Itoa(record.PID),
+				record.Owner,
+				record.Action,
+				record.Value,
+				result,
+				record.Command,
+			})
+		}
+	}
+	pv.history = table
+}
+
+func (pv *ProcessView) IsShowingHistory() bool {
+	return pv.history != nil
+}
+
+func (pv *ProcessView) CloseHistory() {
+	pv.history = nil
+}
+
+func (pv *ProcessView) renderHistory(width, height int) string {
+	title := "Action History"
+	if pv.audit != nil && pv.audit.Path() != "" {
+		title += " (" + pv.audit.Path() + ")"
+	}
+	lines := styles.Title().Render(title)
+	if len(pv.history.Rows) == 0 {
+		return lines + "\n" + styles.Muted().Render("No process actions yet")
+	}
+	pv.history.SetSize(width, height-1)
+	return lines + "\n" + pv.history.Render()
+}
@@ -0,0 +1,204 @@
+package views
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+// processColumn is a column the flat process list can show. before orders
+// processes for the default "desc" sort, which puts the largest numbers
+// first and text in alphabetical order.
+type processColumn struct {
+	name   string
+	header string
+	value  func(p *models.Process) string
+	before func(a, b *models.Process) bool
+}
+
+func byNumber(get func(p *models.Process) float64) func(a, b *models.Process) bool {
+	return func(a, b *models.Process) bool { return get(a) > get(b) }
+}
+
+func byText(get func(p *models.Process) string) func(a, b *models.Process) bool {
+	return func(a, b *models.Process) bool { return strings.ToLower(get(a)) < strings.ToLower(get(b)) }
+}
+
+// processColumns are all columns in the order the setup screen lists them.
+// The names are also the sort fields; pid stays the first column since rows
+// are identified by it.
+var processColumns = []processColumn{
+	{"pid", "PID",
+		func(p *models.Process) string { return strconv.Itoa(p.PID) },
+		func(a, b *models.Process) bool { return a.PID < b.PID }},
+	{"ppid", "PPID",
+		func(p *models.Process) string { return strconv.Itoa(p.PPID) },
+		func(a, b *models.Process) bool { return a.PPID < b.PPID }},
+	{"name", "NAME",
+		func(p *models.Process) string { return p.Name },
+		byText(func(p *models.Process) string { return p.Name })},
+	{"state", "STATE",
+		func(p *models.Process) string { return utils.FormatProcessState(p.State) },
+		byText(func(p *models.Process) string { return p.State })},
+	{"cpu", "CPU%",
+		func(p *models.Process) string { return utils.FormatPercent(p.CPUPercent) },
+		byNumber(func(p *models.Process) float64 { return p.CPUPercent })},
+	{"mem%", "MEM%",
+		func(p *models.Process) string { return utils.FormatPercent(p.MemoryPercent) },
+		byNumber(func(p *models.Process) float64 { return p.MemoryPercent })},
+	{"memory", "MEMORY",
+		func(p *models.Process) string { return utils.FormatBytes(p.MemoryRSS) },
+		byNumber(func(p *models.Process) float64 { return float64(p.MemoryRSS) })},
+	{"virt", "VIRT",
+		func(p *models.Process) string { return utils.FormatBytes(p.MemoryVMS) },
+		byNumber(func(p *models.Process) float64 { return float64(p.MemoryVMS) })},
+	{"time", "TIME",
+		func(p *models.Process) string { return utils.FormatDuration(p.CPUTime) },
+		byNumber(func(p *models.Process) float64 { return float64(p.CPUTime) })},
+	{"user", "USER",
+		func(p *models.Process) string { return p.User },
+		byText(func(p *models.Process) string { return p.User })},
+	{"group", "GROUP",
+		func(p *models.Process) string { return p.Group },
+		byText(func(p *models.Process) string { return p.Group })},
+	{"nice", "NI",
+		func(p *models.Process) string { return strconv.Itoa(p.Nice) },
+		byNumber(func(p *models.Process) float64 { return float64(p.Nice) })},
+	{"priority", "PRI",
+		func(p *models.Process) string { return strconv.Itoa(p.Priority) },
+		byNumber(func(p *models.Process) float64 { return float64(p.Priority) })},
+	{"threads", "THR",
+		func(p *models.Process) string { return strconv.Itoa(p.NumThreads) },
+		byNumber(func(p *models.Process) float64 { return float64(p.NumThreads) })},
+	{"fds", "FDS",
+		func(p *models.Process) string { return strconv.Itoa(p.NumFDs) },
+		byNumber(func(p *models.Process) float64 { return float64(p.NumFDs) })},
+	{"start", "START",
+		func(p *models.Process) string { return formatStart(p.CreateTime) },
+		// Most recently started first.
+		func(a, b *models.Process) bool { return a.CreateTime.After(b.CreateTime) }},
+	{"command", "COMMAND",
+		func(p *models.Process) string { return p.Command },
+		byText(func(p *models.Process) string { return p.Command })},
+}
+
+var defaultProcessColumns = []models.ColumnConfig{
+	{Name: "pid"}, {Name: "ppid"}, {Name: "name"}, {Name: "state"}, {Name: "cpu"},
+	{Name: "memory"}, {Name: "time"}, {Name: "user"}, {Name: "command"},
+}
+
+func processColumnByName(name string) (processColumn, bool) {
+	for _, column := range processColumns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	return processColumn{}, false
+}
+
+// formatStart shows the time of day for processes started today and the
+// date for older ones, like ps.
+func formatStart(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if utils.FormatDate(t) == utils.FormatDate(time.Now()) {
+		return utils.FormatTime(t)
+	}
+	return utils.FormatDate(t)
+}
+
+// normalizeColumns drops unknown and repeated columns and puts pid first.
+// An empty layout gives the default columns.
+func normalizeColumns(columns []models.ColumnConfig) []models.ColumnConfig {
+	if len(columns) == 0 {
+		columns = defaultProcessColumns
+	}
+	normalized := []models.ColumnConfig{{Name: "pid"}}
+	seen := map[string]bool{"pid": true}
+	for _, column := range columns {
+		if column.Width < 0 {
+			column.Width = 0
+		}
+		if column.Name == "pid" {
+			normalized[0] = column
+			continue
+		}
+		if _, ok := processColumnByName(column.Name); ok && !seen[column.Name] {
+			seen[column.Name] = true
+			normalized = append(normalized, column)
+		}
+	}
+	return normalized
+}
+
+// SetColumns lays out the flat process list.
+func (pv *ProcessView) SetColumns(columns []models.ColumnConfig) {
+	pv.columns = normalizeColumns(columns)
+}
+
+// Columns returns the layout of the flat process list.
+func (pv *ProcessView) Columns() []models.ColumnConfig {
+	return append([]models.ColumnConfig(nil), pv.columns...)
+}
+
+func (pv *ProcessView) updateColumnRows(processes []models.Process) {
+	headers := make([]string, len(pv.columns))
+	widths := make([]int, len(pv.columns))
+	columns := make([]processColumn, len(pv.columns))
+	for i, config := range pv.columns {
+		columns[i], _ = processColumnByName(config.Name)
+		headers[i] = columns[i].header
+		if config.Name == pv.sortField {
+			if pv.sortOrder == "asc" {
+				headers[i] += "▲"
+			} else {
+				headers[i] += "▼"
+			}
+		}
+		widths[i] = config.Width
+	}
+	pv.table.Headers = headers
+	pv.table.Widths = widths
+
+	rows := make([][]string, 0, len(processes))
+	for i := range processes {
+		row := make([]string, len(columns))
+		for j, column := range columns {
+			row[j] = column.value(&processes[i])
+		}
+		rows = append(rows, row)
+	}
+	pv.table.Rows = rows
+}
+
+func (pv *ProcessView) sortByColumn(processes []models.Process, column processColumn) {
+	for i := 0; i < len(processes)-1; i++ {
+		for j := i + 1; j < len(processes); j++ {
+			if column.before(&processes[j], &processes[i]) {
+				processes[i], processes[j] = processes[j], processes[i]
+			}
+		}
+	}
+}
+
+// CycleSortColumn sorts the flat list by the next (step 1) or previous
+// (step -1) shown column.
+func (pv *ProcessView) CycleSortColumn(step int) {
+	if pv.ioMode || pv.memoryMode {
+		return
+	}
+	current := 0
+	for i, column := range pv.columns {
+		if column.Name == pv.sortField {
+			current = i
+			break
+		}
+	}
+	next := (current + step + len(pv.columns)) % len(pv.columns)
+	pv.sortField = pv.columns[next].Name
+	pv.sortOrder = "desc"
+}
@@ -0,0 +1,111 @@
+package views
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+func columnNames(columns []models.ColumnConfig) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+func TestNormalizeColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []models.ColumnConfig
+		want    []models.ColumnConfig
+	}{
+		{"empty gives defaults", nil, defaultProcessColumns},
+		{"pid pinned first",
+			[]models.ColumnConfig{{Name: "cpu"}, {Name: "pid", Width: 8}, {Name: "name"}},
+			[]models.ColumnConfig{{Name: "pid", Width: 8}, {Name: "cpu"}, {Name: "name"}}},
+		{"pid added when missing",
+			[]models.ColumnConfig{{Name: "user"}},
+			[]models.ColumnConfig{{Name: "pid"}, {Name: "user"}}},
+		{"duplicates dropped",
+			[]models.ColumnConfig{{Name: "cpu", Width: 6}, {Name: "cpu", Width: 9}},
+			[]models.ColumnConfig{{Name: "pid"}, {Name: "cpu", Width: 6}}},
+		{"unknown dropped",
+			[]models.ColumnConfig{{Name: "bogus"}, {Name: "time"}},
+			[]models.ColumnConfig{{Name: "pid"}, {Name: "time"}}},
+		{"negative width is automatic",
+			[]models.ColumnConfig{{Name: "name", Width: -3}},
+			[]models.ColumnConfig{{Name: "pid"}, {Name: "name"}}},
+	}
+	for _, test := range tests {
+		if got := normalizeColumns(test.columns); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSortByColumn(t *testing.T) {
+	processes := []models.Process{
+		{PID: 3, Name: "beta", CPUPercent: 5},
+		{PID: 1, Name: "Alpha", CPUPercent: 20},
+		{PID: 2, Name: "gamma", CPUPercent: 10},
+	}
+	tests := []struct {
+		column string
+		pids   []int
+	}{
+		{"pid", []int{1, 2, 3}},
+		{"cpu", []int{1, 2, 3}},
+		{"name", []int{1, 3, 2}},
+	}
+	pv := NewProcessView()
+	for _, test := range tests {
+		column, ok := processColumnByName(test.column)
+		if !ok {
+			t.Fatalf("unknown column %q", test.column)
+		}
+		sorted := append([]models.Process(nil), processes...)
+		pv.sortByColumn(sorted, column)
+		var pids []int
+		for _, p := range sorted {
+			pids = append(pids, p.PID)
+		}
+		if !reflect.DeepEqual(pids, test.pids) {
+			t.Errorf("sort by %s: got %v, want %v", test.column, pids, test.pids)
+		}
+	}
+}
+
+func TestCycleSortColumn(t *testing.T) {
+	pv := NewProcessView()
+	pv.SetColumns([]models.ColumnConfig{{Name: "pid"}, {Name: "name"}, {Name: "cpu"}})
+	pv.sortField = "cpu"
+	pv.sortOrder = "asc"
+
+	pv.CycleSortColumn(1)
+	if pv.sortField != "pid" || pv.sortOrder != "desc" {
+		t.Errorf("Expected to wrap to pid descending, got %s %s", pv.sortField, pv.sortOrder)
+	}
+	pv.CycleSortColumn(-1)
+	if pv.sortField != "cpu" {
+		t.Errorf("Expected to wrap back to cpu, got %s", pv.sortField)
+	}
+	pv.CycleSortColumn(-1)
+	if pv.sortField != "name" {
+		t.Errorf("Expected name, got %s", pv.sortField)
+	}
+
+	// A sort field that is not shown starts from the first column.
+	pv.sortField = "threads"
+	pv.CycleSortColumn(1)
+	if pv.sortField != "name" {
+		t.Errorf("Expected name, got %s", pv.sortField)
+	}
+
+	pv.memoryMode = true
+	pv.CycleSortColumn(1)
+	if pv.sortField != "name" {
+		t.Error("Memory mode should keep its own sort")
+	}
+}
@@ -0,0 +1,195 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/styles"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+// DetailView is a scrollable page of everything /proc shows about one
+// process. It is reread on every refresh while open.
+type DetailView struct {
+	collector  *collectors.DetailCollector
+	processMgr *system.ProcessManager
+	pid        int
+	live       bool
+	detail     *models.ProcessDetail
+	scheduling []models.Field
+	err        error
+	offset     int
+	height     int
+}
+
+func NewDetailView() *DetailView {
+	return &DetailView{processMgr: system.NewProcessManager()}
+}
+
+func (dv *DetailView) Open(src *system.Source, pid int) {
+	dv.collector = collectors.NewDetailCollector(src, pid)
+	dv.pid = pid
+	// Scheduling settings come from syscalls, which only see the processes
+	// of the machine ltop runs on.
+	dv.live = src.Paths().ProcRoot == system.DefaultProcRoot
+	dv.offset = 0
+	dv.Refresh()
+}
+
+func (dv *DetailView) Close() {
+	dv.collector = nil
+	dv.detail = nil
+	dv.err = nil
+}
+
+func (dv *DetailView) IsOpen() bool {
+	return dv.collector != nil
+}
+
+func (dv *DetailView) Refresh() {
+	if dv.collector == nil {
+		return
+	}
+	dv.detail, dv.err = dv.collector.Collect()
+	dv.scheduling = nil
+	if dv.err == nil && dv.live {
+		dv.scheduling = schedulingFields(dv.processMgr, dv.pid)
+	}
+}
+
+func (dv *DetailView) MoveUp() {
+	dv.scroll(-1)
+}
+
+func (dv *DetailView) MoveDown() {
+	dv.scroll(1)
+}
+
+func (dv *DetailView) PageUp() {
+	dv.scroll(-utils.Max(1, dv.height-1))
+}
+
+func (dv *DetailView) PageDown() {
+	dv.scroll(utils.Max(1, dv.height-1))
+}
+
+// scroll moves the offset; Render clamps it to the content.
+func (dv *DetailView) scroll(lines int) {
+	dv.offset = utils.Max(0, dv.offset+lines)
+}
+
+func (dv *DetailView) Render(width, height int) string {
+	title := fmt.Sprintf("Process %d", dv.pid)
+	var lines []string
+	if dv.err != nil {
+		lines = []string{styles.Error().Render(dv.err.Error())}
+	} else if dv.detail != nil {
+		if name := detailStatus(dv.detail, "Name"); name != "" {
+			title += " (" + name + ")"
+		}
+		lines = dv.renderLines(width - 4)
+	}
+
+	// The title and the panel's padding take three lines.
+	dv.height = utils.Max(1, height-3)
+	dv.offset = utils.Min(dv.offset, utils.Max(0, len(lines)-dv.height))
+	end := utils.Min(len(lines), dv.offset+dv.height)
+
+	position := ""
+	if len(lines) > dv.height {
+		position = fmt.Sprintf(" [%d-%d of %d]", dv.offset+1, end, len(lines))
+	}
+	return styles.Title().Render(title+position) + "\n" +
+		strings.Join(lines[dv.offset:end], "\n")
+}
+
+func (dv *DetailView) renderLines(width int) []string {
+	d := dv.detail
+	var lines []string
+	section := func(name, key string) {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, styles.TableHeader().Render(name))
+		if reason, ok := d.Unavailable[key]; ok {
+			lines = append(lines, styles.Muted().Render("  unavailable: "+reason))
+		}
+	}
+	add := func(line string) {
+		lines = append(lines, utils.TruncateString("  "+line, width))
+	}
+	addFields := func(fields []models.Field) {
+		nameWidth := 0
+		for _, f := range fields {
+			nameWidth = utils.Max(nameWidth, len(f.Name)+1)
+		}
+		for _, f := range fields {
+			add(fmt.Sprintf("%-*s  %s", nameWidth, f.Name+":", f.Value))
+		}
+	}
+
+	section("Process", "")
+	addFields([]models.Field{
+		{Name: "exe", Value: valueOr(d.Exe, d.Unavailable["exe"])},
+		{Name: "cwd", Value: valueOr(d.Cwd, d.Unavailable["cwd"])},
+		{Name: "wchan", Value: valueOr(d.WChan, d.Unavailable["wchan"])},
+	})
+
+	if len(dv.scheduling) > 0 {
+		section("Scheduling", "")
+		addFields(dv.scheduling)
+	}
+
+	section("Command Line", "cmdline")
+	for i, arg := range d.Args {
+		add(fmt.Sprintf("[%d] %s", i, arg))
+	}
+
+	section("Status", "")
+	addFields(d.Status)
+
+	section("Memory (smaps_rollup)", "smaps_rollup")
+	addFields(d.SmapsRollup)
+
+	section("Limits", "limits")
+	if len(d.Limits) > 0 {
+		add(fmt.Sprintf("%-26s %-20s %-20s %s", "LIMIT", "SOFT", "HARD", "UNITS"))
+	}
+	for _, limit := range d.Limits {
+		add(fmt.Sprintf("%-26s %-20s %-20s %s", limit.Name, limit.Soft, limit.Hard, limit.Units))
+	}
+
+	section("Cgroups", "cgroup")
+	for _, cgroup := range d.Cgroups {
+		add(cgroup)
+	}
+
+	section("Namespaces", "namespaces")
+	addFields(d.Namespaces)
+
+	section("Environment", "environ")
+	for _, env := range d.Environ {
+		add(env)
+	}
+
+	return lines
+}
+
+func detailStatus(d *models.ProcessDetail, name string) string {
+	for _, f := range d.Status {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func valueOr(value, reason string) string {
+	if value == "" && reason != "" {
+		return "(" + reason + ")"
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+// drillDown is a page about the selected process that replaces the process
+// list until it is closed. It reads /proc itself, so it is only available
+// while monitoring a live system.
+type drillDown interface {
+	Open(src *system.Source, pid int)
+	Close()
+	IsOpen() bool
+	Refresh()
+	MoveUp()
+	MoveDown()
+	PageUp()
+	PageDown()
+	Render(width, height int) string
+}
+
+func (pv *ProcessView) drillDowns() []drillDown {
+	return []drillDown{pv.threads, pv.details, pv.files}
+}
+
+// drillDown returns the open drill-down, if any.
+func (pv *ProcessView) drillDown() drillDown {
+	for _, d := range pv.drillDowns() {
+		if d.IsOpen() {
+			return d
+		}
+	}
+	return nil
+}
+
+func (pv *ProcessView) openDrillDown(d drillDown, what string) error {
+	pid := pv.getSelectedPID()
+	if pid <= 0 {
+		return nil
+	}
+	if pv.source == nil {
+		return fmt.Errorf("%s are not available", what)
+	}
+	pv.CloseDrillDown()
+	d.Open(pv.source, pid)
+	return nil
+}
+
+// ShowThreads drills into the threads of the selected process.
+func (pv *ProcessView) ShowThreads() error {
+	return pv.openDrillDown(pv.threads, "threads")
+}
+
+func (pv *ProcessView) IsShowingThreads() bool {
+	return pv.threads.IsOpen()
+}
+
+// ShowDetails drills into everything /proc shows about the selected
+// process.
+func (pv *ProcessView) ShowDetails() error {
+	return pv.openDrillDown(pv.details, "process details")
+}
+
+func (pv *ProcessView) IsShowingDetails() bool {
+	return pv.details.IsOpen()
+}
+
+// ShowFiles drills into the open files and sockets of the selected process.
+func (pv *ProcessView) ShowFiles() error {
+	return pv.openDrillDown(pv.files, "open files")
+}
+
+func (pv *ProcessView) IsShowingFiles() bool {
+	return pv.files.IsOpen()
+}
+
+// IsDrilledDown reports whether a drill-down replaces the process list.
+func (pv *ProcessView) IsDrilledDown() bool {
+	return pv.drillDown() != nil
+}
+
+// CloseDrillDown returns to the process list.
+func (pv *ProcessView) CloseDrillDown() {
+	for _, d := range pv.drillDowns() {
+		d.Close()
+	}
+}
+
+// Refresh rereads the drill-down that is open, once per snapshot.
+func (pv *ProcessView) Refresh() {
+	if d := pv.drillDown(); d != nil {
+		d.Refresh()
+	}
+}
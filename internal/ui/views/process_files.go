@@ -0,0 +1,112 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/internal/ui/styles"
+)
+
+var fileHeaders = []string{"FD", "TYPE", "TARGET", "PROTO", "STATE", "LOCAL", "REMOTE"}
+
+// fdWarnPercent is how close to its soft limit the fd count of a process
+// gets before it is highlighted.
+const fdWarnPercent = 80
+
+// FilesView lists the open file descriptors of one process, with sockets
+// resolved to their addresses.
+type FilesView struct {
+	table     *components.Table
+	collector *collectors.FileCollector
+	pid       int
+	files     *models.ProcessFiles
+	err       error
+}
+
+func NewFilesView() *FilesView {
+	return &FilesView{table: components.NewTable(fileHeaders)}
+}
+
+func (fv *FilesView) Open(src *system.Source, pid int) {
+	fv.collector = collectors.NewFileCollector(src, pid)
+	fv.pid = pid
+	fv.table.ClearRows()
+	fv.Refresh()
+}
+
+func (fv *FilesView) Close() {
+	fv.collector = nil
+	fv.files = nil
+	fv.err = nil
+}
+
+func (fv *FilesView) IsOpen() bool {
+	return fv.collector != nil
+}
+
+func (fv *FilesView) Refresh() {
+	if fv.collector == nil {
+		return
+	}
+	fv.files, fv.err = fv.collector.Collect()
+	if fv.err != nil {
+		return
+	}
+
+	rows := make([][]string, 0, len(fv.files.Files))
+	for _, file := range fv.files.Files {
+		row := []string{strconv.Itoa(file.FD), file.Type, file.Target, "", "", "", ""}
+		if s := file.Socket; s != nil {
+			row[3], row[4], row[5], row[6] = s.Protocol, s.State, s.Local(), s.Remote()
+		}
+		rows = append(rows, row)
+	}
+	fv.table.Rows = rows
+	if fv.table.Selected >= len(rows) {
+		fv.table.SetSelected(len(rows) - 1)
+	}
+}
+
+func (fv *FilesView) MoveUp() {
+	fv.table.MoveUp()
+}
+
+func (fv *FilesView) MoveDown() {
+	fv.table.MoveDown()
+}
+
+func (fv *FilesView) PageUp() {
+	fv.table.PageUp()
+}
+
+func (fv *FilesView) PageDown() {
+	fv.table.PageDown()
+}
+
+func (fv *FilesView) Render(width, height int) string {
+	title := styles.Title().Render(fmt.Sprintf("Open Files of PID %d", fv.pid))
+	if fv.err != nil {
+		return title + "\n" + styles.Error().Render(fv.err.Error())
+	}
+
+	fv.table.SetSize(width, height-2)
+	return title + "\n" + fv.renderSummary() + "\n" + fv.table.Render()
+}
+
+func (fv *FilesView) renderSummary() string {
+	count := len(fv.files.Files)
+	if fv.files.FDLimit == 0 {
+		return styles.Info().Render(fmt.Sprintf("%d open files", count))
+	}
+
+	used := float64(count) / float64(fv.files.FDLimit) * 100
+	summary := fmt.Sprintf("%d of %d open files (%.0f%% of the soft limit)", count, fv.files.FDLimit, used)
+	if used >= fdWarnPercent {
+		return styles.Warning().Render("Warning: " + summary)
+	}
+	return styles.Info().Render(summary)
+}
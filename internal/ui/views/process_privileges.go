@@ -0,0 +1,90 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/admiller/ltop/internal/system"
+)
+
+// SetPrivileges sets what ltop may do, to explain actions that will fail.
+func (pv *ProcessView) SetPrivileges(privileges system.Privileges) {
+	pv.privileges = privileges
+}
+
+func (pv *ProcessView) Privileges() system.Privileges {
+	return pv.privileges
+}
+
+// SetReadOnly disables every process action.
+func (pv *ProcessView) SetReadOnly(readOnly bool) {
+	pv.processMgr.SetReadOnly(readOnly)
+}
+
+func (pv *ProcessView) IsReadOnly() bool {
+	return pv.processMgr.IsReadOnly()
+}
+
+// signalNote warns about targets that cannot be signaled.
+func (pv *ProcessView) signalNote(pids []int) string {
+	return pv.privilegeNote(pids, pv.privileges.CanSignal, "signaled", "CAP_KILL")
+}
+
+// scheduleNote warns about targets whose priority and scheduling cannot be
+// changed.
+func (pv *ProcessView) scheduleNote(pids []int) string {
+	return pv.privilegeNote(pids, pv.privileges.CanSchedule, "changed", "CAP_SYS_NICE")
+}
+
+func (pv *ProcessView) privilegeNote(pids []int, allowed func(owner string) bool, verb, capability string) string {
+	var denied []int
+	for _, pid := range pids {
+		if !allowed(pv.byPID[pid].User) {
+			denied = append(denied, pid)
+		}
+	}
+	switch {
+	case len(denied) == 0:
+		return ""
+	case len(pids) == 1:
+		return fmt.Sprintf("\n\nWarning: it belongs to %s and cannot be %s without %s.",
+			pv.byPID[denied[0]].User, verb, capability)
+	}
+	return fmt.Sprintf("\n\nWarning: %d of them belong to other users and cannot be %s without %s.",
+		len(denied), verb, capability)
+}
+
+// privilegeSummary is a line on what the process list cannot show or do, if
+// anything. The help screen explains it in full.
+func (pv *ProcessView) privilegeSummary() string {
+	p := pv.privileges
+	var missing []string
+	for _, capability := range []struct {
+		name string
+		ok   bool
+	}{
+		{"CAP_KILL", p.CapKill},
+		{"CAP_SYS_NICE", p.CapSysNice},
+		{"CAP_SYS_PTRACE", p.CapSysPtrace},
+	} {
+		if !capability.ok {
+			missing = append(missing, capability.name)
+		}
+	}
+
+	var notes []string
+	if pv.IsReadOnly() {
+		notes = append(notes, "Read-only: process actions are disabled")
+	}
+	if len(missing) > 0 {
+		notes = append(notes, fmt.Sprintf("No %s: some actions and details only work on processes of %s",
+			strings.Join(missing, ", "), p.User))
+	}
+	if p.HidePID != "" {
+		notes = append(notes, "/proc hidepid="+p.HidePID+": other users' processes are hidden or unreadable")
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return strings.Join(notes, " | ") + " (see help)"
+}
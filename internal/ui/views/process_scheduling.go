@@ -0,0 +1,121 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+)
+
+// showSchedulingDialog asks for a new value of a scheduling setting of the
+// targets, starting from the current value of the first one.
+func (pv *ProcessView) showSchedulingDialog(action, title, what, prompt string, current func(pid int) (string, error)) {
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
+		return
+	}
+
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
+	value, err := current(pids[0])
+	if err != nil {
+		value = ""
+	}
+	pv.actionType = action
+	pv.inputDialog.Title = title
+	pv.inputDialog.Message = fmt.Sprintf("Enter new %s for %s and all of their threads\n%s%s%s", what, targets, prompt, list, pv.scheduleNote(pids))
+	pv.inputDialog.Show()
+	pv.inputDialog.Input.SetValue(value)
+}
+
+// ShowAffinityDialog edits the CPUs the targets may run on.
+func (pv *ProcessView) ShowAffinityDialog() {
+	pv.showSchedulingDialog("affinity", "Set CPU Affinity", "CPU affinity",
+		"(CPU list such as 0-3,6)",
+		func(pid int) (string, error) {
+			cpus, err := pv.processMgr.GetAffinity(pid)
+			return system.FormatCPUList(cpus), err
+		})
+}
+
+// ShowSchedulerDialog edits the scheduling policy of the targets.
+func (pv *ProcessView) ShowSchedulerDialog() {
+	pv.showSchedulingDialog("scheduler", "Set Scheduling Policy", "scheduling policy",
+		"(other, batch, idle, or fifo/rr with a priority from 1 to 99)",
+		func(pid int) (string, error) {
+			policy, err := pv.processMgr.GetScheduler(pid)
+			return policy.String(), err
+		})
+}
+
+// ShowIOPriorityDialog edits the I/O scheduling class and level of the
+// targets.
+func (pv *ProcessView) ShowIOPriorityDialog() {
+	pv.showSchedulingDialog("ioprio", "Set I/O Priority", "I/O priority",
+		"(none, idle, or rt/be with a level from 0 = highest to 7)",
+		func(pid int) (string, error) {
+			prio, err := pv.processMgr.GetIOPriority(pid)
+			return prio.String(), err
+		})
+}
+
+// executeScheduling applies the value entered in a scheduling dialog.
+func (pv *ProcessView) executeScheduling() error {
+	value := pv.inputDialog.GetValue()
+	switch pv.actionType {
+	case "affinity":
+		cpus, err := system.ParseCPUList(value)
+		if err != nil {
+			return err
+		}
+		return pv.runAction(func(pid int) error {
+			return pv.processMgr.SetAffinity(pid, cpus)
+		})
+	case "scheduler":
+		policy, err := system.ParseSchedPolicy(value)
+		if err != nil {
+			return err
+		}
+		return pv.runAction(func(pid int) error {
+			return pv.processMgr.SetScheduler(pid, policy)
+		})
+	case "ioprio":
+		prio, err := system.ParseIOPriority(value)
+		if err != nil {
+			return err
+		}
+		return pv.runAction(func(pid int) error {
+			return pv.processMgr.SetIOPriority(pid, prio)
+		})
+	}
+	return fmt.Errorf("unknown action: %s", pv.actionType)
+}
+
+// schedulingFields reads the scheduling settings of a process for the
+// details page. A setting that cannot be read shows why.
+func schedulingFields(pm *system.ProcessManager, pid int) []models.Field {
+	show := func(value string, err error) string {
+		if err != nil {
+			return "(" + strings.TrimPrefix(describeActionError(err), "failed: ") + ")"
+		}
+		return value
+	}
+
+	cpus, err := pm.GetAffinity(pid)
+	affinity := show(system.FormatCPUList(cpus), err)
+	policy, err := pm.GetScheduler(pid)
+	scheduler := show(policy.String(), err)
+	prio, err := pm.GetIOPriority(pid)
+	ioprio := show(prio.String(), err)
+	nice, err := pm.GetProcessPriority(pid)
+	// getpriority(2) returns 20 - nice.
+	niceness := show(fmt.Sprintf("%d", 20-nice), err)
+
+	return []models.Field{
+		{Name: "CPU affinity", Value: affinity},
+		{Name: "policy", Value: scheduler},
+		{Name: "nice", Value: niceness},
+		{Name: "I/O priority", Value: ioprio},
+	}
+}
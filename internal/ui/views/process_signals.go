@@ -0,0 +1,150 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/internal/ui/styles"
+)
+
+// killTreeTimeout is how long a killed tree is given to exit.
+const killTreeTimeout = 5 * time.Second
+
+// KillTreeMsg reports the outcome of a kill tree action.
+type KillTreeMsg []system.KillResult
+
+// ShowSignalMenu lists every signal to send to the selected or tagged
+// processes, starting at SIGTERM.
+func (pv *ProcessView) ShowSignalMenu() {
+	if len(pv.actionTargets()) == 0 {
+		return
+	}
+	pv.signals = system.Signals()
+	pv.signalMenu = components.NewTable([]string{"NUM", "SIGNAL"})
+	for i, info := range pv.signals {
+		pv.signalMenu.AddRow([]string{strconv.Itoa(int(info.Signal)), info.Name})
+		if info.Signal == syscall.SIGTERM {
+			pv.signalMenu.Selected = i
+		}
+	}
+}
+
+func (pv *ProcessView) IsShowingSignalMenu() bool {
+	return pv.signalMenu != nil
+}
+
+func (pv *ProcessView) CloseSignalMenu() {
+	pv.signalMenu = nil
+}
+
+// ChooseSignal asks to confirm sending the signal under the cursor.
+func (pv *ProcessView) ChooseSignal() {
+	info := pv.signals[pv.signalMenu.GetSelectedIndex()]
+	pv.CloseSignalMenu()
+
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
+		return
+	}
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
+	pv.signal = info.Signal
+	pv.actionType = "signal"
+	pv.confirmDialog.Title = "Send Signal"
+	pv.confirmDialog.Message = fmt.Sprintf("Send %s (%d) to %s?%s%s", info.Name, int(info.Signal), targets, list, pv.signalNote(pids))
+	pv.confirmDialog.Show()
+}
+
+func (pv *ProcessView) renderSignalMenu(width, height int) string {
+	targets, _ := pv.describeTargets(pv.actionTargets())
+	title := styles.Title().Render("Send signal to " + targets)
+	pv.signalMenu.SetSize(width, height-1)
+	return title + "\n" + pv.signalMenu.Render()
+}
+
+// subtree lists pid and its descendants from the last snapshot, every
+// parent before its children.
+func (pv *ProcessView) subtree(pid int) []int {
+	tree := []int{pid}
+	seen := map[int]bool{pid: true}
+	for i := 0; i < len(tree); i++ {
+		for _, child := range pv.byPID[tree[i]].Children {
+			// PID reuse can make a snapshot look cyclic.
+			if _, ok := pv.byPID[child]; ok && !seen[child] {
+				seen[child] = true
+				tree = append(tree, child)
+			}
+		}
+	}
+	return tree
+}
+
+// ShowKillTreeDialog asks to kill the selected process and all of its
+// descendants.
+func (pv *ProcessView) ShowKillTreeDialog() {
+	pid := pv.getSelectedPID()
+	if pid <= 0 {
+		return
+	}
+
+	pv.targets = pv.subtree(pid)
+	pv.confirmDialog.Title = "Kill Process Tree"
+	if err := system.CheckKillTree(pv.targets); err != nil {
+		pv.actionType = "kill_tree_refused"
+		pv.confirmDialog.Message = fmt.Sprintf("Process %d and its descendants cannot be killed as a tree: %v.", pid, err)
+		pv.confirmDialog.Show()
+		return
+	}
+	_, list := pv.describeTargets(pv.targets)
+	pv.actionType = "kill_tree"
+	pv.confirmDialog.Message = fmt.Sprintf(
+		"Kill process %d and its %d descendants? They are all stopped, sent SIGTERM and given %s to exit.%s%s",
+		pid, len(pv.targets)-1, killTreeTimeout, list, pv.signalNote(pv.targets))
+	pv.confirmDialog.Show()
+}
+
+// TakeKillTree returns the tree of a confirmed kill tree action, once.
+func (pv *ProcessView) TakeKillTree() []int {
+	tree := pv.killTree
+	pv.killTree = nil
+	return tree
+}
+
+// killTreeCmd kills a tree in the background, since waiting for it to exit
+// takes up to killTreeTimeout.
+func killTreeCmd(pm *system.ProcessManager, tree []int) tea.Cmd {
+	return func() tea.Msg {
+		return KillTreeMsg(pm.KillTree(tree, syscall.SIGTERM, killTreeTimeout))
+	}
+}
+
+// ShowKillTreeResults shows which processes of a killed tree exited. It
+// returns an error naming the survivors, if any.
+func (pv *ProcessView) ShowKillTreeResults(kills []system.KillResult) error {
+	results := make([]actionResult, 0, len(kills))
+	var survivors []int
+	for _, kill := range kills {
+		result := actionResult{pid: kill.PID, name: pv.byPID[kill.PID].Name, err: kill.Err}
+		if kill.Err == nil {
+			result.exited = kill.Exited
+			result.survived = !kill.Exited
+			if result.survived {
+				survivors = append(survivors, kill.PID)
+			}
+		}
+		results = append(results, result)
+	}
+	pv.actionType = "kill_tree"
+	pv.showResults(results)
+	auditErr := pv.auditActions(results)
+	if len(survivors) > 0 {
+		return fmt.Errorf("%d processes survived the kill tree: %v", len(survivors), survivors)
+	}
+	return auditErr
+}
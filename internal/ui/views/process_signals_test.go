@@ -0,0 +1,109 @@
+package views
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+// processState returns the state letter of pid from /proc.
+func processState(t *testing.T, pid int) string {
+	t.Helper()
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		t.Fatalf("Failed to read the state of %d: %v", pid, err)
+	}
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	return fields[0]
+}
+
+// waitForState polls pid until it reaches state.
+func waitForState(t *testing.T, pid int, state string) bool {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if processState(t, pid) == state {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestShowKillTreeDialog(t *testing.T) {
+	pv := newTestProcessView(testTree)
+
+	pv.table.SetSelected(1)
+	pv.ShowKillTreeDialog()
+	if pv.actionType != "kill_tree" || !reflect.DeepEqual(pv.targets, []int{10, 11, 12}) {
+		t.Fatalf("Expected to kill the tree of 10, got %s %v", pv.actionType, pv.targets)
+	}
+	pv.HandleDialogInput("left")
+	pv.HandleDialogInput("enter")
+	if tree := pv.TakeKillTree(); !reflect.DeepEqual(tree, []int{10, 11, 12}) {
+		t.Errorf("Expected the confirmed tree, got %v", tree)
+	}
+
+	// The tree of init contains everything, ltop included.
+	pv.table.SetSelected(0)
+	pv.ShowKillTreeDialog()
+	if pv.actionType != "kill_tree_refused" || !pv.confirmDialog.IsVisible() {
+		t.Fatalf("Expected the tree of PID 1 refused, got %s", pv.actionType)
+	}
+	pv.HandleDialogInput("left")
+	if err := pv.HandleDialogInput("enter"); err != nil {
+		t.Errorf("Expected the refusal to close quietly, got %v", err)
+	}
+	if tree := pv.TakeKillTree(); tree != nil {
+		t.Errorf("Expected no tree to kill, got %v", tree)
+	}
+}
+
+func TestResumeKey(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Cannot start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	pid := cmd.Process.Pid
+	if err := syscall.Kill(pid, syscall.SIGSTOP); err != nil {
+		t.Fatalf("Failed to stop %d: %v", pid, err)
+	}
+	if !waitForState(t, pid, "T") {
+		t.Fatalf("Expected %d stopped", pid)
+	}
+
+	m := newTestModel(t)
+	m.currentView = models.ViewProcesses
+	renderProcesses(m.processView, []models.Process{{PID: pid, Name: "sleep"}})
+	interval := m.app.Bus().Interval()
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune{'r'}},
+		{Type: tea.KeyLeft},
+		{Type: tea.KeyEnter},
+	} {
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	if m.err != nil {
+		t.Fatalf("Expected the resume to succeed, got %v", m.err)
+	}
+	if !waitForState(t, pid, "S") {
+		t.Errorf("Expected %d resumed by SIGCONT, got state %s", pid, processState(t, pid))
+	}
+	if m.app.Bus().Interval() != interval {
+		t.Error("r in the process view should not change the refresh rate")
+	}
+}
@@ -0,0 +1,253 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/internal/ui/styles"
+)
+
+// maxListedTargets bounds the processes a confirmation dialog lists.
+const maxListedTargets = 8
+
+// actionResult is the outcome of an action on one process. Kill tree
+// actions also tell whether the process exited.
+type actionResult struct {
+	pid      int
+	name     string
+	err      error
+	exited   bool
+	survived bool
+}
+
+func (r actionResult) status() string {
+	switch {
+	case r.err != nil:
+		return describeActionError(r.err)
+	case r.survived:
+		return "survived"
+	case r.exited:
+		return "exited"
+	}
+	return "ok"
+}
+
+// ToggleTag tags or untags the selected process and moves to the next row.
+func (pv *ProcessView) ToggleTag() {
+	pid := pv.getSelectedPID()
+	if pid <= 0 {
+		return
+	}
+	if pv.tagged[pid] {
+		delete(pv.tagged, pid)
+	} else {
+		pv.tagged[pid] = true
+	}
+	pv.table.MoveDown()
+}
+
+// TagShown tags every process in the list, which is all of them that match
+// the filter.
+func (pv *ProcessView) TagShown() {
+	for _, row := range pv.table.Rows {
+		if pid, err := strconv.Atoi(row[0]); err == nil {
+			pv.tagged[pid] = true
+		}
+	}
+}
+
+// TagSubtree tags the selected process and all of its descendants.
+func (pv *ProcessView) TagSubtree() {
+	seen := make(map[int]bool)
+	var tag func(pid int)
+	tag = func(pid int) {
+		// PID reuse can make a snapshot look cyclic.
+		if seen[pid] {
+			return
+		}
+		seen[pid] = true
+		pv.tagged[pid] = true
+		for _, child := range pv.byPID[pid].Children {
+			tag(child)
+		}
+	}
+	if pid := pv.getSelectedPID(); pid > 0 {
+		tag(pid)
+	}
+}
+
+func (pv *ProcessView) UntagAll() {
+	pv.tagged = make(map[int]bool)
+}
+
+func (pv *ProcessView) TaggedCount() int {
+	return len(pv.tagged)
+}
+
+// updateTags forgets processes that exited and highlights the tagged rows.
+func (pv *ProcessView) updateTags() {
+	for pid := range pv.tagged {
+		if _, ok := pv.byPID[pid]; !ok {
+			delete(pv.tagged, pid)
+		}
+	}
+	pv.table.Tagged = make(map[int]bool)
+	for i, row := range pv.table.Rows {
+		if pid, err := strconv.Atoi(row[0]); err == nil && pv.tagged[pid] {
+			pv.table.Tagged[i] = true
+		}
+	}
+}
+
+// actionTargets are the tagged processes or, without tags, the selected
+// one.
+func (pv *ProcessView) actionTargets() []int {
+	if len(pv.tagged) == 0 {
+		if pid := pv.getSelectedPID(); pid > 0 {
+			return []int{pid}
+		}
+		return nil
+	}
+	pids := make([]int, 0, len(pv.tagged))
+	for pid := range pv.tagged {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+// describeTargets names the processes of a dialog. Several are counted in
+// the sentence and listed after it, at most maxListedTargets of them.
+func (pv *ProcessView) describeTargets(pids []int) (string, string) {
+	if len(pids) == 1 {
+		return fmt.Sprintf("process %d", pids[0]), ""
+	}
+	lines := []string{""}
+	for i, pid := range pids {
+		if i == maxListedTargets {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(pids)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %d %s", pid, pv.byPID[pid].Name))
+	}
+	return fmt.Sprintf("%d tagged processes", len(pids)), strings.Join(lines, "\n")
+}
+
+// runAction applies action to every target and records it in the audit
+// log. A single process reports its error directly; several show a results
+// panel.
+func (pv *ProcessView) runAction(action func(pid int) error) error {
+	results := make([]actionResult, 0, len(pv.targets))
+	failed := 0
+	for _, pid := range pv.targets {
+		err := action(pid)
+		if err != nil {
+			failed++
+		}
+		results = append(results, actionResult{pid: pid, name: pv.byPID[pid].Name, err: err})
+	}
+	auditErr := pv.auditActions(results)
+
+	if len(results) == 1 {
+		if results[0].err != nil {
+			return results[0].err
+		}
+		return auditErr
+	}
+	pv.showResults(results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d processes failed", failed, len(results))
+	}
+	return auditErr
+}
+
+// describeActionError names the errno of common failures so that missing
+// privileges stand out.
+func describeActionError(err error) string {
+	switch {
+	case errors.Is(err, syscall.EPERM):
+		return "failed: permission denied (EPERM)"
+	case errors.Is(err, syscall.ESRCH), errors.Is(err, os.ErrProcessDone):
+		return "failed: no such process (ESRCH)"
+	case errors.Is(err, syscall.EACCES):
+		return "failed: permission denied (EACCES)"
+	}
+	return "failed: " + err.Error()
+}
+
+func (pv *ProcessView) showResults(results []actionResult) {
+	table := components.NewTable([]string{"PID", "NAME", "RESULT"})
+	failed := 0
+	for _, result := range results {
+		if result.err != nil || result.survived {
+			failed++
+		}
+		table.AddRow([]string{strconv.Itoa(result.pid), result.name, result.status()})
+	}
+	pv.results = table
+	pv.resultsFailed = failed
+	pv.resultsTitle = pv.actionTitle()
+}
+
+func (pv *ProcessView) actionTitle() string {
+	switch pv.actionType {
+	case "kill":
+		return "SIGTERM"
+	case "force_kill":
+		return "SIGKILL"
+	case "stop":
+		return "SIGSTOP"
+	case "continue":
+		return "SIGCONT"
+	case "nice":
+		return "renice to " + strings.TrimSpace(pv.inputDialog.GetValue())
+	case "signal":
+		return system.SignalName(pv.signal)
+	case "kill_tree":
+		return "kill tree"
+	case "affinity":
+		return "set CPU affinity to " + strings.TrimSpace(pv.inputDialog.GetValue())
+	case "scheduler":
+		return "set scheduling policy to " + strings.TrimSpace(pv.inputDialog.GetValue())
+	case "ioprio":
+		return "set I/O priority to " + strings.TrimSpace(pv.inputDialog.GetValue())
+	}
+	return pv.actionType
+}
+
+func (pv *ProcessView) IsShowingResults() bool {
+	return pv.results != nil
+}
+
+func (pv *ProcessView) CloseResults() {
+	pv.results = nil
+}
+
+func (pv *ProcessView) renderResults(width, height int) string {
+	failed := pv.resultsFailed
+	summary := fmt.Sprintf("Results of %s: %d ok, %d failed",
+		pv.resultsTitle, len(pv.results.Rows)-failed, failed)
+	style := styles.Info()
+	if failed > 0 {
+		style = styles.Warning()
+	}
+	pv.results.SetSize(width, height-1)
+	return style.Render(summary) + "\n" + pv.results.Render()
+}
+
+// indexProcesses remembers the processes of the snapshot by PID, for tags
+// and dialogs.
+func (pv *ProcessView) indexProcesses(processes []models.Process) {
+	pv.byPID = make(map[int]models.Process, len(processes))
+	for _, proc := range processes {
+		pv.byPID[proc.PID] = proc
+	}
+}
@@ -0,0 +1,148 @@
+package views
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"syscall"
+	"testing"
+
+	"github.com/admiller/ltop/internal/models"
+)
+
+// testTree is listed by CPU usage in PID order: 1 and its subtree 10, 11
+// and 12, then 20.
+var testTree = []models.Process{
+	{PID: 1, Name: "init", CPUPercent: 50, Children: []int{10, 20}},
+	{PID: 10, PPID: 1, Name: "server", CPUPercent: 40, Children: []int{11}},
+	{PID: 11, PPID: 10, Name: "worker", CPUPercent: 30, Children: []int{12}},
+	{PID: 12, PPID: 11, Name: "helper", CPUPercent: 20},
+	{PID: 20, PPID: 1, Name: "shell", CPUPercent: 10},
+}
+
+func newTestProcessView(processes []models.Process) *ProcessView {
+	pv := NewProcessView()
+	renderProcesses(pv, processes)
+	return pv
+}
+
+func renderProcesses(pv *ProcessView, processes []models.Process) {
+	snapshot := &models.MetricsSnapshot{}
+	snapshot.Processes.Processes = processes
+	pv.Render(snapshot, 120, 40)
+}
+
+func taggedPIDs(pv *ProcessView) []int {
+	pids := []int{}
+	for pid := range pv.tagged {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+// failedResults counts the failed rows of the results panel.
+func failedResults(pv *ProcessView) int {
+	failed := 0
+	for _, row := range pv.results.Rows {
+		if row[2] != "ok" {
+			failed++
+		}
+	}
+	return failed
+}
+
+func TestActionTargets(t *testing.T) {
+	pv := newTestProcessView(testTree)
+
+	pv.table.SetSelected(1)
+	if got := pv.actionTargets(); !reflect.DeepEqual(got, []int{10}) {
+		t.Errorf("Expected the selected process without tags, got %v", got)
+	}
+
+	pv.tagged[20] = true
+	pv.tagged[1] = true
+	if got := pv.actionTargets(); !reflect.DeepEqual(got, []int{1, 20}) {
+		t.Errorf("Expected the tagged processes in PID order, got %v", got)
+	}
+
+	pv.UntagAll()
+	pv.table.Rows = nil
+	if got := pv.actionTargets(); got != nil {
+		t.Errorf("Expected no targets in an empty list, got %v", got)
+	}
+}
+
+func TestTagSubtree(t *testing.T) {
+	processes := append([]models.Process(nil), testTree...)
+	// A reused PID can make the snapshot look cyclic.
+	processes[3].Children = []int{10}
+	pv := newTestProcessView(processes)
+
+	pv.table.SetSelected(1)
+	pv.TagSubtree()
+	if got := taggedPIDs(pv); !reflect.DeepEqual(got, []int{10, 11, 12}) {
+		t.Errorf("Expected the subtree of 10 tagged, got %v", got)
+	}
+}
+
+func TestUpdateTagsPrunesExited(t *testing.T) {
+	pv := newTestProcessView(testTree)
+	pv.tagged[12] = true
+	pv.tagged[20] = true
+
+	renderProcesses(pv, testTree[:4])
+	if got := taggedPIDs(pv); !reflect.DeepEqual(got, []int{12}) {
+		t.Errorf("Expected the exited process untagged, got %v", got)
+	}
+	if !reflect.DeepEqual(pv.table.Tagged, map[int]bool{3: true}) {
+		t.Errorf("Expected row 3 highlighted, got %v", pv.table.Tagged)
+	}
+}
+
+func TestRunAction(t *testing.T) {
+	failing := func(pid int) error {
+		if pid == 11 {
+			return syscall.EPERM
+		}
+		return nil
+	}
+
+	pv := newTestProcessView(testTree)
+	pv.actionType = "kill"
+	pv.targets = []int{11}
+	if err := pv.runAction(failing); !errors.Is(err, syscall.EPERM) {
+		t.Errorf("Expected the error of a single target, got %v", err)
+	}
+	if pv.IsShowingResults() {
+		t.Error("A single target should not show the results panel")
+	}
+
+	var called []int
+	pv.targets = []int{10, 11, 12}
+	err := pv.runAction(func(pid int) error {
+		called = append(called, pid)
+		return failing(pid)
+	})
+	if err == nil || err.Error() != "1 of 3 processes failed" {
+		t.Errorf("Expected a summary error, got %v", err)
+	}
+	if !reflect.DeepEqual(called, []int{10, 11, 12}) {
+		t.Errorf("Expected every target acted on, got %v", called)
+	}
+	if !pv.IsShowingResults() || failedResults(pv) != 1 || len(pv.results.Rows) != 3 {
+		t.Fatalf("Expected a results panel with one failure")
+	}
+	if status := pv.results.Rows[1][2]; status != "failed: permission denied (EPERM)" {
+		t.Errorf("Expected the errno in the results, got %q", status)
+	}
+
+	pv.CloseResults()
+	pv.targets = []int{10, 12}
+	if err := pv.runAction(failing); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if failedResults(pv) != 0 || pv.resultsTitle != "SIGTERM" {
+		t.Errorf("Expected a clean SIGTERM results panel, got %d failed, %q", failedResults(pv), pv.resultsTitle)
+	}
+}
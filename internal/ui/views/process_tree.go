@@ -0,0 +1,219 @@
+package views
+
+import (
+	"strconv"
+
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+var treeHeaders = []string{"PID", "USER", "STATE", "CPU%", "MEMORY", "ΣCPU%", "ΣMEMORY", "TREE", "COMMAND"}
+
+// subtree totals of a process and all of its descendants.
+type subtree struct {
+	cpu    float64
+	memory uint64
+}
+
+// treeRow is a process as shown in tree mode.
+type treeRow struct {
+	proc     models.Process
+	prefix   string
+	parent   bool
+	totals   subtree
+	expanded bool
+}
+
+// processTree indexes a process list by PID.
+type processTree struct {
+	byPID  map[int]models.Process
+	totals map[int]subtree
+}
+
+func newProcessTree(processes []models.Process) *processTree {
+	t := &processTree{
+		byPID:  make(map[int]models.Process, len(processes)),
+		totals: make(map[int]subtree, len(processes)),
+	}
+	for _, proc := range processes {
+		t.byPID[proc.PID] = proc
+	}
+	return t
+}
+
+// total sums CPU and memory over the subtree of pid.
+func (t *processTree) total(pid int, visiting map[int]bool) subtree {
+	if sum, ok := t.totals[pid]; ok {
+		return sum
+	}
+	proc := t.byPID[pid]
+	sum := subtree{cpu: proc.CPUPercent, memory: proc.MemoryRSS}
+
+	// PID reuse can make a snapshot look cyclic.
+	visiting[pid] = true
+	for _, child := range proc.Children {
+		if _, ok := t.byPID[child]; ok && !visiting[child] {
+			childSum := t.total(child, visiting)
+			sum.cpu += childSum.cpu
+			sum.memory += childSum.memory
+		}
+	}
+	delete(visiting, pid)
+
+	t.totals[pid] = sum
+	return sum
+}
+
+// withAncestors returns the PIDs of the given processes and all of their
+// ancestors, so that matches stay reachable from a root.
+func (t *processTree) withAncestors(processes []models.Process) map[int]bool {
+	visible := make(map[int]bool, len(processes))
+	for _, proc := range processes {
+		for pid := proc.PID; !visible[pid]; {
+			visible[pid] = true
+			parent, ok := t.byPID[t.byPID[pid].PPID]
+			if !ok {
+				break
+			}
+			pid = parent.PID
+		}
+	}
+	return visible
+}
+
+// buildTree flattens the visible processes into rows, depth first. Siblings
+// are sorted like the flat list; children of collapsed processes are left
+// out.
+func (pv *ProcessView) buildTree(all, matches []models.Process) []treeRow {
+	tree := newProcessTree(all)
+	visible := tree.withAncestors(matches)
+
+	var roots []models.Process
+	for _, proc := range all {
+		if !visible[proc.PID] {
+			continue
+		}
+		if _, ok := tree.byPID[proc.PPID]; !ok || proc.PPID == proc.PID || !visible[proc.PPID] {
+			roots = append(roots, proc)
+		}
+	}
+
+	var rows []treeRow
+	seen := make(map[int]bool)
+	var walk func(procs []models.Process, indent string, root bool)
+	walk = func(procs []models.Process, indent string, root bool) {
+		for i, proc := range pv.sortProcesses(procs) {
+			if seen[proc.PID] {
+				continue
+			}
+			seen[proc.PID] = true
+
+			var children []models.Process
+			for _, pid := range proc.Children {
+				if child, ok := tree.byPID[pid]; ok && visible[pid] {
+					children = append(children, child)
+				}
+			}
+
+			last := i == len(procs)-1
+			prefix, childIndent := "", ""
+			if !root {
+				prefix, childIndent = indent+"├─ ", indent+"│  "
+				if last {
+					prefix, childIndent = indent+"└─ ", indent+"   "
+				}
+			}
+
+			expanded := !pv.collapsed[proc.PID]
+			rows = append(rows, treeRow{
+				proc:     proc,
+				prefix:   prefix,
+				parent:   len(children) > 0,
+				totals:   tree.total(proc.PID, make(map[int]bool)),
+				expanded: expanded,
+			})
+			if expanded {
+				walk(children, childIndent, false)
+			}
+		}
+	}
+	walk(roots, "", true)
+	return rows
+}
+
+func (pv *ProcessView) updateTreeRows(all, matches []models.Process) {
+	var rows [][]string
+	for _, row := range pv.buildTree(all, matches) {
+		marker := ""
+		if row.parent {
+			marker = "▾ "
+			if !row.expanded {
+				marker = "▸ "
+			}
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(row.proc.PID),
+			row.proc.User,
+			utils.FormatProcessState(row.proc.State),
+			utils.FormatPercent(row.proc.CPUPercent),
+			utils.FormatBytes(row.proc.MemoryRSS),
+			utils.FormatPercent(row.totals.cpu),
+			utils.FormatBytes(row.totals.memory),
+			row.prefix + marker + row.proc.Name,
+			row.proc.Command,
+		})
+	}
+	pv.table.Rows = rows
+}
+
+// ToggleTreeMode switches between the flat process list and the process
+// tree. Tree mode replaces IO and memory mode.
+func (pv *ProcessView) ToggleTreeMode() {
+	if pv.ioMode {
+		pv.ToggleIOMode()
+	}
+	if pv.memoryMode {
+		pv.ToggleMemoryMode()
+	}
+	pv.treeMode = !pv.treeMode
+	if pv.treeMode {
+		pv.table.Headers = treeHeaders
+	}
+}
+
+func (pv *ProcessView) IsTreeMode() bool {
+	return pv.treeMode
+}
+
+// Expand and Collapse show or hide the children of the selected process.
+func (pv *ProcessView) Expand() {
+	delete(pv.collapsed, pv.getSelectedPID())
+}
+
+func (pv *ProcessView) Collapse() {
+	if pid := pv.getSelectedPID(); pid > 0 {
+		pv.collapsed[pid] = true
+	}
+}
+
+// revealPending expands the ancestors of the process passed to SelectPID so
+// that the tree has a row for it.
+func (pv *ProcessView) revealPending(processes []models.Process) {
+	if pv.pendingPID == 0 || !pv.treeMode {
+		return
+	}
+	parents := make(map[int]int, len(processes))
+	for _, p := range processes {
+		parents[p.PID] = p.PPID
+	}
+	seen := make(map[int]bool)
+	for pid := parents[pv.pendingPID]; pid > 0 && !seen[pid]; pid = parents[pid] {
+		seen[pid] = true
+		delete(pv.collapsed, pid)
+	}
+}
+
+// ExpandAll forgets every collapsed process.
+func (pv *ProcessView) ExpandAll() {
+	pv.collapsed = make(map[int]bool)
+}
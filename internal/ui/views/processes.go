@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"syscall"
 
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/admiller/ltop/internal/audit"
 	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/query"
 	"github.com/admiller/ltop/internal/system"
 	"github.com/admiller/ltop/internal/ui/components"
 	"github.com/admiller/ltop/internal/ui/styles"
@@ -21,14 +26,46 @@ type ProcessView struct {
 	processMgr    *system.ProcessManager
 	confirmDialog *components.ConfirmDialog
 	inputDialog   *components.InputDialog
-	selectedPID   int
+	targets       []int
 	actionType    string
-}
+	ioMode        bool
+	ioTotals      models.ProcessIOStats
+	memoryMode    bool
+	smaps         bool
+	memoryTotals  models.Process
+	savedSort     [2]string
+	treeMode      bool
+	collapsed     map[int]bool
+	source        *system.Source
+	threads       *ThreadView
+	details       *DetailView
+	files         *FilesView
+	pendingPID    int
+	filter        *query.Query
+	columns       []models.ColumnConfig
+	columnSetup   *ColumnSetup
+	tagged        map[int]bool
+	byPID         map[int]models.Process
+	results       *components.Table
+	resultsTitle  string
+	resultsFailed int
+	signalMenu    *components.Table
+	signals       []system.SignalInfo
+	signal        syscall.Signal
+	killTree      []int
+	audit         *audit.Log
+	history       *components.Table
+	privileges    system.Privileges
+}
+
+var (
+	ioHeaders     = []string{"PID", "USER", "NAME", "READ/s", "WRITE/s", "IO/s", "READ", "WRITTEN", "COMMAND"}
+	memoryHeaders = []string{"PID", "USER", "NAME", "RSS", "PSS", "USS", "SHARED", "SWAP", "COMMAND"}
+)
 
 func NewProcessView() *ProcessView {
-	headers := []string{"PID", "PPID", "NAME", "STATE", "CPU%", "MEMORY", "TIME", "USER", "COMMAND"}
-	table := components.NewTable(headers)
-	searchInput := components.NewTextInput("Search processes...", 40)
+	table := components.NewTable(nil)
+	searchInput := components.NewTextInput("Search processes...", 60)
 	confirmDialog := components.NewConfirmDialog("Confirm Action", "")
 	inputDialog := components.NewInputDialog("Process Management", "", "")
 
@@ -41,21 +78,49 @@ func NewProcessView() *ProcessView {
 		processMgr:    system.NewProcessManager(),
 		confirmDialog: confirmDialog,
 		inputDialog:   inputDialog,
+		collapsed:     make(map[int]bool),
+		threads:       NewThreadView(),
+		details:       NewDetailView(),
+		files:         NewFilesView(),
+		columns:       normalizeColumns(nil),
+		columnSetup:   NewColumnSetup(),
+		tagged:        make(map[int]bool),
+		privileges:    system.DetectPrivileges(system.DefaultProcRoot),
 	}
 }
 
+// SetSource sets where drill-downs such as the thread list read /proc.
+func (pv *ProcessView) SetSource(src *system.Source) {
+	pv.source = src
+}
+
 func (v *ProcessView) Render(snapshot *models.MetricsSnapshot, width, height int) string {
 	if snapshot == nil {
 		return "No data available"
 	}
 
+	v.smaps = snapshot.Processes.Smaps
+	v.indexProcesses(snapshot.Processes.Processes)
+	v.revealPending(snapshot.Processes.Processes)
 	v.updateProcesses(snapshot.Processes.Processes)
+	v.selectPending()
+	v.updateTags()
 
 	var content string
 	if v.confirmDialog.IsVisible() {
 		content = v.confirmDialog.Render()
 	} else if v.inputDialog.IsVisible() {
 		content = v.inputDialog.Render()
+	} else if v.signalMenu != nil {
+		content = v.renderSignalMenu(width, height)
+	} else if v.results != nil {
+		content = v.renderResults(width, height)
+	} else if v.history != nil {
+		content = v.renderHistory(width, height)
+	} else if v.columnSetup.IsOpen() {
+		content = v.columnSetup.Render(width, height)
+	} else if d := v.drillDown(); d != nil {
+		content = d.Render(width, height)
 	} else {
 		content = v.renderTable(width, height)
 	}
@@ -64,18 +129,66 @@ func (v *ProcessView) Render(snapshot *models.MetricsSnapshot, width, height int
 }
 
 func (pv *ProcessView) MoveUp() {
+	if pv.results != nil {
+		pv.results.MoveUp()
+		return
+	}
+	if pv.history != nil {
+		pv.history.MoveUp()
+		return
+	}
+	if d := pv.drillDown(); d != nil {
+		d.MoveUp()
+		return
+	}
 	pv.table.MoveUp()
 }
 
 func (pv *ProcessView) MoveDown() {
+	if pv.results != nil {
+		pv.results.MoveDown()
+		return
+	}
+	if pv.history != nil {
+		pv.history.MoveDown()
+		return
+	}
+	if d := pv.drillDown(); d != nil {
+		d.MoveDown()
+		return
+	}
 	pv.table.MoveDown()
 }
 
 func (pv *ProcessView) PageUp() {
+	if pv.results != nil {
+		pv.results.PageUp()
+		return
+	}
+	if pv.history != nil {
+		pv.history.PageUp()
+		return
+	}
+	if d := pv.drillDown(); d != nil {
+		d.PageUp()
+		return
+	}
 	pv.table.PageUp()
 }
 
 func (pv *ProcessView) PageDown() {
+	if pv.results != nil {
+		pv.results.PageDown()
+		return
+	}
+	if pv.history != nil {
+		pv.history.PageDown()
+		return
+	}
+	if d := pv.drillDown(); d != nil {
+		d.PageDown()
+		return
+	}
 	pv.table.PageDown()
 }
 
@@ -85,19 +198,73 @@ func (pv *ProcessView) GetSelectedProcess() []string {
 
 func (v *ProcessView) updateProcesses(processes []models.Process) {
 	filteredProcesses := v.filterProcesses(processes)
+	v.table.Widths = nil
+	if v.treeMode {
+		v.updateTreeRows(processes, filteredProcesses)
+		return
+	}
 	sortedProcesses := v.sortProcesses(filteredProcesses)
 
+	if v.ioMode {
+		v.updateIORows(processes, sortedProcesses)
+		return
+	}
+	if v.memoryMode {
+		v.updateMemoryRows(processes, sortedProcesses)
+		return
+	}
+
+	v.updateColumnRows(sortedProcesses)
+}
+
+// updateIORows fills the table like iotop, one row per process with its
+// disk throughput.
+func (v *ProcessView) updateIORows(all, sorted []models.Process) {
+	v.ioTotals = models.ProcessIOStats{}
+	for _, proc := range all {
+		v.ioTotals.ReadBytesPerSec += proc.IOStats.ReadBytesPerSec
+		v.ioTotals.WriteBytesPerSec += proc.IOStats.WriteBytesPerSec
+	}
+
 	var rows [][]string
-	for _, proc := range sortedProcesses {
+	for _, proc := range sorted {
+		io := proc.IOStats
 		rows = append(rows, []string{
 			strconv.Itoa(proc.PID),
-			strconv.Itoa(proc.PPID),
+			proc.User,
 			proc.Name,
-			utils.FormatProcessState(proc.State),
-			utils.FormatPercent(proc.CPUPercent),
-			utils.FormatBytes(proc.MemoryRSS),
-			utils.FormatDuration(proc.CPUTime),
+			utils.FormatBytesPerSecond(io.ReadBytesPerSec),
+			utils.FormatBytesPerSecond(io.WriteBytesPerSec),
+			utils.FormatBytesPerSecond(io.BytesPerSec()),
+			utils.FormatBytes(io.ReadBytes),
+			utils.FormatBytes(io.WriteBytes),
+			proc.Command,
+		})
+	}
+	v.table.Rows = rows
+}
+
+// updateMemoryRows fills the table with the smaps_rollup breakdown, which
+// shows what each process costs when memory is shared between processes.
+func (v *ProcessView) updateMemoryRows(all, sorted []models.Process) {
+	v.memoryTotals = models.Process{}
+	for _, proc := range all {
+		v.memoryTotals.MemoryPSS += proc.MemoryPSS
+		v.memoryTotals.MemoryUSS += proc.MemoryUSS
+		v.memoryTotals.MemorySwap += proc.MemorySwap
+	}
+
+	var rows [][]string
+	for _, proc := range sorted {
+		rows = append(rows, []string{
+			strconv.Itoa(proc.PID),
 			proc.User,
+			proc.Name,
+			utils.FormatBytes(proc.MemoryRSS),
+			utils.FormatBytes(proc.MemoryPSS),
+			utils.FormatBytes(proc.MemoryUSS),
+			utils.FormatBytes(proc.MemoryShared),
+			utils.FormatBytes(proc.MemorySwap),
 			proc.Command,
 		})
 	}
@@ -105,20 +272,139 @@ func (v *ProcessView) updateProcesses(processes []models.Process) {
 }
 
 func (v *ProcessView) renderTable(width, height int) string {
-	v.table.SetSize(width, height)
-	return v.table.Render()
+	var summary string
+	switch {
+	case v.ioMode:
+		summary = fmt.Sprintf("Total DISK READ: %s | Total DISK WRITE: %s | sorted by %s",
+			utils.FormatBytesPerSecond(v.ioTotals.ReadBytesPerSec),
+			utils.FormatBytesPerSecond(v.ioTotals.WriteBytesPerSec),
+			ioSortLabels[v.sortField])
+	case v.memoryMode && !v.smaps:
+		summary = "Collecting PSS/USS from smaps_rollup, shown from the next update"
+	case v.memoryMode:
+		summary = fmt.Sprintf("Total PSS: %s | Total USS: %s | Total SWAP: %s | sorted by %s",
+			utils.FormatBytes(v.memoryTotals.MemoryPSS),
+			utils.FormatBytes(v.memoryTotals.MemoryUSS),
+			utils.FormatBytes(v.memoryTotals.MemorySwap),
+			memorySortLabels[v.sortField])
+	}
+
+	var header []string
+	if v.searchMode || !v.searchInput.IsEmpty() {
+		header = append(header, lipgloss.JoinHorizontal(lipgloss.Center, "Filter: ", v.searchInput.Render()))
+	}
+	if summary != "" {
+		header = append(header, styles.Info().Render(summary))
+	}
+	if note := v.privilegeSummary(); note != "" {
+		header = append(header, styles.Muted().Render(utils.TruncateString(note, width-4)))
+	}
+	if len(v.tagged) > 0 {
+		header = append(header, styles.Warning().Render(fmt.Sprintf(
+			"%d tagged: actions apply to every tagged process (U to untag)", len(v.tagged))))
+	}
+	if len(header) == 0 {
+		v.table.SetSize(width, height)
+		return v.table.Render()
+	}
+
+	top := strings.Join(header, "\n")
+	v.table.SetSize(width, height-lipgloss.Height(top))
+	return top + "\n" + v.table.Render()
+}
+
+var ioSortLabels = map[string]string{
+	"io":    "IO/s",
+	"read":  "READ/s",
+	"write": "WRITE/s",
+}
+
+var memorySortLabels = map[string]string{
+	"pss":  "PSS",
+	"uss":  "USS",
+	"swap": "SWAP",
+}
+
+// ToggleIOMode switches between the process list and a list ranked by disk
+// throughput. Leaving IO mode restores the previous sort.
+func (pv *ProcessView) ToggleIOMode() {
+	pv.treeMode = false
+	if pv.memoryMode {
+		pv.ToggleMemoryMode()
+	}
+	pv.ioMode = !pv.ioMode
+	if pv.ioMode {
+		pv.table.Headers = ioHeaders
+		pv.savedSort = [2]string{pv.sortField, pv.sortOrder}
+		pv.sortField, pv.sortOrder = "io", "desc"
+	} else {
+		if _, ok := ioSortLabels[pv.sortField]; ok {
+			pv.sortField, pv.sortOrder = pv.savedSort[0], pv.savedSort[1]
+		}
+	}
+}
+
+func (pv *ProcessView) IsIOMode() bool {
+	return pv.ioMode
+}
+
+// ToggleMemoryMode switches between the process list and a list ranked by
+// proportional memory use. The caller turns smaps_rollup collection on.
+func (pv *ProcessView) ToggleMemoryMode() {
+	pv.treeMode = false
+	if pv.ioMode {
+		pv.ToggleIOMode()
+	}
+	pv.memoryMode = !pv.memoryMode
+	if pv.memoryMode {
+		pv.table.Headers = memoryHeaders
+		pv.savedSort = [2]string{pv.sortField, pv.sortOrder}
+		pv.sortField, pv.sortOrder = "pss", "desc"
+	} else {
+		if _, ok := memorySortLabels[pv.sortField]; ok {
+			pv.sortField, pv.sortOrder = pv.savedSort[0], pv.savedSort[1]
+		}
+	}
+}
+
+func (pv *ProcessView) IsMemoryMode() bool {
+	return pv.memoryMode
+}
+
+// CycleMemorySort sorts by PSS, then USS, then swap.
+func (pv *ProcessView) CycleMemorySort() {
+	switch pv.sortField {
+	case "pss":
+		pv.sortField = "uss"
+	case "uss":
+		pv.sortField = "swap"
+	default:
+		pv.sortField = "pss"
+	}
+	pv.sortOrder = "desc"
+}
+
+// CycleIOSort sorts by total, then read, then write throughput.
+func (pv *ProcessView) CycleIOSort() {
+	switch pv.sortField {
+	case "io":
+		pv.sortField = "read"
+	case "read":
+		pv.sortField = "write"
+	default:
+		pv.sortField = "io"
+	}
+	pv.sortOrder = "desc"
 }
 
 func (pv *ProcessView) filterProcesses(processes []models.Process) []models.Process {
-	if pv.searchInput.IsEmpty() {
+	if pv.filter == nil {
 		return processes
 	}
 
-	query := strings.ToLower(pv.searchInput.GetValue())
 	var filtered []models.Process
-
 	for _, proc := range processes {
-		if pv.processMatches(proc, query) {
+		if pv.filter.Match(proc) {
 			filtered = append(filtered, proc)
 		}
 	}
@@ -126,22 +412,35 @@ func (pv *ProcessView) filterProcesses(processes []models.Process) []models.Proc
 	return filtered
 }
 
-func (pv *ProcessView) processMatches(proc models.Process, query string) bool {
-	fields := []string{
-		proc.Name,
-		proc.Command,
-		proc.User,
-		strconv.Itoa(proc.PID),
-		utils.FormatProcessState(proc.State),
+// parseFilter applies the search box as a query. While the query does not
+// parse, the last one that did stays applied and the error is shown in the
+// search box.
+func (pv *ProcessView) parseFilter() {
+	filter, err := query.Parse(pv.searchInput.GetValue())
+	if err != nil {
+		pv.searchInput.SetError(err.Error())
+		return
 	}
+	pv.searchInput.SetError("")
+	pv.filter = filter
+}
 
-	for _, field := range fields {
-		if strings.Contains(strings.ToLower(field), query) {
-			return true
-		}
-	}
+// Filter returns the applied query.
+func (pv *ProcessView) Filter() string {
+	return pv.filter.String()
+}
 
-	return false
+// SetFilter replaces the search box with a query, such as a saved one.
+func (pv *ProcessView) SetFilter(expr string) error {
+	filter, err := query.Parse(expr)
+	if err != nil {
+		return err
+	}
+	pv.searchInput.SetValue(expr)
+	pv.searchInput.MoveCursorToEnd()
+	pv.searchInput.SetError("")
+	pv.filter = filter
+	return nil
 }
 
 func (pv *ProcessView) sortProcesses(processes []models.Process) []models.Process {
@@ -163,6 +462,22 @@ func (pv *ProcessView) sortProcesses(processes []models.Process) []models.Proces
 		pv.sortByName(sorted)
 	case "time":
 		pv.sortByTime(sorted)
+	case "io":
+		pv.sortByIO(sorted, models.ProcessIOStats.BytesPerSec)
+	case "read":
+		pv.sortByIO(sorted, func(io models.ProcessIOStats) float64 { return io.ReadBytesPerSec })
+	case "write":
+		pv.sortByIO(sorted, func(io models.ProcessIOStats) float64 { return io.WriteBytesPerSec })
+	case "pss":
+		pv.sortByBytes(sorted, func(proc models.Process) uint64 { return proc.MemoryPSS })
+	case "uss":
+		pv.sortByBytes(sorted, func(proc models.Process) uint64 { return proc.MemoryUSS })
+	case "swap":
+		pv.sortByBytes(sorted, func(proc models.Process) uint64 { return proc.MemorySwap })
+	default:
+		if column, ok := processColumnByName(pv.sortField); ok {
+			pv.sortByColumn(sorted, column)
+		}
 	}
 
 	if pv.sortOrder == "asc" {
@@ -222,6 +537,26 @@ func (pv *ProcessView) sortByTime(processes []models.Process) {
 	}
 }
 
+func (pv *ProcessView) sortByIO(processes []models.Process, rate func(models.ProcessIOStats) float64) {
+	for i := 0; i < len(processes)-1; i++ {
+		for j := i + 1; j < len(processes); j++ {
+			if rate(processes[i].IOStats) < rate(processes[j].IOStats) {
+				processes[i], processes[j] = processes[j], processes[i]
+			}
+		}
+	}
+}
+
+func (pv *ProcessView) sortByBytes(processes []models.Process, size func(models.Process) uint64) {
+	for i := 0; i < len(processes)-1; i++ {
+		for j := i + 1; j < len(processes); j++ {
+			if size(processes[i]) < size(processes[j]) {
+				processes[i], processes[j] = processes[j], processes[i]
+			}
+		}
+	}
+}
+
 func (pv *ProcessView) reverseProcesses(processes []models.Process) {
 	for i := 0; i < len(processes)/2; i++ {
 		j := len(processes) - 1 - i
@@ -229,10 +564,35 @@ func (pv *ProcessView) reverseProcesses(processes []models.Process) {
 	}
 }
 
+// SelectPID selects a process on the next render, clearing the search and
+// closing drill-downs so that it is shown.
+func (pv *ProcessView) SelectPID(pid int) {
+	pv.CloseDrillDown()
+	pv.StopSearch()
+	pv.searchInput.Clear()
+	pv.filter = nil
+	pv.pendingPID = pid
+}
+
+func (pv *ProcessView) selectPending() {
+	if pv.pendingPID == 0 {
+		return
+	}
+	pid := strconv.Itoa(pv.pendingPID)
+	for i, row := range pv.table.Rows {
+		if len(row) > 0 && row[0] == pid {
+			pv.table.SetSelected(i)
+			break
+		}
+	}
+	pv.pendingPID = 0
+}
+
 func (pv *ProcessView) StartSearch() {
 	pv.searchMode = true
 	pv.searchInput.Focus()
 	pv.searchInput.Clear()
+	pv.filter = nil
 }
 
 func (pv *ProcessView) StopSearch() {
@@ -244,15 +604,21 @@ func (pv *ProcessView) IsSearching() bool {
 	return pv.searchMode
 }
 
+func (pv *ProcessView) GetSearchQuery() string {
+	return pv.searchInput.GetValue()
+}
+
 func (pv *ProcessView) HandleSearchInput(ch rune) {
 	if pv.searchMode {
 		pv.searchInput.InsertChar(ch)
+		pv.parseFilter()
 	}
 }
 
 func (pv *ProcessView) HandleSearchBackspace() {
 	if pv.searchMode {
 		pv.searchInput.DeleteChar()
+		pv.parseFilter()
 	}
 }
 
@@ -288,84 +654,102 @@ func (pv *ProcessView) getSelectedPID() int {
 }
 
 func (pv *ProcessView) ShowKillDialog() {
-	pid := pv.getSelectedPID()
-	if pid <= 0 {
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
 		return
 	}
 
-	pv.selectedPID = pid
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
 	pv.actionType = "kill"
 	pv.confirmDialog.Title = "Kill Process"
-	pv.confirmDialog.Message = fmt.Sprintf("Are you sure you want to terminate process %d?", pid)
+	pv.confirmDialog.Message = fmt.Sprintf("Are you sure you want to terminate %s?%s%s", targets, list, pv.signalNote(pids))
 	pv.confirmDialog.Show()
 }
 
 func (pv *ProcessView) ShowForceKillDialog() {
-	pid := pv.getSelectedPID()
-	if pid <= 0 {
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
 		return
 	}
 
-	pv.selectedPID = pid
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
 	pv.actionType = "force_kill"
 	pv.confirmDialog.Title = "Force Kill Process"
-	pv.confirmDialog.Message = fmt.Sprintf("Are you sure you want to force kill process %d? This cannot be undone.", pid)
+	pv.confirmDialog.Message = fmt.Sprintf("Are you sure you want to force kill %s? This cannot be undone.%s%s", targets, list, pv.signalNote(pids))
 	pv.confirmDialog.Show()
 }
 
 func (pv *ProcessView) ShowStopDialog() {
-	pid := pv.getSelectedPID()
-	if pid <= 0 {
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
 		return
 	}
 
-	pv.selectedPID = pid
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
 	pv.actionType = "stop"
 	pv.confirmDialog.Title = "Stop Process"
-	pv.confirmDialog.Message = fmt.Sprintf("Are you sure you want to stop process %d?", pid)
+	pv.confirmDialog.Message = fmt.Sprintf("Are you sure you want to stop %s?%s%s", targets, list, pv.signalNote(pids))
 	pv.confirmDialog.Show()
 }
 
 func (pv *ProcessView) ShowContinueDialog() {
-	pid := pv.getSelectedPID()
-	if pid <= 0 {
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
 		return
 	}
 
-	pv.selectedPID = pid
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
 	pv.actionType = "continue"
 	pv.confirmDialog.Title = "Continue Process"
-	pv.confirmDialog.Message = fmt.Sprintf("Continue process %d?", pid)
+	pv.confirmDialog.Message = fmt.Sprintf("Continue %s?%s%s", targets, list, pv.signalNote(pids))
 	pv.confirmDialog.Show()
 }
 
 func (pv *ProcessView) ShowNiceDialog() {
-	pid := pv.getSelectedPID()
-	if pid <= 0 {
+	pids := pv.actionTargets()
+	if len(pids) == 0 {
 		return
 	}
 
-	pv.selectedPID = pid
+	pv.targets = pids
+	targets, list := pv.describeTargets(pids)
 	pv.actionType = "nice"
 	pv.inputDialog.Title = "Change Process Priority"
-	pv.inputDialog.Message = fmt.Sprintf("Enter new priority for process %d\n(Range: -20 to 19, lower = higher priority)", pid)
+	pv.inputDialog.Message = fmt.Sprintf("Enter new priority for %s\n(Range: -20 to 19, lower = higher priority)%s%s", targets, list, pv.scheduleNote(pids))
 	pv.inputDialog.Show()
 }
 
 func (pv *ProcessView) ExecuteAction() error {
 	switch pv.actionType {
 	case "kill":
-		return pv.processMgr.TerminateProcess(pv.selectedPID)
+		return pv.runAction(pv.processMgr.TerminateProcess)
 	case "force_kill":
-		return pv.processMgr.ForceKillProcess(pv.selectedPID)
+		return pv.runAction(pv.processMgr.ForceKillProcess)
 	case "stop":
-		return pv.processMgr.StopProcess(pv.selectedPID)
+		return pv.runAction(pv.processMgr.StopProcess)
 	case "continue":
-		return pv.processMgr.ContinueProcess(pv.selectedPID)
+		return pv.runAction(pv.processMgr.ContinueProcess)
+	case "signal":
+		return pv.runAction(func(pid int) error {
+			return pv.processMgr.KillProcess(pid, pv.signal)
+		})
+	case "kill_tree":
+		pv.killTree = pv.targets
+		return nil
+	case "kill_tree_refused":
+		return nil
+	case "affinity", "scheduler", "ioprio":
+		return pv.executeScheduling()
 	case "nice":
 		if priority, err := strconv.Atoi(pv.inputDialog.GetValue()); err == nil {
 			if priority >= -20 && priority <= 19 {
-				return pv.processMgr.SetProcessPriority(pv.selectedPID, priority)
+				return pv.runAction(func(pid int) error {
+					return pv.processMgr.SetProcessPriority(pid, priority)
+				})
 			}
 			return fmt.Errorf("priority must be between -20 and 19")
 		}
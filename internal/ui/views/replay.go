@@ -0,0 +1,109 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/admiller/ltop/internal/app"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+func newSeekDialog() *components.InputDialog {
+	dialog := components.NewInputDialog("Go To Time", "", "HH:MM:SS")
+	dialog.Height = 11
+	return dialog
+}
+
+// updateReplay handles the playback keys. It reports whether the key was
+// one of them.
+func (m Model) updateReplay(player *app.Player, msg tea.KeyMsg) (Model, bool) {
+	if m.seekDialog.IsVisible() {
+		switch msg.String() {
+		case "enter":
+			m.seekDialog.Hide()
+			target, err := parseSeekTime(m.seekDialog.GetValue(), m.snapshotTime(player))
+			if err != nil {
+				m.err = err
+				return m, true
+			}
+			m.err = nil
+			player.Seek(target)
+		case "esc":
+			m.seekDialog.Hide()
+		case "backspace":
+			m.seekDialog.HandleBackspace()
+		default:
+			if len(msg.Runes) == 1 {
+				m.seekDialog.HandleInput(msg.Runes[0])
+			}
+		}
+		return m, true
+	}
+
+	switch msg.String() {
+	case "]":
+		player.Step(1)
+	case "[":
+		player.Step(-1)
+	case "}":
+		player.Faster()
+	case "{":
+		player.Slower()
+	case "g":
+		m.seekDialog.Message = fmt.Sprintf("Enter a time (HH:MM:SS or YYYY-MM-DD HH:MM:SS)\nor an offset (-5m, +30s)\n\nFrom: %s\nTo:   %s",
+			utils.FormatDateTime(player.Start()), utils.FormatDateTime(player.End()))
+		m.seekDialog.Show()
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+func (m Model) snapshotTime(player *app.Player) time.Time {
+	if m.snapshot != nil {
+		return m.snapshot.Timestamp
+	}
+	return player.Start()
+}
+
+// parseSeekTime reads an absolute time, a time of day on the day of current,
+// or an offset from current.
+func parseSeekTime(input string, current time.Time) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return time.Time{}, fmt.Errorf("no time given")
+	}
+
+	if input[0] == '+' || input[0] == '-' {
+		offset, err := time.ParseDuration(input)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q", input)
+		}
+		return current.Add(offset), nil
+	}
+
+	loc := current.Location()
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, input, loc); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, input, loc); err == nil {
+			year, month, day := current.Date()
+			return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", input)
+}
+
+// replayStatus describes the playback position for the header.
+func replayStatus(player *app.Player) string {
+	speed := strconv.FormatFloat(player.Speed(), 'f', -1, 64)
+	return fmt.Sprintf(" [REPLAY %sx %d/%d]", speed, player.Position()+1, player.Len())
+}
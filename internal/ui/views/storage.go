@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/admiller/ltop/internal/app"
 	"github.com/admiller/ltop/internal/models"
 	"github.com/admiller/ltop/internal/ui/components"
 	"github.com/admiller/ltop/internal/ui/styles"
@@ -12,6 +13,7 @@ import (
 
 type StorageView struct {
 	fsGauges []*components.Gauge
+	history  *app.History
 }
 
 func NewStorageView() *StorageView {
@@ -20,6 +22,10 @@ func NewStorageView() *StorageView {
 	}
 }
 
+func (sv *StorageView) SetHistory(history *app.History) {
+	sv.history = history
+}
+
 func (sv *StorageView) Render(snapshot *models.MetricsSnapshot, width, height int) string {
 	if snapshot == nil {
 		return "No data available"
@@ -27,8 +33,15 @@ func (sv *StorageView) Render(snapshot *models.MetricsSnapshot, width, height in
 
 	var sections []string
 
-	sections = append(sections, sv.renderFilesystems(snapshot))
-	sections = append(sections, sv.renderDiskIO(snapshot))
+	filesystems := sv.renderFilesystems(snapshot)
+	diskIO := sv.renderDiskIO(snapshot)
+	used := strings.Count(filesystems, "\n") + strings.Count(diskIO, "\n")
+
+	sections = append(sections, filesystems)
+	if sv.history != nil && height-used >= graphHeight+10 {
+		sections = append(sections, sv.renderHistory(snapshot, width))
+	}
+	sections = append(sections, diskIO)
 
 	content := strings.Join(sections, "\n\n")
 	return styles.Panel().Width(width).Height(height).Render(content)
@@ -65,6 +78,20 @@ func (sv *StorageView) renderFilesystems(snapshot *models.MetricsSnapshot) strin
 	return strings.Join(fs, "\n")
 }
 
+func (sv *StorageView) renderHistory(snapshot *models.MetricsSnapshot, width int) string {
+	var reads, writes []string
+	for _, stat := range snapshot.Storage.IOStats {
+		reads = append(reads, app.SeriesDiskReadIOPS(stat.Device))
+		writes = append(writes, app.SeriesDiskWriteIOPS(stat.Device))
+	}
+
+	graph, samples := newHistoryGraph(width, components.GraphBlock)
+	graph.AddSeries("Read IOPS", historyValues(sv.history, samples, reads...), styles.Success())
+	graph.AddSeries("Write IOPS", historyValues(sv.history, samples, writes...), styles.Warning())
+
+	return styles.Title().Render("Disk IOPS") + "\n" + renderHistoryGraph(sv.history, graph)
+}
+
 func (sv *StorageView) renderDiskIO(snapshot *models.MetricsSnapshot) string {
 	var io []string
 	io = append(io, styles.Title().Render("Disk I/O Statistics"))
@@ -0,0 +1,114 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/admiller/ltop/internal/collectors"
+	"github.com/admiller/ltop/internal/models"
+	"github.com/admiller/ltop/internal/system"
+	"github.com/admiller/ltop/internal/ui/components"
+	"github.com/admiller/ltop/internal/ui/styles"
+	"github.com/admiller/ltop/pkg/utils"
+)
+
+var threadHeaders = []string{"TID", "NAME", "STATE", "CPU%", "TIME", "CPU", "VOL CTXSW", "INVOL CTXSW"}
+
+// ThreadView lists the threads of one process, busiest first. It reads
+// /proc itself whenever it is refreshed, independently of the collectors.
+type ThreadView struct {
+	table     *components.Table
+	collector *collectors.ThreadCollector
+	pid       int
+	threads   []models.Thread
+	err       error
+}
+
+func NewThreadView() *ThreadView {
+	return &ThreadView{table: components.NewTable(threadHeaders)}
+}
+
+func (tv *ThreadView) Open(src *system.Source, pid int) {
+	tv.collector = collectors.NewThreadCollector(src, pid)
+	tv.pid = pid
+	tv.table.ClearRows()
+	tv.Refresh()
+}
+
+func (tv *ThreadView) Close() {
+	tv.collector = nil
+	tv.threads = nil
+	tv.err = nil
+}
+
+func (tv *ThreadView) IsOpen() bool {
+	return tv.collector != nil
+}
+
+func (tv *ThreadView) Refresh() {
+	if tv.collector == nil {
+		return
+	}
+	threads, err := tv.collector.Collect()
+	tv.threads, tv.err = threads, err
+	if err != nil {
+		return
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].CPUPercent > threads[j].CPUPercent
+	})
+
+	rows := make([][]string, 0, len(threads))
+	for _, thread := range threads {
+		rows = append(rows, []string{
+			strconv.Itoa(thread.TID),
+			thread.Name,
+			utils.FormatProcessState(thread.State),
+			utils.FormatPercent(thread.CPUPercent),
+			utils.FormatDuration(thread.CPUTime),
+			strconv.Itoa(thread.Processor),
+			strconv.FormatUint(thread.VoluntaryCtxSwitches, 10),
+			strconv.FormatUint(thread.InvoluntaryCtxSwitches, 10),
+		})
+	}
+	tv.table.Rows = rows
+	if tv.table.Selected >= len(rows) {
+		tv.table.SetSelected(len(rows) - 1)
+	}
+}
+
+func (tv *ThreadView) MoveUp() {
+	tv.table.MoveUp()
+}
+
+func (tv *ThreadView) MoveDown() {
+	tv.table.MoveDown()
+}
+
+func (tv *ThreadView) PageUp() {
+	tv.table.PageUp()
+}
+
+func (tv *ThreadView) PageDown() {
+	tv.table.PageDown()
+}
+
+func (tv *ThreadView) Render(width, height int) string {
+	name := ""
+	for _, thread := range tv.threads {
+		// The main thread has the PID as its TID and the process name.
+		if thread.TID == tv.pid {
+			name = " (" + thread.Name + ")"
+		}
+	}
+	title := styles.Title().Render(fmt.Sprintf("Threads of PID %d%s", tv.pid, name))
+	if tv.err != nil {
+		return title + "\n" + styles.Error().Render(tv.err.Error())
+	}
+
+	summary := styles.Info().Render(fmt.Sprintf("%d threads | CPU%% is of one CPU since the last update", len(tv.threads)))
+	tv.table.SetSize(width, height-2)
+	return title + "\n" + summary + "\n" + tv.table.Render()
+}
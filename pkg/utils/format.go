@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"time"
+	"unicode/utf8"
 )
 
 func FormatBytes(bytes uint64) string {
@@ -78,21 +79,24 @@ func FormatUptime(uptime time.Duration) string {
 	return fmt.Sprintf("%02d:%02d", minutes, seconds)
 }
 
+// TruncateString shortens s to maxLen runes, marking the cut with "...".
 func TruncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
+	runes := []rune(s)
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return string(runes[:maxLen])
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 func PadString(s string, length int, padChar rune) string {
-	if len(s) >= length {
+	n := utf8.RuneCountInString(s)
+	if n >= length {
 		return s
 	}
-	padding := length - len(s)
+	padding := length - n
 	padStr := ""
 	for i := 0; i < padding; i++ {
 		padStr += string(padChar)
@@ -77,6 +77,7 @@ func TestTruncateString(t *testing.T) {
 		{"test", 3, "tes"},
 		{"a", 1, "a"},
 		{"", 5, ""},
+		{"└─ bash -l", 6, "└─ ..."},
 	}
 
 	for _, tc := range testCases {